@@ -0,0 +1,553 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// The alignment verifier checks an LLM-proposed match against the full texts: the
+// reference and target are aligned paragraph by paragraph with Gale–Church length
+// alignment, and structural anchors (numbers, names, exclamations, repeated
+// invocations) are compared. Matches scoring below alignmentThreshold are downgraded
+// to AMBIGUOUS before anything is written.
+const (
+	alignmentThreshold = 0.5
+
+	// Gale–Church variance of target length per source character
+	galeChurchVariance = 6.8
+
+	// Weight of the anchor score in the final alignment score
+	anchorWeight = 0.3
+
+	// Spread (in log ratio) of a language's total length around its usual ratio to English
+	lengthRatioSpread = 0.35
+
+	// Matched pairs needed before a language's length ratio is trusted
+	minRatioSamples = 5
+)
+
+// defaultLengthRatio is the rough letter ratio of a language's text to English, used
+// until the database holds minRatioSamples matched pairs of the language
+func defaultLengthRatio(language string) float64 {
+	switch {
+	case isChinese(language):
+		return 0.3
+	case isKorean(language):
+		return 0.35
+	case isJapanese(language):
+		return 0.45
+	case isArabicScript(language):
+		return 0.8
+	case language == "he":
+		return 0.75
+	}
+	return 1.1
+}
+
+// galeChurchBead is an alignment step of Source reference and Target target segments
+type galeChurchBead struct {
+	Source, Target int
+	Prior          float64
+}
+
+// galeChurchBeads are the bead types with their Gale–Church prior probabilities
+var galeChurchBeads = []galeChurchBead{
+	{1, 1, 0.89},
+	{1, 0, 0.0099},
+	{0, 1, 0.0099},
+	{2, 1, 0.089},
+	{1, 2, 0.089},
+	{2, 2, 0.011},
+}
+
+var (
+	anchorNumberRegex     = regexp.MustCompile(`\d+`)
+	anchorExclamationRune = map[rune]bool{'!': true, '！': true, '¡': true}
+)
+
+// AlignmentBead is one aligned group of reference and target paragraphs
+type AlignmentBead struct {
+	Source      []int   // reference paragraph indexes
+	Target      []int   // target paragraph indexes
+	Probability float64 // length-match probability, 0 for insertions/deletions
+}
+
+// AlignmentResult is the outcome of aligning a reference with a proposed target
+type AlignmentResult struct {
+	Score       float64         // combined alignment and anchor score, 0-1
+	LengthScore float64         // length-weighted mean paragraph coverage
+	RatioScore  float64         // agreement of total length with the language's usual ratio, 1 when unknown
+	AnchorScore float64         // agreement of structural anchors, -1 when no anchors apply
+	Coverage    []float64       // per reference paragraph coverage, 0-1
+	Beads       []AlignmentBead // the best alignment path
+}
+
+// paragraphUnits splits a text into non-empty paragraphs
+func paragraphUnits(text string) []string {
+	var units []string
+	for _, paragraph := range paragraphBreakRegex.Split(text, -1) {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			units = append(units, paragraph)
+		}
+	}
+	return units
+}
+
+// sentenceUnits splits a text into sentences across paragraphs
+func sentenceUnits(text string) []string {
+	var units []string
+	for _, paragraph := range paragraphUnits(text) {
+		units = append(units, splitSentences(paragraph)...)
+	}
+	return units
+}
+
+// alignmentSegments picks the granularity for both texts: paragraphs when both are
+// paragraphed comparably, otherwise sentences, so a single-block transcription of
+// a paragraphed prayer is not penalized
+func alignmentSegments(reference, target string) ([]string, []string) {
+	source, dest := paragraphUnits(reference), paragraphUnits(target)
+	if len(source) >= 2 && len(dest) >= 2 && max(len(source), len(dest)) <= 2*min(len(source), len(dest)) {
+		return source, dest
+	}
+	return sentenceUnits(reference), sentenceUnits(target)
+}
+
+// letterLength counts letters, the length unit used for alignment across scripts
+func letterLength(text string) int {
+	n := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// normalCDF is the standard normal cumulative distribution function
+func normalCDF(x float64) float64 {
+	return 0.5 * (1 + math.Erf(x/math.Sqrt2))
+}
+
+// lengthMatchProbability is the Gale–Church probability that segments of the given
+// lengths are translations of each other with expansion ratio c
+func lengthMatchProbability(sourceLen, targetLen int, c float64) float64 {
+	if sourceLen == 0 && targetLen == 0 {
+		return 1
+	}
+	mean := (float64(sourceLen) + float64(targetLen)/c) / 2
+	if mean == 0 {
+		return 0
+	}
+	delta := (float64(targetLen) - float64(sourceLen)*c) / math.Sqrt(mean*galeChurchVariance)
+	return 2 * (1 - normalCDF(math.Abs(delta)))
+}
+
+// AlignTexts aligns reference paragraphs with target paragraphs. Paragraphs are aligned
+// with the expansion ratio of the two texts themselves, so the length score reflects
+// paragraph structure; the total length is checked separately against expectedRatio,
+// the language's usual target/reference letter ratio (0 when unknown).
+func AlignTexts(reference, target string, expectedRatio float64) AlignmentResult {
+	source, dest := alignmentSegments(reference, target)
+	result := AlignmentResult{AnchorScore: -1, RatioScore: 1, Coverage: make([]float64, len(source))}
+	if len(source) == 0 || len(dest) == 0 {
+		return result
+	}
+
+	sourceLens := make([]int, len(source))
+	destLens := make([]int, len(dest))
+	sourceTotal, destTotal := 0, 0
+	for i, s := range source {
+		sourceLens[i] = letterLength(s)
+		sourceTotal += sourceLens[i]
+	}
+	for j, d := range dest {
+		destLens[j] = letterLength(d)
+		destTotal += destLens[j]
+	}
+	if sourceTotal == 0 || destTotal == 0 {
+		return result
+	}
+	c := float64(destTotal) / float64(sourceTotal)
+
+	sum := func(lens []int, from, count int) int {
+		total := 0
+		for k := from; k < from+count; k++ {
+			total += lens[k]
+		}
+		return total
+	}
+
+	// Dynamic programming over bead types, minimizing -log probability
+	type step struct {
+		cost      float64
+		di, dj    int
+		prob      float64
+		reachable bool
+	}
+	n, m := len(source), len(dest)
+	table := make([][]step, n+1)
+	for i := range table {
+		table[i] = make([]step, m+1)
+	}
+	table[0][0] = step{reachable: true}
+
+	for i := 0; i <= n; i++ {
+		for j := 0; j <= m; j++ {
+			if !table[i][j].reachable {
+				continue
+			}
+			for _, bead := range galeChurchBeads {
+				di, dj := bead.Source, bead.Target
+				if i+di > n || j+dj > m {
+					continue
+				}
+				prob := 0.0
+				if di > 0 && dj > 0 {
+					prob = lengthMatchProbability(sum(sourceLens, i, di), sum(destLens, j, dj), c)
+				}
+				matchCost := -math.Log(math.Max(prob, 1e-9))
+				if di == 0 || dj == 0 {
+					matchCost = -math.Log(0.05)
+				}
+				cost := table[i][j].cost + matchCost - math.Log(bead.Prior)
+				next := &table[i+di][j+dj]
+				if !next.reachable || cost < next.cost {
+					*next = step{cost: cost, di: di, dj: dj, prob: prob, reachable: true}
+				}
+			}
+		}
+	}
+
+	// Trace back the best path
+	for i, j := n, m; i > 0 || j > 0; {
+		s := table[i][j]
+		bead := AlignmentBead{Probability: s.prob}
+		for k := i - s.di; k < i; k++ {
+			bead.Source = append(bead.Source, k)
+			result.Coverage[k] = s.prob
+		}
+		for k := j - s.dj; k < j; k++ {
+			bead.Target = append(bead.Target, k)
+		}
+		result.Beads = append([]AlignmentBead{bead}, result.Beads...)
+		i, j = i-s.di, j-s.dj
+	}
+
+	// Length-weighted coverage; unmatched target paragraphs count against the score
+	covered := 0.0
+	for i, coverage := range result.Coverage {
+		covered += coverage * float64(sourceLens[i])
+	}
+	unmatchedTarget := 0
+	for _, bead := range result.Beads {
+		if len(bead.Source) == 0 {
+			unmatchedTarget += sum(destLens, bead.Target[0], len(bead.Target))
+		}
+	}
+	result.LengthScore = covered / (float64(sourceTotal) + float64(unmatchedTarget)/c)
+
+	if expectedRatio > 0 {
+		deviation := math.Log(c / expectedRatio)
+		result.RatioScore = math.Exp(-deviation * deviation / (2 * lengthRatioSpread * lengthRatioSpread))
+	}
+
+	result.AnchorScore = anchorAgreement(extractAnchors(reference), extractAnchors(target))
+	result.Score = result.LengthScore * result.RatioScore
+	if result.AnchorScore >= 0 {
+		result.Score = (1-anchorWeight)*result.Score + anchorWeight*result.AnchorScore
+	}
+	return result
+}
+
+// textAnchors are language-independent landmarks that survive translation
+type textAnchors struct {
+	Numbers      []string
+	Names        []string // transliterated Latin-script names, folded
+	Exclamations int
+	Invocations  int // short exclamatory lines such as "O God!" that open or repeat
+}
+
+// extractAnchors collects anchors from a text
+func extractAnchors(text string) textAnchors {
+	var anchors textAnchors
+
+	// Arabic-Indic and Persian digits are folded to ASCII by the Arabic-script normalizer
+	anchors.Numbers = anchorNumberRegex.FindAllString(normalizeArabicScript(text), -1)
+
+	for _, r := range text {
+		if anchorExclamationRune[r] {
+			anchors.Exclamations++
+		}
+	}
+
+	for _, sentence := range sentenceUnits(text) {
+		last, _ := lastRune(sentence)
+		if anchorExclamationRune[last] && len(strings.Fields(sentence)) <= 6 {
+			anchors.Invocations++
+		}
+	}
+
+	// Names: transliterated proper names (Bahá'u'lláh, Báb, ‘Akká) keep their capital,
+	// diacritics and apostrophes in every Latin-script translation
+	for _, word := range strings.Fields(text) {
+		clean := strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) })
+		runes := []rune(clean)
+		if len(runes) < 3 || !unicode.IsUpper(runes[0]) || !unicode.Is(unicode.Latin, runes[0]) {
+			continue
+		}
+		if isTransliteratedName(clean) {
+			anchors.Names = append(anchors.Names, foldedLetters(clean, ""))
+		}
+	}
+
+	return anchors
+}
+
+// isTransliteratedName reports whether a word carries transliteration marks:
+// an inner apostrophe or an accented letter
+func isTransliteratedName(word string) bool {
+	for _, r := range word {
+		if r == '\'' || r == '’' || r == '‘' {
+			return true
+		}
+		if folded, ok := latinDiacriticFolds[unicode.ToLower(r)]; ok && folded != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// lastRune returns the final rune of a string
+func lastRune(s string) (rune, bool) {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return 0, false
+	}
+	return runes[len(runes)-1], true
+}
+
+// anchorAgreement scores how well two texts' anchors agree, averaging the anchor
+// kinds present in either text; -1 when no anchor applies
+func anchorAgreement(a, b textAnchors) float64 {
+	var scores []float64
+
+	if len(a.Numbers) > 0 || len(b.Numbers) > 0 {
+		scores = append(scores, multisetOverlap(a.Numbers, b.Numbers))
+	}
+	// Names only compare within Latin script, where they survive translation
+	if len(a.Names) >= 2 && len(b.Names) >= 2 {
+		scores = append(scores, multisetOverlap(a.Names, b.Names))
+	}
+	if a.Exclamations >= 2 || b.Exclamations >= 2 {
+		scores = append(scores, countAgreement(a.Exclamations, b.Exclamations))
+	}
+	if a.Invocations >= 2 || b.Invocations >= 2 {
+		scores = append(scores, countAgreement(a.Invocations, b.Invocations))
+	}
+
+	if len(scores) == 0 {
+		return -1
+	}
+	total := 0.0
+	for _, s := range scores {
+		total += s
+	}
+	return total / float64(len(scores))
+}
+
+// multisetOverlap is the Dice coefficient of two multisets
+func multisetOverlap(a, b []string) float64 {
+	if len(a)+len(b) == 0 {
+		return 1
+	}
+	counts := make(map[string]int)
+	for _, x := range a {
+		counts[x]++
+	}
+	shared := 0
+	for _, x := range b {
+		if counts[x] > 0 {
+			counts[x]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
+
+// countAgreement compares two counts as min/max
+func countAgreement(a, b int) float64 {
+	if a == b {
+		return 1
+	}
+	return float64(min(a, b)) / float64(max(a, b))
+}
+
+// referenceLanguageRank orders the languages a reference text is taken from
+var referenceLanguageRank = map[string]int{"en": 3, "ar": 2, "fa": 1}
+
+// alignmentTexts holds cleaned reference and target texts for verification
+type alignmentTexts struct {
+	references     map[string]string  // phelps -> reference text (en, then ar, then fa)
+	referenceLangs map[string]string  // phelps -> language of the reference text
+	targets        map[string]Writing // version -> writing with cleaned text
+	ratios         map[string]float64 // language -> median letter ratio to English
+}
+
+// buildAlignmentTexts indexes cleaned texts needed to verify matches and learns each
+// language's usual length ratio to English from the matches already in the database
+func buildAlignmentTexts(db Database) alignmentTexts {
+	texts := alignmentTexts{
+		references:     make(map[string]string),
+		referenceLangs: make(map[string]string),
+		targets:        make(map[string]Writing),
+		ratios:         make(map[string]float64),
+	}
+	best := make(map[string]int)
+
+	for _, w := range db.Writing {
+		if w.Text == "" {
+			continue
+		}
+		w.Text = cleanWritingText(w)
+		texts.targets[w.Version] = w
+		if w.Phelps != "" && referenceLanguageRank[w.Language] > best[w.Phelps] {
+			best[w.Phelps] = referenceLanguageRank[w.Language]
+			texts.references[w.Phelps] = w.Text
+			texts.referenceLangs[w.Phelps] = w.Language
+		}
+	}
+
+	samples := make(map[string][]float64)
+	for _, w := range texts.targets {
+		if w.Phelps == "" || w.Language == "en" || texts.referenceLangs[w.Phelps] != "en" {
+			continue
+		}
+		if _, _, excerpt := parseExcerptNote(w.Notes); excerpt {
+			continue
+		}
+		if refLen := letterLength(texts.references[w.Phelps]); refLen > 0 {
+			samples[w.Language] = append(samples[w.Language], float64(letterLength(w.Text))/float64(refLen))
+		}
+	}
+	for language, ratios := range samples {
+		if len(ratios) >= minRatioSamples {
+			sort.Float64s(ratios)
+			texts.ratios[language] = ratios[len(ratios)/2]
+		}
+	}
+
+	return texts
+}
+
+// verify aligns a proposed code with a target; passage codes are verified against their passage
+func (t alignmentTexts) verify(code, version string) (AlignmentResult, bool) {
+	phelps, passage := splitPassageCode(code)
+	reference, ok := t.references[phelps]
+	target, ok2 := t.targets[version]
+	if !ok || !ok2 {
+		return AlignmentResult{}, false
+	}
+	if passage > 0 {
+		passages := SegmentPassages(reference, t.referenceLangs[phelps])
+		if passage > len(passages) {
+			return AlignmentResult{}, false
+		}
+		reference = passages[passage-1]
+	}
+
+	expectedRatio := 0.0
+	if t.referenceLangs[phelps] == "en" {
+		expectedRatio = t.expectedRatio(target.Language)
+	}
+	return AlignTexts(reference, target.Text, expectedRatio), true
+}
+
+// expectedRatio is a language's length ratio to English: learned from matches when
+// there are enough of them, the language's default otherwise
+func (t alignmentTexts) expectedRatio(language string) float64 {
+	if ratio := t.ratios[language]; ratio != 0 {
+		return ratio
+	}
+	return defaultLengthRatio(language)
+}
+
+// describeAlignment summarizes a result for ambiguity reasons and logs
+func describeAlignment(result AlignmentResult) string {
+	weak := 0
+	for _, coverage := range result.Coverage {
+		if coverage < 0.5 {
+			weak++
+		}
+	}
+	anchor := "n/a"
+	if result.AnchorScore >= 0 {
+		anchor = fmt.Sprintf("%.2f", result.AnchorScore)
+	}
+	return fmt.Sprintf("alignment score %.2f below %.2f (paragraphs %.2f, total length %.2f, anchors %s, %d/%d paragraphs weakly covered)",
+		result.Score, alignmentThreshold, result.LengthScore, result.RatioScore, anchor, weak, len(result.Coverage))
+}
+
+// VerifyProposedMatches aligns every EXACT/LIKELY match with its reference text and
+// downgrades those below alignmentThreshold to AMBIGUOUS, adjusting the counters
+func VerifyProposedMatches(db Database, results *CompressedBatchResponse) {
+	texts := buildAlignmentTexts(db)
+	downgraded := 0
+
+	for i := range results.Matches {
+		match := &results.Matches[i]
+		if match.MatchType != "EXACT" && match.MatchType != "LIKELY" {
+			continue
+		}
+		alignment, ok := texts.verify(match.EnglishPhelps, match.TargetVersion)
+		if !ok || alignment.Score >= alignmentThreshold {
+			continue
+		}
+
+		if match.MatchType == "EXACT" {
+			results.ExactMatches--
+		} else {
+			results.LikelyMatches--
+		}
+		results.AmbiguousCount++
+		match.MatchType = "AMBIGUOUS"
+		match.AmbiguityReason = describeAlignment(alignment)
+		downgraded++
+		log.Printf("   📐 %s -> %s downgraded to AMBIGUOUS: %s", match.EnglishPhelps, match.TargetVersion, match.AmbiguityReason)
+	}
+
+	if downgraded > 0 {
+		log.Printf("📐 Alignment verification downgraded %d of %d matches", downgraded, len(results.Matches))
+	}
+}
+
+// VerifyMultiLanguageMatches applies the same verification to ultra batch matches
+func VerifyMultiLanguageMatches(db Database, matches []MultiLanguageMatchResult) {
+	texts := buildAlignmentTexts(db)
+	downgraded := 0
+
+	for i := range matches {
+		match := &matches[i]
+		if match.MatchType != "EXACT" && match.MatchType != "LIKELY" {
+			continue
+		}
+		alignment, ok := texts.verify(match.EnglishPhelps, match.TargetVersion)
+		if !ok || alignment.Score >= alignmentThreshold {
+			continue
+		}
+		match.MatchType = "AMBIGUOUS"
+		match.AmbiguityReason = describeAlignment(alignment)
+		downgraded++
+		log.Printf("   📐 %s -> %s (%s) downgraded to AMBIGUOUS: %s",
+			match.EnglishPhelps, match.TargetVersion, match.TargetLanguage, match.AmbiguityReason)
+	}
+
+	if downgraded > 0 {
+		log.Printf("📐 Alignment verification downgraded %d of %d matches", downgraded, len(matches))
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const alignmentEnglish = `O God, my God! I have turned in repentance unto Thee, and verily Thou art the Pardoner, the Compassionate.
+
+O God, my God! I have returned to Thee, and verily Thou art the Ever-Forgiving, the Gracious.
+
+O God, my God! I have clung to the cord of Thy bounty, and with Thee is the storehouse of all that is in heaven and earth. Grant me, I beseech Thee, the 19 blessings of Thy mercy, and let me attain the presence of Bahá'u'lláh in the Abhá Kingdom.
+
+O God, my God! Hasten to me with Thy grace.`
+
+const alignmentSpanish = `¡Oh Dios, mi Dios! Me he vuelto a Ti arrepentido, y en verdad Tú eres el Perdonador, el Compasivo.
+
+¡Oh Dios, mi Dios! He regresado a Ti, y en verdad Tú eres el Siempre Perdonador, el Benévolo.
+
+¡Oh Dios, mi Dios! Me he aferrado al cordón de Tu generosidad, y Contigo está el depósito de todo lo que hay en el cielo y en la tierra. Concédeme, Te lo ruego, las 19 bendiciones de Tu misericordia, y permíteme alcanzar la presencia de Bahá'u'lláh en el Reino de Abhá.
+
+¡Oh Dios, mi Dios! Apresúrate a mí con Tu gracia.`
+
+const alignmentOtherSpanish = `¡Di: Dios basta sobre todas las cosas, y nada hay en los cielos ni en la tierra que no baste sino Dios! En verdad, Él es en Sí mismo el Conocedor, el Sustentador, el Omnipotente. Él es quien guarda a Sus siervos de todo mal, y quien los guía en el sendero recto, y quien los protege en toda circunstancia, de día y de noche, en la tierra y en el mar, y en todos los mundos de Dios, los visibles y los invisibles, por siempre jamás.
+
+Alabado sea Dios.`
+
+// Test structural alignment of proposed matches
+func TestAlignTexts(t *testing.T) {
+	tests := []struct {
+		name          string
+		target        string
+		expectedRatio float64
+		pass          bool
+	}{
+		{"Translation with the same structure", alignmentSpanish, 0, true},
+		{"Translation with a known length ratio", alignmentSpanish, 1.1, true},
+		{"Different prayer", alignmentOtherSpanish, 0, false},
+		{"Translation far longer than the language usually is", strings.Repeat(alignmentSpanish+"\n\n", 3), 1.1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := AlignTexts(alignmentEnglish, tt.target, tt.expectedRatio)
+			if (result.Score >= alignmentThreshold) != tt.pass {
+				t.Errorf("score %.2f (paragraphs %.2f, ratio %.2f, anchors %.2f), expected pass=%v",
+					result.Score, result.LengthScore, result.RatioScore, result.AnchorScore, tt.pass)
+			}
+			if len(result.Coverage) == 0 {
+				t.Error("Expected per-paragraph coverage")
+			}
+		})
+	}
+}
+
+func TestExtractAnchors(t *testing.T) {
+	anchors := extractAnchors("He is God!\n\nPraise be to the Báb and to Bahá'u'lláh, for the ١٩ days of the Fast!")
+
+	if strings.Join(anchors.Numbers, ",") != "19" {
+		t.Errorf("Expected Arabic-Indic 19 folded to ASCII, got %v", anchors.Numbers)
+	}
+	if anchors.Exclamations != 2 || anchors.Invocations != 1 {
+		t.Errorf("Expected 2 exclamations and 1 invocation, got %d and %d", anchors.Exclamations, anchors.Invocations)
+	}
+	if len(anchors.Names) != 2 {
+		t.Errorf("Expected Báb and Bahá'u'lláh as names, got %v", anchors.Names)
+	}
+}
+
+func TestVerifyProposedMatchesDowngrades(t *testing.T) {
+	db := Database{
+		Writing: []Writing{
+			{Phelps: "BH00001ONE", Version: "en-1", Language: "en", Text: alignmentEnglish},
+			{Version: "es-1", Language: "es", Text: alignmentSpanish},
+			{Version: "es-2", Language: "es", Text: alignmentOtherSpanish},
+			{Version: "es-3", Language: "es", Text: strings.Repeat(alignmentSpanish+"\n\n", 3)},
+		},
+	}
+	results := CompressedBatchResponse{
+		Matches: []CompressedMatchResult{
+			{EnglishPhelps: "BH00001ONE", TargetVersion: "es-1", MatchType: "LIKELY", Confidence: 85},
+			{EnglishPhelps: "BH00001ONE", TargetVersion: "es-2", MatchType: "EXACT", Confidence: 95},
+		},
+		ExactMatches:  1,
+		LikelyMatches: 1,
+	}
+
+	// Without matched Spanish pairs the total length is checked against the default ratio
+	if result, ok := buildAlignmentTexts(db).verify("BH00001ONE", "es-3"); !ok || result.RatioScore >= 0.5 || result.Score >= alignmentThreshold {
+		t.Errorf("Expected a text three times too long to fail, got ratio %.2f, score %.2f", result.RatioScore, result.Score)
+	}
+
+	VerifyProposedMatches(db, &results)
+
+	if results.Matches[0].MatchType != "LIKELY" {
+		t.Errorf("Expected the real translation to stay LIKELY, got %s: %s", results.Matches[0].MatchType, results.Matches[0].AmbiguityReason)
+	}
+	if results.Matches[1].MatchType != "AMBIGUOUS" || results.Matches[1].AmbiguityReason == "" {
+		t.Errorf("Expected the wrong prayer to be downgraded, got %s", results.Matches[1].MatchType)
+	}
+	if results.ExactMatches != 0 || results.LikelyMatches != 1 || results.AmbiguousCount != 1 {
+		t.Errorf("Unexpected counters: %d exact, %d likely, %d ambiguous", results.ExactMatches, results.LikelyMatches, results.AmbiguousCount)
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Phase 2: open ambiguous and low-confidence items of the review queue are resolved by
+// showing the LLM the full target text next to the texts of its best candidates.
+const (
+	maxResolutionCandidates = 5
+	resolutionExcerptChars  = 1200 // longer candidate texts are shown as opening and closing
+	resolutionReviewer      = "phase2-full-text"
+)
+
+var candidateCodeRegex = regexp.MustCompile(`\b[A-Z]{2,3}\d{5}[A-Z]*(?:§\d+)?`)
+
+// resolutionCandidate is a reference the target prayer might be a translation of
+type resolutionCandidate struct {
+	Code  string
+	Text  string
+	Score float64 // structural alignment with the target
+	Note  string
+}
+
+// gatherResolutionCandidates collects the codes proposed in a review item and fills up
+// with the references that align best with the full target text
+func gatherResolutionCandidates(texts alignmentTexts, item ReviewItem) []resolutionCandidate {
+	version := item.Version
+	target, ok := texts.targets[version]
+	if !ok {
+		return nil
+	}
+
+	var candidates []resolutionCandidate
+	seen := make(map[string]bool)
+	add := func(code, note string) {
+		if seen[code] {
+			return
+		}
+		alignment, ok := texts.verify(code, version)
+		if !ok {
+			return
+		}
+		phelps, passage := splitPassageCode(code)
+		text := texts.references[phelps]
+		if passage > 0 {
+			text = SegmentPassages(text, texts.referenceLangs[phelps])[passage-1]
+		}
+		seen[code] = true
+		candidates = append(candidates, resolutionCandidate{Code: code, Text: text, Score: alignment.Score, Note: note})
+	}
+
+	// Codes proposed by earlier passes come first
+	for _, proposed := range item.Candidates {
+		add(proposed.Phelps, fmt.Sprintf("proposed at %.0f%%", proposed.Confidence))
+		for _, code := range candidateCodeRegex.FindAllString(strings.Join(proposed.Reasons, " ")+" "+proposed.Ambiguity, -1) {
+			add(code, "mentioned in review notes")
+		}
+	}
+
+	// Then the references whose structure fits the target best
+	expected := texts.expectedRatio(target.Language)
+	targetLen := letterLength(target.Text)
+	var aligned []resolutionCandidate
+	for phelps, reference := range texts.references {
+		if seen[phelps] || texts.referenceLangs[phelps] != "en" {
+			continue
+		}
+		refLen := letterLength(reference)
+		if refLen == 0 || targetLen == 0 || math.Abs(math.Log(float64(targetLen)/float64(refLen)/expected)) > 0.5 {
+			continue
+		}
+		alignment := AlignTexts(reference, target.Text, expected)
+		if alignment.Score >= alignmentThreshold {
+			aligned = append(aligned, resolutionCandidate{Code: phelps, Text: reference, Score: alignment.Score, Note: "structural match"})
+		}
+	}
+	sort.Slice(aligned, func(i, j int) bool {
+		if aligned[i].Score != aligned[j].Score {
+			return aligned[i].Score > aligned[j].Score
+		}
+		return aligned[i].Code < aligned[j].Code
+	})
+	for _, candidate := range aligned {
+		if len(candidates) >= maxResolutionCandidates {
+			break
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if len(candidates) > maxResolutionCandidates {
+		candidates = candidates[:maxResolutionCandidates]
+	}
+	return candidates
+}
+
+// resolutionExcerpt shortens long candidate texts to their opening and closing
+func resolutionExcerpt(text string) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= resolutionExcerptChars {
+		return string(runes)
+	}
+	head := resolutionExcerptChars * 2 / 3
+	tail := resolutionExcerptChars - head
+	return string(runes[:head]) + "\n[…]\n" + string(runes[len(runes)-tail:])
+}
+
+// buildResolutionPrompt asks the LLM to pick the candidate the target prayer translates
+func buildResolutionPrompt(target Writing, candidates []resolutionCandidate) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("You are an expert in Bahá'í prayers resolving a match that automatic matching could not decide.\n\n")
+	prompt.WriteString(fmt.Sprintf("# TARGET PRAYER (%s, %s)\n", target.Language, target.Version))
+	prompt.WriteString(target.Text)
+	prompt.WriteString("\n\n# CANDIDATES\n")
+	for i, candidate := range candidates {
+		prompt.WriteString(fmt.Sprintf("[%d] %s (%s, alignment %.2f)\n", i+1, candidate.Code, candidate.Note, candidate.Score))
+		prompt.WriteString(resolutionExcerpt(candidate.Text))
+		prompt.WriteString("\n\n")
+	}
+
+	prompt.WriteString(`Decide which candidate the target prayer is a translation of. Compare the sequence of ideas, invocations and names, not just shared phrases. Codes ending in §n are passages of a longer Tablet. Answer NONE if no candidate is this prayer.
+
+Respond in exactly this format:
+CHOICE: [candidate number, or NONE]
+CONFIDENCE: [0-100]
+EVIDENCE: [a short excerpt from each text that shows the correspondence]`)
+
+	return prompt.String()
+}
+
+// parseResolutionResponse reads CHOICE/CONFIDENCE/EVIDENCE; choice 0 means NONE
+func parseResolutionResponse(response string, candidates int) (int, float64, string, error) {
+	choice, confidence, evidence := -1, 0.0, ""
+
+	for _, line := range strings.Split(response, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, "[]* ")
+
+		switch strings.ToUpper(strings.Trim(strings.TrimSpace(key), "*")) {
+		case "CHOICE":
+			if strings.EqualFold(value, "NONE") {
+				choice = 0
+			} else if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && n >= 1 && n <= candidates {
+				choice = n
+			}
+		case "CONFIDENCE":
+			if m := reviewConfidenceRegex.FindString(value); m != "" {
+				confidence, _ = strconv.ParseFloat(m, 64)
+			}
+		case "EVIDENCE":
+			evidence = value
+		}
+	}
+
+	if choice < 0 {
+		return 0, 0, "", fmt.Errorf("no valid CHOICE in response")
+	}
+	return choice, confidence, evidence, nil
+}
+
+// applyResolution writes a resolved code unless the prayer got a code in the meantime or
+// is protected; it reports whether the code was written
+func applyResolution(version, language, code string, confidence float64, evidence string) (bool, error) {
+	phelps, passage := splitPassageCode(code)
+	change := ProposedChange{Version: version, Language: language, NewPhelps: phelps, Passage: passage,
+		Confidence: confidence, MatchType: "RESOLVED", Reasons: []string{evidence}, Source: "resolve-ambiguous"}
+	return writePhelps(change, true)
+}
+
+// ResolveAmbiguousMatches resolves the open review queue items of a language with
+// full-text comparison, applies confident resolutions and marks those items resolved
+func ResolveAmbiguousMatches(language string) error {
+	log.Printf("Starting Phase 2 ambiguous resolution for language: %s", language)
+
+	items, err := openReviewQueue()
+	if err != nil {
+		return fmt.Errorf("failed to load review queue: %w", err)
+	}
+
+	var pending []int
+	for i, item := range items {
+		if item.Language == language && item.IsOpen() {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return fmt.Errorf("no open review items for language %s. Run compressed matching first to fill %s", language, reviewQueueFile)
+	}
+	log.Printf("Found %d open review items for %s", len(pending), language)
+
+	db, err := GetDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load database: %w", err)
+	}
+	texts := buildAlignmentTexts(db)
+
+	resolve := func(item *ReviewItem, decision, note string) {
+		item.Status = ReviewResolved
+		item.Reviewer = resolutionReviewer
+		item.Decision = decision
+		item.Note = note
+		item.Updated = time.Now()
+	}
+
+	applied, none, undecided := 0, 0, 0
+	for n, idx := range pending {
+		item := &items[idx]
+		target, ok := texts.targets[item.Version]
+		if !ok {
+			log.Printf("⚠️ %s not found in database, skipping", item.Version)
+			continue
+		}
+		if target.Phelps != "" {
+			resolve(item, target.Phelps, "already matched")
+			continue
+		}
+
+		candidates := gatherResolutionCandidates(texts, *item)
+		if len(candidates) == 0 {
+			log.Printf("   ❓ No candidates for %s", item.Version)
+			undecided++
+			continue
+		}
+
+		log.Printf("🔍 [%d/%d] Resolving %s among %d candidates", n+1, len(pending), item.Version, len(candidates))
+		response, err := callLLMWithBackendFallback(buildResolutionPrompt(target, candidates), "ambiguous resolution", true)
+		if err != nil {
+			log.Printf("❌ LLM failed for %s: %v", item.Version, err)
+			undecided++
+			continue
+		}
+		backend := lastLLMBackend
+		choice, confidence, evidence, err := parseResolutionResponse(response, len(candidates))
+		if err != nil || !shouldAutoApply(backend, "RESOLVED", confidence, nil) {
+			log.Printf("   ❓ %s left open (confidence %.0f%%)", item.Version, confidence)
+			undecided++
+			continue
+		}
+
+		if choice == 0 {
+			resolve(item, "NONE", fmt.Sprintf("%.0f%%: %s", confidence, evidence))
+			none++
+			log.Printf("   🚫 %s matches none of the candidates", item.Version)
+			continue
+		}
+
+		code := candidates[choice-1].Code
+		written, err := applyResolution(item.Version, language, code, confidence, evidence)
+		if err != nil {
+			log.Printf("❌ Failed to apply %s -> %s: %v", item.Version, code, err)
+			undecided++
+			continue
+		}
+		if !written {
+			log.Printf("   ❓ %s left open: %s was not written", item.Version, code)
+			undecided++
+			continue
+		}
+		resolve(item, code, fmt.Sprintf("%.0f%%: %s", confidence, evidence))
+		applied++
+		log.Printf("   ✅ %s -> %s (%.0f%%)", item.Version, code, confidence)
+	}
+
+	// A dry run leaves the queue open until its changeset is applied
+	if !dryRunMode {
+		if err := SaveReviewQueue(reviewQueueFile, items); err != nil {
+			return fmt.Errorf("failed to save review queue: %w", err)
+		}
+	}
+
+	log.Printf("Phase 2 resolution completed for %s:", language)
+	log.Printf("  - Resolved and applied: %d", applied)
+	log.Printf("  - Resolved as no match: %d", none)
+	log.Printf("  - Still open: %d", undecided)
+	return nil
+}
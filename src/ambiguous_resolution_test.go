@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+)
+
+// Test parsing of Phase 2 resolution responses
+func TestParseResolutionResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   string
+		choice     int
+		confidence float64
+		wantErr    bool
+	}{
+		{"Candidate chosen", "CHOICE: 2\nCONFIDENCE: 88\nEVIDENCE: \"Oh Dios\" / \"O God\"", 2, 88, false},
+		{"No candidate", "CHOICE: NONE\nCONFIDENCE: 90%\nEVIDENCE: different prayer", 0, 90, false},
+		{"Choice out of range", "CHOICE: 7\nCONFIDENCE: 95", 0, 0, true},
+		{"Markdown emphasis", "**CHOICE:** [1]\n**CONFIDENCE:** 85", 1, 85, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			choice, confidence, _, err := parseResolutionResponse(tt.response, 3)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			if !tt.wantErr && (choice != tt.choice || confidence != tt.confidence) {
+				t.Errorf("Got choice %d at %.0f%%, want %d at %.0f%%", choice, confidence, tt.choice, tt.confidence)
+			}
+		})
+	}
+}
+
+const alignmentChinese = `神啊，我的神！我已悔改归向祢，祢确是宽恕者，慈悲者。
+
+神啊，我的神！我已回到祢身边，祢确是永远宽恕者，仁慈者。
+
+神啊，我的神！我已紧握祢恩惠的绳索，天地间万物的宝库都在祢那里。我恳求祢，赐我祢慈悲的19种祝福，让我在阿卜哈天国中得见巴哈欧拉。
+
+神啊，我的神！求祢以祢的恩典速速临到我。`
+
+// Test that structural candidates are filtered with the language's default length ratio
+func TestGatherResolutionCandidatesUsesDefaultRatio(t *testing.T) {
+	texts := alignmentTexts{
+		references:     map[string]string{"BH00001ONE": alignmentEnglish},
+		referenceLangs: map[string]string{"BH00001ONE": "en"},
+		targets:        map[string]Writing{"zh-1": {Version: "zh-1", Language: "zh", Text: alignmentChinese}},
+		ratios:         map[string]float64{},
+	}
+
+	candidates := gatherResolutionCandidates(texts, ReviewItem{Version: "zh-1"})
+	if len(candidates) != 1 || candidates[0].Code != "BH00001ONE" {
+		t.Errorf("Expected the English prayer as a structural candidate, got %+v", candidates)
+	}
+}
+
+// Test that a resolution the write path skips is reported as not written
+func TestApplyResolutionReportsSkippedWrites(t *testing.T) {
+	dryRunMode = true
+	phelpsRows = map[string]phelpsRow{
+		"es-1": {Language: "es"},
+		"es-2": {Language: "es", Phelps: "BH00002SEC"},
+	}
+	defer func() { dryRunMode, proposedChanges, phelpsRows = false, nil, nil }()
+
+	if written, err := applyResolution("es-1", "es", "BH00001ONE", 92, "evidence"); err != nil || !written {
+		t.Errorf("Expected the resolution of an unmatched prayer to be written, got %v, %v", written, err)
+	}
+	if written, err := applyResolution("es-2", "es", "BH00001ONE", 92, "evidence"); err != nil || written {
+		t.Errorf("Expected the resolution of a matched prayer to be skipped, got %v, %v", written, err)
+	}
+}
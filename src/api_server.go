@@ -0,0 +1,343 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The -serve mode exposes the matching database as a read-only JSON API, so other tools
+// can look up translations without Dolt. It serves the snapshot loaded at startup; the
+// review queue is re-read on every request. Lists are paginated with ?page=&per_page=
+// and every response carries an ETag for conditional requests.
+
+const (
+	apiDefaultPerPage = 100
+	apiMaxPerPage     = 1000
+)
+
+// APIWriting is a writing as returned by the API; text is only included on request
+type APIWriting struct {
+	Version  string `json:"version"`
+	Language string `json:"language"`
+	Phelps   string `json:"phelps,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Link     string `json:"link,omitempty"`
+	Source   string `json:"source,omitempty"`
+	SourceID string `json:"source_id,omitempty"`
+	Verified bool   `json:"verified"`
+	Text     string `json:"text,omitempty"`
+}
+
+// APILanguageStatus mirrors ProcessingStatus for one language
+type APILanguageStatus struct {
+	Language         string `json:"language"`
+	Name             string `json:"name,omitempty"`
+	TotalPrayers     int    `json:"total_prayers"`
+	MatchedPrayers   int    `json:"matched_prayers"`
+	UnmatchedPrayers int    `json:"unmatched_prayers"`
+	CompletionRate   int    `json:"completion_rate"`
+}
+
+// APIPage is a page of a list response
+type APIPage struct {
+	Items   interface{} `json:"items"`
+	Page    int         `json:"page"`
+	PerPage int         `json:"per_page"`
+	Total   int         `json:"total"`
+}
+
+// apiServer indexes the database snapshot for the endpoints
+type apiServer struct {
+	db         Database
+	queuePath  string
+	byVersion  map[string]Writing
+	byPhelps   map[string][]Writing
+	byLanguage map[string][]Writing
+	languages  []string
+	names      map[string]string
+}
+
+func newAPIServer(db Database, queuePath string) *apiServer {
+	s := &apiServer{
+		db:         db,
+		queuePath:  queuePath,
+		byVersion:  make(map[string]Writing),
+		byPhelps:   make(map[string][]Writing),
+		byLanguage: make(map[string][]Writing),
+		names:      make(map[string]string),
+	}
+	for _, w := range db.Writing {
+		s.byVersion[w.Version] = w
+		if w.Phelps != "" {
+			s.byPhelps[w.Phelps] = append(s.byPhelps[w.Phelps], w)
+		}
+		if _, ok := s.byLanguage[w.Language]; !ok {
+			s.languages = append(s.languages, w.Language)
+		}
+		s.byLanguage[w.Language] = append(s.byLanguage[w.Language], w)
+	}
+	sort.Strings(s.languages)
+	for _, l := range db.Languages {
+		s.names[l.LangCode] = l.Name
+	}
+	return s
+}
+
+func (s *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("GET /api/writings/{version}", s.handleWriting)
+	mux.HandleFunc("GET /api/phelps/{code}", s.handlePhelps)
+	mux.HandleFunc("GET /api/phelps/{code}/missing", s.handleMissing)
+	mux.HandleFunc("GET /api/languages", s.handleLanguages)
+	mux.HandleFunc("GET /api/languages/{language}", s.handleLanguageStatus)
+	mux.HandleFunc("GET /api/languages/{language}/writings", s.handleLanguageWritings)
+	mux.HandleFunc("GET /api/languages/{language}/unmatched", s.handleLanguageWritings)
+	mux.HandleFunc("GET /api/review-queue", s.handleReviewQueue)
+	return mux
+}
+
+// writeCachedJSON writes a response with an ETag of its content and answers matching
+// If-None-Match requests with 304
+func writeCachedJSON(w http.ResponseWriter, r *http.Request, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:12]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if c := strings.TrimSpace(candidate); c == etag || c == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(body)
+	w.Write([]byte("\n"))
+}
+
+// paginate returns one page of a list according to ?page= and ?per_page=
+func paginate[T any](r *http.Request, items []T) (APIPage, error) {
+	page, perPage := 1, apiDefaultPerPage
+	if v := r.URL.Query().Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return APIPage{}, fmt.Errorf("invalid page %q", v)
+		}
+		page = n
+	}
+	if v := r.URL.Query().Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return APIPage{}, fmt.Errorf("invalid per_page %q", v)
+		}
+		perPage = min(n, apiMaxPerPage)
+	}
+
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+	return APIPage{Items: items[start:end], Page: page, PerPage: perPage, Total: len(items)}, nil
+}
+
+// writePage paginates a list and writes it
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	page, err := paginate(r, items)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	writeCachedJSON(w, r, page)
+}
+
+// apiWritings converts writings, with text when ?text=1
+func apiWritings(r *http.Request, writings []Writing) []APIWriting {
+	withText := parseBool(r.URL.Query().Get("text"))
+	result := make([]APIWriting, 0, len(writings))
+	for _, w := range writings {
+		result = append(result, toAPIWriting(w, withText))
+	}
+	return result
+}
+
+func toAPIWriting(w Writing, withText bool) APIWriting {
+	writing := APIWriting{
+		Version:  w.Version,
+		Language: w.Language,
+		Phelps:   w.Phelps,
+		Name:     w.Name,
+		Type:     w.Type,
+		Link:     w.Link,
+		Source:   w.Source,
+		SourceID: w.SourceID,
+		Verified: w.IsVerified,
+	}
+	if withText {
+		writing.Text = w.Text
+	}
+	return writing
+}
+
+// languageStatus counts a language the way GetProcessingStatus counts the database
+func (s *apiServer) languageStatus(language string) APILanguageStatus {
+	status := APILanguageStatus{Language: language, Name: s.names[language]}
+	for _, w := range s.byLanguage[language] {
+		status.TotalPrayers++
+		if w.Phelps != "" {
+			status.MatchedPrayers++
+		}
+	}
+	status.UnmatchedPrayers = status.TotalPrayers - status.MatchedPrayers
+	if status.TotalPrayers > 0 {
+		status.CompletionRate = (status.MatchedPrayers * 100) / status.TotalPrayers
+	}
+	return status
+}
+
+func (s *apiServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status := ProcessingStatus{TotalPrayers: len(s.db.Writing), TotalLanguages: len(s.languages)}
+	unprocessed := make(map[string]bool)
+	for _, writing := range s.db.Writing {
+		if writing.Phelps != "" {
+			status.MatchedPrayers++
+			continue
+		}
+		status.UnmatchedPrayers++
+		if writing.Language != "en" && !strings.HasSuffix(writing.Language, "-translit") {
+			unprocessed[writing.Language] = true
+		}
+	}
+	if status.TotalPrayers > 0 {
+		status.CompletionRate = (status.MatchedPrayers * 100) / status.TotalPrayers
+	}
+	status.UnprocessedLangs = len(unprocessed)
+
+	writeCachedJSON(w, r, map[string]int{
+		"total_prayers":     status.TotalPrayers,
+		"total_languages":   status.TotalLanguages,
+		"matched_prayers":   status.MatchedPrayers,
+		"unmatched_prayers": status.UnmatchedPrayers,
+		"completion_rate":   status.CompletionRate,
+		"unprocessed_langs": status.UnprocessedLangs,
+	})
+}
+
+func (s *apiServer) handleWriting(w http.ResponseWriter, r *http.Request) {
+	writing, ok := s.byVersion[r.PathValue("version")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no such writing"})
+		return
+	}
+	writeCachedJSON(w, r, toAPIWriting(writing, true))
+}
+
+// handlePhelps lists all translations of a code
+func (s *apiServer) handlePhelps(w http.ResponseWriter, r *http.Request) {
+	writings, ok := s.byPhelps[r.PathValue("code")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no writings with this Phelps code"})
+		return
+	}
+	writePage(w, r, apiWritings(r, writings))
+}
+
+// handleMissing lists the languages that have no version of a code
+func (s *apiServer) handleMissing(w http.ResponseWriter, r *http.Request) {
+	writings, ok := s.byPhelps[r.PathValue("code")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no writings with this Phelps code"})
+		return
+	}
+	has := make(map[string]bool)
+	for _, writing := range writings {
+		has[writing.Language] = true
+	}
+	missing := []APILanguageStatus{}
+	for _, language := range s.languages {
+		if !has[language] {
+			missing = append(missing, s.languageStatus(language))
+		}
+	}
+	writePage(w, r, missing)
+}
+
+func (s *apiServer) handleLanguages(w http.ResponseWriter, r *http.Request) {
+	statuses := make([]APILanguageStatus, 0, len(s.languages))
+	for _, language := range s.languages {
+		statuses = append(statuses, s.languageStatus(language))
+	}
+	writePage(w, r, statuses)
+}
+
+func (s *apiServer) handleLanguageStatus(w http.ResponseWriter, r *http.Request) {
+	language := r.PathValue("language")
+	if _, ok := s.byLanguage[language]; !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no writings in this language"})
+		return
+	}
+	writeCachedJSON(w, r, s.languageStatus(language))
+}
+
+// handleLanguageWritings lists the writings of a language, only the unmatched ones on /unmatched
+func (s *apiServer) handleLanguageWritings(w http.ResponseWriter, r *http.Request) {
+	writings, ok := s.byLanguage[r.PathValue("language")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no writings in this language"})
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/unmatched") {
+		var unmatched []Writing
+		for _, writing := range writings {
+			if writing.Phelps == "" {
+				unmatched = append(unmatched, writing)
+			}
+		}
+		writings = unmatched
+	}
+	writePage(w, r, apiWritings(r, writings))
+}
+
+// handleReviewQueue lists review items, filtered by ?language= and ?status=
+func (s *apiServer) handleReviewQueue(w http.ResponseWriter, r *http.Request) {
+	items, err := LoadReviewQueue(s.queuePath)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	language, status := r.URL.Query().Get("language"), r.URL.Query().Get("status")
+	filtered := []ReviewItem{}
+	for _, item := range items {
+		if language != "" && item.Language != language {
+			continue
+		}
+		if status != "" && item.Status != status && !(status == ReviewOpen && item.IsOpen()) {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+	writePage(w, r, filtered)
+}
+
+// ServeAPICommand loads the database and serves the read-only API on addr
+func ServeAPICommand(addr string) error {
+	db, err := GetDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load database: %w", err)
+	}
+
+	server := newAPIServer(db, reviewQueueFile)
+	log.Printf("🌐 Serving %d writings in %d languages at http://%s/api/ (Ctrl+C to stop)", len(db.Writing), len(server.languages), addr)
+	return http.ListenAndServe(addr, server.routes())
+}
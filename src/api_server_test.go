@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// Test the read-only API endpoints, pagination and ETags
+func TestAPIServer(t *testing.T) {
+	db := Database{
+		Writing: []Writing{
+			{Version: "en-1", Language: "en", Phelps: "BH00001ONE", Name: "Guidance", Text: "O God, guide me."},
+			{Version: "es-1", Language: "es", Phelps: "BH00001ONE", Text: "Oh Dios, guíame."},
+			{Version: "es-2", Language: "es"},
+			{Version: "es-3", Language: "es"},
+			{Version: "fr-1", Language: "fr"},
+		},
+		Languages: []Language{{LangCode: "fr", Name: "French"}},
+	}
+	queuePath := filepath.Join(t.TempDir(), reviewQueueFile)
+	items, _ := EnqueueReviewItems(nil, "es", ReviewAmbiguous, []ReviewProposal{
+		{Version: "es-2", ReviewCandidate: ReviewCandidate{Phelps: "BH00001ONE", Confidence: 60}},
+	})
+	if err := SaveReviewQueue(queuePath, items); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(newAPIServer(db, queuePath).routes())
+	defer server.Close()
+
+	get := func(path, etag string) (*http.Response, []byte) {
+		t.Helper()
+		request, _ := http.NewRequest("GET", server.URL+path, nil)
+		if etag != "" {
+			request.Header.Set("If-None-Match", etag)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		var body json.RawMessage
+		json.NewDecoder(response.Body).Decode(&body)
+		return response, body
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		total  int // expected total for list responses, -1 otherwise
+	}{
+		{"Writing by version", "/api/writings/es-1", http.StatusOK, -1},
+		{"Unknown version", "/api/writings/xx-1", http.StatusNotFound, -1},
+		{"Translations of a code", "/api/phelps/BH00001ONE", http.StatusOK, 2},
+		{"Languages lacking a code", "/api/phelps/BH00001ONE/missing", http.StatusOK, 1},
+		{"Language writings", "/api/languages/es/writings", http.StatusOK, 3},
+		{"Unmatched in language", "/api/languages/es/unmatched", http.StatusOK, 2},
+		{"All languages", "/api/languages", http.StatusOK, 3},
+		{"Open review items", "/api/review-queue?status=open&language=es", http.StatusOK, 1},
+		{"Bad page", "/api/languages?page=0", http.StatusBadRequest, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, body := get(tt.path, "")
+			if response.StatusCode != tt.status {
+				t.Fatalf("Got status %d, want %d: %s", response.StatusCode, tt.status, body)
+			}
+			if tt.total >= 0 {
+				var page APIPage
+				json.Unmarshal(body, &page)
+				if page.Total != tt.total {
+					t.Errorf("Got total %d, want %d: %s", page.Total, tt.total, body)
+				}
+			}
+		})
+	}
+
+	var status APILanguageStatus
+	_, body := get("/api/languages/es", "")
+	json.Unmarshal(body, &status)
+	if status.TotalPrayers != 3 || status.MatchedPrayers != 1 || status.CompletionRate != 33 {
+		t.Errorf("Unexpected language status %+v", status)
+	}
+
+	var page struct {
+		APIPage
+		Items []APIWriting `json:"items"`
+	}
+	_, body = get("/api/languages/es/writings?per_page=2&page=2", "")
+	json.Unmarshal(body, &page)
+	if len(page.Items) != 1 || page.Items[0].Version != "es-3" || page.Items[0].Text != "" {
+		t.Errorf("Unexpected second page %s", body)
+	}
+
+	response, _ := get("/api/writings/en-1", "")
+	etag := response.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+	if response, _ := get("/api/writings/en-1", etag); response.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected 304 for a matching ETag, got %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// arabicScriptLanguages are the languages written in Arabic script that get
+// diacritic folding and letter unification before fingerprinting
+var arabicScriptLanguages = map[string]bool{
+	"ar": true, "fa": true, "ur": true, "ps": true,
+}
+
+// arabicLetterVariants folds Persian, Urdu and Pashto letter forms (and hamza
+// carriers) onto a single Arabic form so the same word hashes identically.
+// Bare hamza and hamza on yeh are dropped by arabicDiacriticsRegex instead,
+// since spellings such as بهاء/بهائك/بها vary between editions.
+var arabicLetterVariants = map[rune]rune{
+	'أ': 'ا', 'إ': 'ا', 'آ': 'ا', 'ٱ': 'ا', 'ٲ': 'ا', 'ٳ': 'ا',
+	'ى': 'ي', 'ی': 'ي', 'ې': 'ي', 'ۍ': 'ي', 'ێ': 'ي',
+	'ک': 'ك', 'ڪ': 'ك', 'ګ': 'ك',
+	'ہ': 'ه', 'ۀ': 'ه', 'ە': 'ه', 'ة': 'ه', 'ھ': 'ه',
+	'ؤ': 'و',
+	'،': ',', '؛': ';', '؟': '?', '٪': '%',
+}
+
+// arabicInvocations are opening formulas common to Arabic and Persian prayers
+var arabicInvocations = []string{
+	"هو الله", "هوالله", "هو الأبهى", "بسم الله", "بسمه", "یا إلهی",
+	"إلهي إلهي", "اللهم", "يا رب", "يا الله", "سبحانك اللهم",
+	"خداوندا", "پروردگارا", "ای خدا", "ای پروردگار",
+}
+
+// arabicBlessings are blessing formulas in Arabic and Persian
+var arabicBlessings = []string{
+	"بارك", "مبارك", "برکت", "بركات", "متبارك", "طوبى",
+}
+
+// arabicSupplications are petitionary formulas in Arabic and Persian
+var arabicSupplications = []string{
+	"اسالك", "أسألك", "ايدني", "ايد", "وفق", "اعطني", "ارزق", "انزل",
+	"عطا فرما", "عنايت فرما", "مرحمت فرما", "موفق فرما", "مؤيد فرما",
+}
+
+// arabicKeyTerms maps Arabic-script theological terms to their transliteration
+// and an English gloss, used both as key terms and phonetic terms
+var arabicKeyTerms = map[string][]string{
+	"الله":       {"allah", "god"},
+	"بهاء":       {"baha", "glory"},
+	"الابهى":     {"abha", "most-glorious"},
+	"بهاءالله":   {"bahaullah"},
+	"عبدالبهاء":  {"abdul-baha"},
+	"رب":         {"rabb", "lord"},
+	"اله":        {"ilah", "god"},
+	"الهي":       {"ilahi", "my-god"},
+	"خدا":        {"khuda", "god"},
+	"خداوند":     {"khudavand", "lord"},
+	"پروردگار":   {"parvardigar", "lord"},
+	"رحمن":       {"rahman", "merciful"},
+	"رحيم":       {"rahim", "compassionate"},
+	"رحمت":       {"rahmat", "mercy"},
+	"غفور":       {"ghafur", "forgiving"},
+	"مغفرت":      {"maghfirat", "forgiveness"},
+	"ملكوت":      {"malakut", "kingdom"},
+	"جبروت":      {"jabarut", "dominion"},
+	"لاهوت":      {"lahut", "divinity"},
+	"عهد":        {"ahd", "covenant"},
+	"ميثاق":      {"mithaq", "covenant"},
+	"شفاء":       {"shifa", "healing"},
+	"نور":        {"nur", "light"},
+	"روح":        {"ruh", "spirit"},
+	"قدس":        {"quds", "holy"},
+	"عبد":        {"abd", "servant"},
+	"امه":        {"amah", "handmaiden"},
+	"ظهور":       {"zuhur", "manifestation"},
+	"مظهر":       {"mazhar", "manifestation"},
+	"قلم":        {"qalam", "pen"},
+	"سلطان":      {"sultan", "sovereign"},
+	"مناجات":     {"munajat", "prayer"},
+	"تاييد":      {"tayid", "confirmation"},
+	"هدايت":      {"hidayat", "guidance"},
+	"هدايه":      {"hidayah", "guidance"},
+	"حفظ":        {"hifz", "protection"},
+	"وحدت":       {"vahdat", "unity"},
+	"اتحاد":      {"ittihad", "unity"},
+	"شكر":        {"shukr", "gratitude"},
+	"ثنا":        {"thana", "praise"},
+	"حمد":        {"hamd", "praise"},
+	"تسبيح":      {"tasbih", "glorification"},
+	"فضل":        {"fadl", "grace"},
+	"عنايت":      {"inayat", "bounty"},
+	"ملكوت الله": {"malakut-allah", "kingdom-of-god"},
+}
+
+// arabicPrayerTypeKeywords mirrors prayerTypeKeywords for Arabic-script texts
+var arabicPrayerTypeKeywords = map[string][]string{
+	"devotional":  {"حمد", "ثنا", "تسبيح", "سبحان"},
+	"healing":     {"شفا", "شافي", "دوا"},
+	"protection":  {"حفظ", "حمايت", "صيانت", "ملجا", "پناه"},
+	"guidance":    {"هدايت", "هدايه", "صراط", "راه"},
+	"forgiveness": {"مغفرت", "غفران", "عفو", "غفور"},
+	"unity":       {"اتحاد", "وحدت", "اتفاق"},
+	"assistance":  {"تاييد", "نصرت", "توفيق", "مدد"},
+	"gratitude":   {"شكر", "سپاس"},
+}
+
+// arabicProclitics are single-letter prefixes stripped when looking up key terms
+var arabicProclitics = []string{"و", "ف", "ب", "ل", "ك"}
+
+// arabicEnclitics are pronoun and vocative suffixes stripped when looking up key terms
+var arabicEnclitics = []string{"ها", "هم", "كم", "نا", "يا", "ي", "ك", "ه", "ا"}
+
+var arabicDiacriticsRegex = regexp.MustCompile(`[\x{0610}-\x{061A}\x{064B}-\x{065F}\x{0670}\x{06D6}-\x{06DC}\x{06DF}-\x{06E8}\x{06EA}-\x{06ED}\x{0640}\x{0621}\x{0626}]`)
+
+// isArabicScript checks if a language is written in Arabic script
+func isArabicScript(language string) bool {
+	return arabicScriptLanguages[language]
+}
+
+// normalizeArabicScript strips harakat, tatweel and loose hamza, unifies letter variants and
+// converts Arabic-Indic digits so Arabic, Persian, Urdu and Pashto texts compare
+func normalizeArabicScript(text string) string {
+	if len(text) == 0 {
+		return text
+	}
+
+	text = arabicDiacriticsRegex.ReplaceAllString(text, "")
+
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case r >= 0x0660 && r <= 0x0669: // Arabic-Indic digits
+			runes[i] = '0' + (r - 0x0660)
+		case r >= 0x06F0 && r <= 0x06F9: // Extended Arabic-Indic (Persian/Urdu) digits
+			runes[i] = '0' + (r - 0x06F0)
+		case r == 0x200C: // Zero-width non-joiner is written as a space by many typists
+			runes[i] = ' '
+		default:
+			if folded, exists := arabicLetterVariants[r]; exists {
+				runes[i] = folded
+			}
+		}
+	}
+
+	return string(runes)
+}
+
+// arabicWordCandidates returns the word itself plus forms with common clitics removed
+func arabicWordCandidates(word string) []string {
+	candidates := []string{word}
+	stems := []string{word}
+
+	for _, p := range arabicProclitics {
+		if strings.HasPrefix(word, p) && len([]rune(word)) > 3 {
+			stems = append(stems, strings.TrimPrefix(word, p))
+		}
+	}
+	for _, stem := range stems {
+		if strings.HasPrefix(stem, "ال") && len([]rune(stem)) > 3 {
+			stems = append(stems, strings.TrimPrefix(stem, "ال"))
+		}
+	}
+
+	for _, stem := range stems {
+		candidates = append(candidates, stem)
+		for _, s := range arabicEnclitics {
+			if strings.HasSuffix(stem, s) && len([]rune(stem))-len([]rune(s)) >= 2 {
+				candidates = append(candidates, strings.TrimSuffix(stem, s))
+			}
+		}
+	}
+
+	return candidates
+}
+
+// normalizedArabicPatterns folds a pattern list through normalizeArabicScript so
+// the lists above can be written in any common spelling
+func normalizedArabicPatterns(patterns []string) []string {
+	result := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		result = append(result, normalizeArabicScript(p))
+	}
+	return result
+}
+
+// containsAnyArabic checks normalized text for any normalized pattern
+func containsAnyArabic(text string, patterns []string) bool {
+	for _, p := range normalizedArabicPatterns(patterns) {
+		if strings.Contains(text, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasArabicInvocation detects invocations such as "هو الله" and "بسم الله"
+func hasArabicInvocation(text string) bool {
+	return containsAnyArabic(text, arabicInvocations)
+}
+
+// hasArabicBlessings detects Arabic and Persian blessing patterns
+func hasArabicBlessings(text string) bool {
+	return containsAnyArabic(text, arabicBlessings)
+}
+
+// hasArabicSupplication detects Arabic and Persian supplication patterns
+func hasArabicSupplication(text string) bool {
+	return containsAnyArabic(text, arabicSupplications)
+}
+
+// findArabicKeyTerms identifies theological terms in normalized Arabic-script text
+func findArabicKeyTerms(text string) []string {
+	terms := make(map[string]string, len(arabicKeyTerms))
+	for term := range arabicKeyTerms {
+		terms[normalizeArabicScript(term)] = term
+	}
+
+	found := make(map[string]bool)
+	words := strings.Fields(text)
+	for i, word := range words {
+		clean := strings.Trim(word, ".,!?;:«»()\"'")
+		for _, candidate := range arabicWordCandidates(clean) {
+			if term, ok := terms[candidate]; ok {
+				found[term] = true
+			}
+		}
+		// Two-word terms such as "ملكوت الله"
+		if i+1 < len(words) {
+			pair := clean + " " + strings.Trim(words[i+1], ".,!?;:«»()\"'")
+			if term, ok := terms[pair]; ok {
+				found[term] = true
+			}
+		}
+	}
+
+	var result []string
+	for term := range found {
+		result = append(result, term)
+	}
+	sort.Strings(result)
+
+	if len(result) > 15 {
+		return result[:15]
+	}
+	return result
+}
+
+// extractArabicPhoneticTerms transliterates Arabic-script key terms so they can be
+// compared with Latin-script fingerprints and with each other across ar/fa/ur/ps
+func extractArabicPhoneticTerms(keyTerms []string) []string {
+	seen := make(map[string]bool)
+	var phonetic []string
+
+	for _, term := range keyTerms {
+		for _, p := range arabicKeyTerms[term] {
+			if !seen[p] {
+				seen[p] = true
+				phonetic = append(phonetic, p)
+			}
+		}
+	}
+
+	return phonetic
+}
+
+// determineArabicPrayerType classifies Arabic-script prayers using arabicPrayerTypeKeywords
+func determineArabicPrayerType(text string) string {
+	maxScore := 0
+	bestType := "general"
+
+	types := make([]string, 0, len(arabicPrayerTypeKeywords))
+	for prayerType := range arabicPrayerTypeKeywords {
+		types = append(types, prayerType)
+	}
+	sort.Strings(types)
+
+	for _, prayerType := range types {
+		score := 0
+		for _, keyword := range normalizedArabicPatterns(arabicPrayerTypeKeywords[prayerType]) {
+			if strings.Contains(text, keyword) {
+				score++
+			}
+		}
+		if score > maxScore {
+			maxScore = score
+			bestType = prayerType
+		}
+	}
+
+	return bestType
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Confidence calibration: the confidence an LLM states is not a probability. -calibrate
+// fits, from human review decisions and -evaluate runs against verified matches, how
+// often a proposal of a given backend, match type, confidence and kind of evidence turns
+// out right, and stores the counts in calibration.json. Every auto-apply decision asks
+// shouldAutoApply whether that empirical precision reaches -target-precision; without
+// data the stated confidence is the estimate, so at the default 0.9 EXACT, LIKELY and
+// RESOLVED matches need a confidence of 90.
+
+const (
+	calibrationFile     = "calibration.json"
+	calibrationBins     = 10  // confidence deciles
+	calibrationStrength = 5.0 // pseudo-observations a coarser level's estimate is worth
+	calibrationAny      = "*"
+)
+
+var targetPrecision = 0.9 // precision an auto-applied match must reach, set with -target-precision
+
+// CalibrationObservation is one proposal whose correctness is known
+type CalibrationObservation struct {
+	Backend    string
+	MatchType  string
+	Confidence float64
+	Reasons    []string
+	Correct    bool
+}
+
+// CalibrationCount is how many proposals in a bin there were and how many were right
+type CalibrationCount struct {
+	N       int `json:"n"`
+	Correct int `json:"correct"`
+}
+
+// CalibrationModel holds the observed counts per bin at every level of detail, keyed
+// "backend|match type|evidence" with * for levels that pool over a field
+type CalibrationModel struct {
+	Fitted       time.Time                     `json:"fitted"`
+	Observations int                           `json:"observations"`
+	Counts       map[string][]CalibrationCount `json:"counts"`
+}
+
+var (
+	calibration     *CalibrationModel
+	calibrationOnce sync.Once
+)
+
+// reasonClass condenses match reasons to the evidence that matters for precision
+func reasonClass(reasons []string) string {
+	class := "plain"
+	for _, reason := range reasons {
+		switch {
+		case strings.HasPrefix(reason, "local "):
+			return hashBackend
+		case strings.HasPrefix(reason, "second opinion: confirmed"):
+			class = "confirmed"
+		case strings.HasPrefix(reason, "second opinion: improved"):
+			class = "improved"
+		case strings.HasPrefix(reason, "second opinion: questioned"):
+			class = "questioned"
+		}
+	}
+	return class
+}
+
+// calibrationBin is the decile a confidence (0-100) falls in
+func calibrationBin(confidence float64) int {
+	return min(max(int(confidence)/10, 0), calibrationBins-1)
+}
+
+// calibrationKeys lists the distinct keys of a combination from the coarsest level to
+// the finest; without a backend the finest level is the one above it
+func calibrationKeys(backend, matchType, class string) []string {
+	if backend == "" {
+		backend = calibrationAny
+	}
+	var keys []string
+	for _, parts := range [][]string{
+		{calibrationAny, calibrationAny, calibrationAny},
+		{calibrationAny, matchType, calibrationAny},
+		{calibrationAny, matchType, class},
+		{backend, matchType, class},
+	} {
+		if key := strings.Join(parts, "|"); len(keys) == 0 || keys[len(keys)-1] != key {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// calibrationPrior is the precision assumed without data
+func calibrationPrior(matchType string, confidence float64) float64 {
+	p := confidence / 100
+	if p > 1 {
+		p = 1
+	}
+	switch matchType {
+	case "EXACT", "LIKELY", "RESOLVED":
+		return p
+	case "AMBIGUOUS":
+		if p > 0.5 {
+			return 0.5
+		}
+		return p
+	}
+	return 0
+}
+
+// FitCalibration counts the observations at every level
+func FitCalibration(observations []CalibrationObservation) *CalibrationModel {
+	model := &CalibrationModel{Fitted: time.Now(), Observations: len(observations), Counts: make(map[string][]CalibrationCount)}
+	for _, o := range observations {
+		bin := calibrationBin(o.Confidence)
+		for _, key := range calibrationKeys(o.Backend, o.MatchType, reasonClass(o.Reasons)) {
+			if model.Counts[key] == nil {
+				model.Counts[key] = make([]CalibrationCount, calibrationBins)
+			}
+			model.Counts[key][bin].N++
+			if o.Correct {
+				model.Counts[key][bin].Correct++
+			}
+		}
+	}
+	return model
+}
+
+// curve estimates the precision of every bin for one combination: each level shrinks
+// the counts towards the level above it, and the result is made non-decreasing in
+// confidence with pool-adjacent-violators. The prior of the bin confidence falls in is
+// that confidence itself, the other bins take their midpoint
+func (m *CalibrationModel) curve(backend, matchType, class string, confidence float64) []float64 {
+	estimates := make([]float64, calibrationBins)
+	weights := make([]float64, calibrationBins)
+	for bin := range estimates {
+		estimates[bin] = calibrationPrior(matchType, float64(bin*10+5))
+		weights[bin] = calibrationStrength
+	}
+	estimates[calibrationBin(confidence)] = calibrationPrior(matchType, confidence)
+	if m != nil {
+		for _, key := range calibrationKeys(backend, matchType, class) {
+			counts := m.Counts[key]
+			if counts == nil {
+				continue
+			}
+			for bin, c := range counts {
+				estimates[bin] = (float64(c.Correct) + calibrationStrength*estimates[bin]) / (float64(c.N) + calibrationStrength)
+				weights[bin] = float64(c.N) + calibrationStrength
+			}
+		}
+	}
+
+	// Pool adjacent bins that violate monotonicity into their weighted mean
+	type block struct {
+		value, weight float64
+		size          int
+	}
+	var blocks []block
+	for bin := range estimates {
+		blocks = append(blocks, block{estimates[bin], weights[bin], 1})
+		for len(blocks) > 1 && blocks[len(blocks)-2].value > blocks[len(blocks)-1].value {
+			a, b := blocks[len(blocks)-2], blocks[len(blocks)-1]
+			weight := a.weight + b.weight
+			blocks = append(blocks[:len(blocks)-2], block{(a.value*a.weight + b.value*b.weight) / weight, weight, a.size + b.size})
+		}
+	}
+	result := make([]float64, 0, calibrationBins)
+	for _, b := range blocks {
+		for i := 0; i < b.size; i++ {
+			result = append(result, b.value)
+		}
+	}
+	return result
+}
+
+// Precision estimates how often a proposal like this one is right
+func (m *CalibrationModel) Precision(backend, matchType string, confidence float64, reasons []string) float64 {
+	switch matchType {
+	case "EXACT", "LIKELY", "AMBIGUOUS", "RESOLVED":
+	default:
+		return 0
+	}
+	class := reasonClass(reasons)
+	if class == hashBackend {
+		backend = hashBackend
+	}
+	return m.curve(backend, matchType, class, confidence)[calibrationBin(confidence)]
+}
+
+// loadCalibrationModel reads a fitted model; a missing file means no data yet
+func loadCalibrationModel(path string) (*CalibrationModel, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var model CalibrationModel
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// currentCalibration loads calibration.json once per run
+func currentCalibration() *CalibrationModel {
+	calibrationOnce.Do(func() {
+		model, err := loadCalibrationModel(calibrationFile)
+		if err != nil {
+			log.Printf("⚠️ Ignoring %s, using stated confidence: %v", calibrationFile, err)
+		}
+		calibration = model
+	})
+	return calibration
+}
+
+// shouldAutoApply reports whether a proposal is precise enough to write without review;
+// backend is the one that proposed it ("" when unknown pools all backends), and local
+// text-hash matches are told apart by their reasons
+func shouldAutoApply(backend, matchType string, confidence float64, reasons []string) bool {
+	return currentCalibration().Precision(backend, matchType, confidence, reasons) >= targetPrecision
+}
+
+// reviewObservations turns human review decisions into observations: every candidate
+// of an approved, modified or rejected item was right exactly when it is the decision
+func reviewObservations(items []ReviewItem) []CalibrationObservation {
+	var observations []CalibrationObservation
+	for _, item := range items {
+		if item.Status != ReviewApproved && item.Status != ReviewModified && item.Status != ReviewRejected {
+			continue
+		}
+		for _, c := range item.Candidates {
+			observations = append(observations, CalibrationObservation{
+				MatchType:  c.MatchType,
+				Confidence: c.Confidence,
+				Reasons:    c.Reasons,
+				Correct:    sameTablet(c.Phelps, item.Decision),
+			})
+		}
+	}
+	return observations
+}
+
+// evaluationObservations reads the scored proposals of -evaluate reports
+func evaluationObservations(paths []string) ([]CalibrationObservation, error) {
+	var observations []CalibrationObservation
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var report EvaluationReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, o := range report.Outcomes {
+			if o.Predicted == "" {
+				continue
+			}
+			observations = append(observations, CalibrationObservation{
+				Backend:    o.Backend,
+				MatchType:  o.MatchType,
+				Confidence: o.Confidence,
+				Reasons:    o.Reasons,
+				Correct:    o.Correct,
+			})
+		}
+	}
+	return observations, nil
+}
+
+// minimumConfidence is the lowest confidence a match type needs to reach the target
+func (m *CalibrationModel) minimumConfidence(backend, matchType, class string) (int, bool) {
+	for bin := 0; bin < calibrationBins; bin++ {
+		if m.curve(backend, matchType, class, float64(bin*10))[bin] >= targetPrecision {
+			return bin * 10, true
+		}
+	}
+	return 0, false
+}
+
+// CalibrateCommand fits calibration.json from review decisions and evaluation reports
+func CalibrateCommand() error {
+	items, err := LoadReviewQueue(reviewQueueFile)
+	if err != nil {
+		return fmt.Errorf("failed to load review queue: %w", err)
+	}
+	observations := reviewObservations(items)
+	reviewed := len(observations)
+
+	reports, _ := filepath.Glob("evaluation_*.json")
+	evaluated, err := evaluationObservations(reports)
+	if err != nil {
+		return fmt.Errorf("failed to read evaluation reports: %w", err)
+	}
+	observations = append(observations, evaluated...)
+	if len(observations) == 0 {
+		return fmt.Errorf("no reviewed items or evaluation reports to learn from; review matches or run -evaluate first")
+	}
+
+	model := FitCalibration(observations)
+	data, err := json.MarshalIndent(model, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(calibrationFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", calibrationFile, err)
+	}
+
+	log.Printf("🎚️ Calibrated on %d reviewed candidates and %d scored proposals from %d evaluation reports", reviewed, len(evaluated), len(reports))
+	log.Printf("Observed precision per match type (confidence decile: right/total):")
+	for _, matchType := range []string{"EXACT", "LIKELY", "AMBIGUOUS"} {
+		counts := model.Counts[strings.Join([]string{calibrationAny, matchType, calibrationAny}, "|")]
+		if counts == nil {
+			continue
+		}
+		var cells []string
+		for bin, c := range counts {
+			if c.N > 0 {
+				cells = append(cells, fmt.Sprintf("%d+: %d/%d", bin*10, c.Correct, c.N))
+			}
+		}
+		log.Printf("  %-10s %s", matchType, strings.Join(cells, "  "))
+	}
+
+	log.Printf("Minimum confidence to auto-apply at %.0f%% precision:", 100*targetPrecision)
+	var keys []string
+	for key := range model.Counts {
+		if parts := strings.Split(key, "|"); parts[1] != calibrationAny && parts[1] != "AMBIGUOUS" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts := strings.Split(key, "|")
+		if threshold, ok := model.minimumConfidence(parts[0], parts[1], parts[2]); ok {
+			log.Printf("  %-32s %d", key, threshold)
+		} else {
+			log.Printf("  %-32s never", key)
+		}
+	}
+	log.Printf("✅ Calibration written to %s", calibrationFile)
+	return nil
+}
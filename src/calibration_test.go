@@ -0,0 +1,86 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Test fitting calibration and the precision it assigns to proposals
+func TestCalibration(t *testing.T) {
+	// Without data the stated confidence is the estimate
+	var empty *CalibrationModel
+	tests := []struct {
+		matchType  string
+		confidence float64
+		expected   float64
+	}{
+		{"EXACT", 97, 0.97},
+		{"EXACT", 90, 0.90},
+		{"LIKELY", 89, 0.89},
+		{"LIKELY", 82, 0.82},
+		{"AMBIGUOUS", 90, 0.5},
+		{"NEW_TRANSLATION", 99, 0},
+	}
+	for _, tt := range tests {
+		if got := empty.Precision("Claude CLI", tt.matchType, tt.confidence, nil); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("%s at %.0f: expected %.2f, got %.2f", tt.matchType, tt.confidence, tt.expected, got)
+		}
+	}
+
+	// LIKELY 80-89 from ollama turns out right only a third of the time
+	var observations []CalibrationObservation
+	for i := 0; i < 30; i++ {
+		observations = append(observations, CalibrationObservation{Backend: "ollama", MatchType: "LIKELY", Confidence: 85, Correct: i%3 == 0})
+		observations = append(observations, CalibrationObservation{Backend: "Claude CLI", MatchType: "LIKELY", Confidence: 85, Correct: true})
+	}
+	model := FitCalibration(observations)
+	if model.Observations != 60 || model.Counts["*|LIKELY|plain"][8].N != 60 || model.Counts["ollama|LIKELY|plain"][8].Correct != 10 {
+		t.Fatalf("Unexpected counts %+v", model.Counts)
+	}
+	ollama := model.Precision("ollama", "LIKELY", 85, nil)
+	claude := model.Precision("Claude CLI", "LIKELY", 85, nil)
+	if ollama >= 0.6 || claude <= 0.9 {
+		t.Errorf("Expected ollama well below and Claude above the pooled estimate, got %.2f and %.2f", ollama, claude)
+	}
+
+	// Auto-apply follows the backend that proposed the match, not the last one called
+	calibrationOnce.Do(func() {})
+	calibration = model
+	defer func() { calibration = nil }()
+	if shouldAutoApply("ollama", "LIKELY", 85, nil) || !shouldAutoApply("Claude CLI", "LIKELY", 85, nil) {
+		t.Errorf("Expected only the Claude CLI proposal to be applied")
+	}
+
+	// Estimates never drop as confidence rises
+	curve := model.curve("ollama", "LIKELY", "plain", 85)
+	for bin := 1; bin < len(curve); bin++ {
+		if curve[bin] < curve[bin-1] {
+			t.Errorf("Curve decreases at bin %d: %v", bin, curve)
+		}
+	}
+
+	if threshold, ok := empty.minimumConfidence("Claude CLI", "LIKELY", "plain"); !ok || threshold != 90 {
+		t.Errorf("Expected uncalibrated LIKELY matches to need 90, got %d", threshold)
+	}
+
+	if class := reasonClass([]string{"local exact text match with fr-1", "second opinion: confirmed"}); class != hashBackend {
+		t.Errorf("Expected text-hash evidence, got %s", class)
+	}
+	if class := reasonClass([]string{"same opening", "second opinion: questioned (90% -> 70%)"}); class != "questioned" {
+		t.Errorf("Expected questioned evidence, got %s", class)
+	}
+}
+
+// Test learning from human review decisions
+func TestReviewObservations(t *testing.T) {
+	items := []ReviewItem{
+		{Status: ReviewApproved, Decision: "BH00001ONE", Candidates: []ReviewCandidate{{Phelps: "BH00001ONE§2", MatchType: "AMBIGUOUS", Confidence: 60}, {Phelps: "AB00002TWO", Confidence: 50}}},
+		{Status: ReviewRejected, Decision: "NONE", Candidates: []ReviewCandidate{{Phelps: "AB00002TWO", Confidence: 70}}},
+		{Status: ReviewResolved, Decision: "AB00002TWO", Candidates: []ReviewCandidate{{Phelps: "AB00002TWO", Confidence: 90}}}, // decided by the LLM
+		{Status: ReviewOpen, Candidates: []ReviewCandidate{{Phelps: "AB00002TWO", Confidence: 90}}},
+	}
+	observations := reviewObservations(items)
+	if len(observations) != 3 || !observations[0].Correct || observations[1].Correct || observations[2].Correct {
+		t.Errorf("Unexpected observations %+v", observations)
+	}
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dry runs: every Phelps code write goes through writePhelps. With -dry-run it records the
+// change next to the code it would replace instead of writing it, and at the end of the
+// run the proposals are saved as changeset_<timestamp>.json plus a .sql script to read.
+// -apply-changeset writes such a file later, skipping every prayer whose code changed
+// since the dry run.
+
+var dryRunMode bool // set with -dry-run
+
+// ProposedChange is one write of a Phelps code, or the insert of a new LLM translation
+type ProposedChange struct {
+	Version    string   `json:"version"`
+	Language   string   `json:"language"`
+	OldPhelps  string   `json:"old_phelps"`
+	NewPhelps  string   `json:"new_phelps"` // empty clears the code
+	Passage    int      `json:"passage,omitempty"`
+	Text       string   `json:"text,omitempty"` // set for a new translation to insert
+	Confidence float64  `json:"confidence"`
+	MatchType  string   `json:"match_type"`
+	Reasons    []string `json:"reasons,omitempty"`
+	Source     string   `json:"source"` // the mode that proposed the change
+}
+
+// Changeset is what a dry run would have written
+type Changeset struct {
+	Created   time.Time        `json:"created"`
+	SessionID string           `json:"session_id"`
+	RunArgs   string           `json:"run_args"`
+	Changes   []ProposedChange `json:"changes"`
+}
+
+// phelpsRow is what a write needs to know about a prayer
+type phelpsRow struct {
+	Language   string
+	Phelps     string
+	IsVerified bool
+	Reviewed   bool // a reviewer approved or set its code
+}
+
+var (
+	proposedChanges []ProposedChange
+	phelpsRows      map[string]phelpsRow // codes as this run left them, loaded on first write
+)
+
+// loadPhelpsRows reads the language, code and verification of every writing
+func loadPhelpsRows() (map[string]phelpsRow, error) {
+	records, err := execDoltQueryCSV("SELECT version, language, phelps, is_verified FROM writings")
+	if err != nil {
+		return nil, err
+	}
+	rows := make(map[string]phelpsRow)
+	for i, rec := range records {
+		if i == 0 || len(rec) < 4 {
+			continue
+		}
+		rows[rec[0]] = phelpsRow{Language: rec[1], Phelps: normalizePhelps(rec[2]), IsVerified: parseBool(rec[3])}
+	}
+	return rows, nil
+}
+
+// changeSQL is the statement that makes a change, restricted by guard when given; a new
+// translation is only inserted while its version does not exist
+func changeSQL(change ProposedChange, guard string) string {
+	escape := func(s string) string { return strings.ReplaceAll(s, "'", "''") }
+
+	if change.Text != "" {
+		return fmt.Sprintf(`INSERT INTO writings (phelps, language, version, name, text, source, is_verified)
+	SELECT '%s', '%s', '%s', 'LLM Translation', '%s', '%s', false FROM DUAL
+	WHERE NOT EXISTS (SELECT 1 FROM writings WHERE version = '%s')`,
+			escape(change.NewPhelps), escape(change.Language), escape(change.Version),
+			escape(change.Text), llmTranslationSource, escape(change.Version))
+	}
+
+	value := "NULL"
+	if change.NewPhelps != "" {
+		value = "'" + escape(change.NewPhelps) + "'"
+	}
+	where := fmt.Sprintf("version = '%s'", escape(change.Version))
+	if change.Language != "" {
+		where += fmt.Sprintf(" AND language = '%s'", escape(change.Language))
+	}
+	if guard != "" {
+		where += " AND " + guard
+	}
+	return fmt.Sprintf("UPDATE writings SET phelps = %s WHERE %s", value, where)
+}
+
+// unchangedSQL holds a change to prayers that still have the code it was proposed against
+func unchangedSQL(change ProposedChange) string {
+	if change.OldPhelps == "" {
+		return unmatchedSQL("phelps")
+	}
+	return fmt.Sprintf("phelps = '%s'", strings.ReplaceAll(change.OldPhelps, "'", "''"))
+}
+
+// changeGuardSQL holds a change to unverified prayers that still have the code it was
+// proposed against
+func changeGuardSQL(change ProposedChange) string {
+	return unchangedSQL(change) + " AND " + notVerifiedSQL
+}
+
+// writePhelps sets the Phelps code of a prayer, or inserts a new translation when the change
+// carries a text; with onlyUnmatched a prayer that has a code keeps it. Changes the
+// protection policy forbids are not made, and replacements of real codes are queued for
+// review. In a dry run the change is proposed instead. Reports whether the change was
+// written or proposed
+func writePhelps(change ProposedChange, onlyUnmatched bool) (bool, error) {
+	if phelpsRows == nil {
+		rows, err := loadPhelpsRows()
+		if err != nil {
+			return false, fmt.Errorf("failed to read current codes: %w", err)
+		}
+		markReviewedRows(rows)
+		phelpsRows = rows
+	}
+
+	if err := validatePassage(change.NewPhelps, change.Passage); err != nil {
+		return false, err
+	}
+
+	row, exists := phelpsRows[change.Version]
+	if change.Text != "" {
+		if exists {
+			return false, nil
+		}
+	} else {
+		if !exists {
+			return false, fmt.Errorf("%s not found in database", change.Version)
+		}
+		if row.Phelps == change.NewPhelps || (onlyUnmatched && row.Phelps != "") {
+			return false, nil
+		}
+		if change.Language == "" {
+			change.Language = row.Language
+		}
+		change.OldPhelps = row.Phelps
+		if reason, review := protectionReason(row, change); reason != "" {
+			log.Printf("   🔒 %s %s, not writing %s", change.Version, reason, displayPhelps(change.NewPhelps))
+			if review {
+				queueCorrection(change)
+			}
+			return false, nil
+		}
+	}
+
+	if dryRunMode {
+		proposedChanges = append(proposedChanges, change)
+	} else {
+		if _, err := execDoltQuery(changeSQL(change, changeGuardSQL(change))); err != nil {
+			return false, err
+		}
+		notePassageMatch(change.Version, change.NewPhelps, change.Passage)
+	}
+	row.Language, row.Phelps = change.Language, change.NewPhelps
+	phelpsRows[change.Version] = row
+	return true, nil
+}
+
+// highestProposedTMP is the highest TMP number proposed so far, so a dry run hands out
+// the same new codes a real run would
+func highestProposedTMP() int {
+	highest := 0
+	for _, change := range proposedChanges {
+		if !isTMPCode(change.NewPhelps) {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(change.NewPhelps, TMP_CODE_PREFIX)); err == nil && n > highest {
+			highest = n
+		}
+	}
+	return highest
+}
+
+// writeChangesetSQL writes the changes as statements that only apply while each prayer
+// still has the code the change was proposed against
+func writeChangesetSQL(w io.Writer, cs Changeset) error {
+	fmt.Fprintf(w, "-- Changeset proposed by a dry run: %s\n", cs.RunArgs)
+	fmt.Fprintf(w, "-- Generated: %s\n", cs.Created.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "-- Total changes: %d\n\n", len(cs.Changes))
+
+	for _, change := range cs.Changes {
+		old := change.OldPhelps
+		if old == "" {
+			old = "(none)"
+		}
+		if change.Text != "" {
+			fmt.Fprintf(w, "-- %s, %s: new translation of %s (%s)\n", change.Source, strings.ToUpper(change.Language), change.NewPhelps, change.Version)
+		} else {
+			fmt.Fprintf(w, "-- %s, %s: %s %s -> %s\n", change.Source, strings.ToUpper(change.Language), change.Version, old, change.NewPhelps)
+		}
+		fmt.Fprintf(w, "-- Confidence: %.0f%%, Type: %s\n", change.Confidence, change.MatchType)
+		if len(change.Reasons) > 0 {
+			fmt.Fprintf(w, "-- Reasons: %s\n", sqlComment(strings.Join(change.Reasons, "; "), 120))
+		}
+		if change.Passage > 0 {
+			fmt.Fprintf(w, "-- Excerpt of %s, passage %d\n", change.NewPhelps, change.Passage)
+		}
+		fmt.Fprintf(w, "%s;\n\n", changeSQL(change, changeGuardSQL(change)))
+	}
+	return nil
+}
+
+// SaveChangeset writes the changes proposed by a dry run to changeset_<timestamp>.json and .sql
+func SaveChangeset() {
+	if len(proposedChanges) == 0 {
+		log.Printf("🔍 Dry run - no changes proposed")
+		return
+	}
+
+	cs := Changeset{Created: time.Now(), SessionID: currentSessionID, RunArgs: strings.Join(os.Args[1:], " "), Changes: proposedChanges}
+	base := fmt.Sprintf("changeset_%s", cs.Created.Format("20060102_150405"))
+	data, err := json.MarshalIndent(cs, "", "  ")
+	if err == nil {
+		err = os.WriteFile(base+".json", data, 0644)
+	}
+	if err == nil {
+		err = writeReviewExport(base+".sql", func(w io.Writer) error { return writeChangesetSQL(w, cs) })
+	}
+	if err != nil {
+		log.Printf("❌ Failed to save changeset: %v", err)
+		return
+	}
+	log.Printf("🔍 Dry run - %d proposed changes saved to %s.json and %s.sql", len(cs.Changes), base, base)
+	log.Printf("   Apply them later with -apply-changeset=%s.json", base)
+}
+
+// loadChangeset reads a changeset saved by a dry run
+func loadChangeset(path string) (Changeset, error) {
+	var cs Changeset
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cs, err
+	}
+	if err := json.Unmarshal(data, &cs); err != nil {
+		return cs, fmt.Errorf("%s: %w", path, err)
+	}
+	return cs, nil
+}
+
+// staleChange says why a change no longer fits the current codes, or "" when it does
+func staleChange(change ProposedChange, rows map[string]phelpsRow) string {
+	row, exists := rows[change.Version]
+	if change.Text != "" {
+		if exists {
+			return "translation already exists"
+		}
+		return ""
+	}
+	if !exists {
+		return "not found in database"
+	}
+	if row.IsVerified {
+		return "verified since the dry run"
+	}
+	if row.Phelps != change.OldPhelps {
+		return fmt.Sprintf("code changed from %q to %q since the dry run", change.OldPhelps, row.Phelps)
+	}
+	return ""
+}
+
+// protectedChange says why the protection policy now forbids a change, or "" when it
+// allows it; a reviewer may have approved the prayer's code since the dry run
+func protectedChange(change ProposedChange, rows map[string]phelpsRow) string {
+	if change.Text != "" {
+		return ""
+	}
+	reason, _ := protectionReason(rows[change.Version], change)
+	return reason
+}
+
+// doltCommit stages and commits all changes of the working set
+func doltCommit(message string) error {
+	if output, err := execDoltCommand("add", ".").CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to stage changes: %w: %s", err, string(output))
+	}
+	if output, err := execDoltCommand("commit", "-m", message).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit: %w: %s", err, string(output))
+	}
+	return nil
+}
+
+// ApplyChangesetCommand writes the changes of a dry run's changeset and commits them to
+// Dolt; changes to prayers whose code changed since are skipped as stale, and changes
+// the protection policy now forbids are skipped as protected
+func ApplyChangesetCommand(path string) error {
+	if dryRunMode {
+		return fmt.Errorf("-apply-changeset writes to the database and cannot be combined with -dry-run; read %s to preview it", strings.TrimSuffix(path, ".json")+".sql")
+	}
+	cs, err := loadChangeset(path)
+	if err != nil {
+		return fmt.Errorf("failed to read changeset: %w", err)
+	}
+	rows, err := loadPhelpsRows()
+	if err != nil {
+		return fmt.Errorf("failed to read current codes: %w", err)
+	}
+	markReviewedRows(rows)
+	log.Printf("📦 Applying %d changes proposed %s (%s)", len(cs.Changes), cs.Created.Format("2006-01-02 15:04"), cs.RunArgs)
+
+	applied, stale, protected, failed := 0, 0, 0, 0
+	for _, change := range cs.Changes {
+		if reason := staleChange(change, rows); reason != "" {
+			log.Printf("   ⏭️  %s: %s, skipping", change.Version, reason)
+			stale++
+			continue
+		}
+		if reason := protectedChange(change, rows); reason != "" {
+			log.Printf("   🔒 %s %s, skipping", change.Version, reason)
+			protected++
+			continue
+		}
+		if err := validatePassage(change.NewPhelps, change.Passage); err != nil {
+			log.Printf("   ❌ %s: %v", change.Version, err)
+			failed++
+			continue
+		}
+		if _, err := execDoltQuery(changeSQL(change, changeGuardSQL(change))); err != nil {
+			log.Printf("   ❌ Failed to apply %s -> %s: %v", change.Version, change.NewPhelps, err)
+			failed++
+			continue
+		}
+		notePassageMatch(change.Version, change.NewPhelps, change.Passage)
+		row := rows[change.Version]
+		row.Language, row.Phelps = change.Language, change.NewPhelps
+		rows[change.Version] = row
+		applied++
+	}
+
+	if applied > 0 {
+		message := fmt.Sprintf("Apply changeset %s: %d changes", path, applied)
+		if err := doltCommit(message); err != nil {
+			log.Printf("WARNING: %v", err)
+		} else {
+			log.Printf("✅ Committed to Dolt: %s", message)
+		}
+	}
+
+	log.Printf("Changeset applied:")
+	log.Printf("  - Applied: %d", applied)
+	log.Printf("  - Stale (changed since the dry run): %d", stale)
+	log.Printf("  - Protected (verified or reviewed since the dry run): %d", protected)
+	log.Printf("  - Failed: %d", failed)
+	return nil
+}
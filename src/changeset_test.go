@@ -0,0 +1,136 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test that a dry run proposes changes against the codes earlier proposals left
+func TestProposeChange(t *testing.T) {
+	dryRunMode = true
+	proposedChanges = nil
+	phelpsRows = map[string]phelpsRow{
+		"es-1": {Language: "es", Phelps: ""},
+		"es-2": {Language: "es", Phelps: "AB00002TWO"},
+		"es-3": {Language: "es", Phelps: "TMP00007"},
+		"es-4": {Language: "es", Phelps: "", IsVerified: true},
+		"es-5": {Language: "es", Phelps: "AB00003THR", Reviewed: true},
+	}
+	defer func() { dryRunMode, proposedChanges, phelpsRows = false, nil, nil }()
+
+	tests := []struct {
+		name          string
+		change        ProposedChange
+		onlyUnmatched bool
+		expected      bool
+	}{
+		{"unmatched prayer", ProposedChange{Version: "es-1", NewPhelps: "BH00001ONE", Confidence: 98, MatchType: "EXACT"}, true, true},
+		{"now matched by the proposal", ProposedChange{Version: "es-1", NewPhelps: "AB00003THR"}, true, false},
+		{"guarded write to a matched prayer", ProposedChange{Version: "es-2", NewPhelps: "BH00001ONE"}, true, false},
+		{"same code", ProposedChange{Version: "es-2", NewPhelps: "AB00002TWO"}, false, false},
+		{"TMP code replaced", ProposedChange{Version: "es-3", NewPhelps: "BH00001ONE"}, false, true},
+		{"real code kept", ProposedChange{Version: "es-2", NewPhelps: "BH00001ONE"}, false, false},
+		{"verified prayer", ProposedChange{Version: "es-4", NewPhelps: "BH00001ONE"}, false, false},
+		{"reviewed prayer", ProposedChange{Version: "es-5", NewPhelps: "BH00001ONE"}, false, false},
+		{"reviewer replaces real code", ProposedChange{Version: "es-5", NewPhelps: "AB00002TWO", Source: reviewChangeSource}, false, true},
+		{"new translation", ProposedChange{Version: "es_llm_AB00003THR", Language: "es", NewPhelps: "AB00003THR", Text: "Oh Dios"}, false, true},
+		{"existing translation", ProposedChange{Version: "es_llm_AB00003THR", Language: "es", NewPhelps: "AB00003THR", Text: "Oh Dios"}, false, false},
+	}
+	for _, tt := range tests {
+		got, err := writePhelps(tt.change, tt.onlyUnmatched)
+		if err != nil || got != tt.expected {
+			t.Errorf("%s: expected %v, got %v (%v)", tt.name, tt.expected, got, err)
+		}
+	}
+	if _, err := writePhelps(ProposedChange{Version: "missing", NewPhelps: "BH00001ONE"}, false); err == nil {
+		t.Errorf("Expected an error for a prayer that is not in the database")
+	}
+
+	if len(proposedChanges) != 4 {
+		t.Fatalf("Expected 4 proposed changes, got %+v", proposedChanges)
+	}
+	if c := proposedChanges[0]; c.OldPhelps != "" || c.Language != "es" || c.Confidence != 98 {
+		t.Errorf("Unexpected first change %+v", c)
+	}
+	if c := proposedChanges[1]; c.OldPhelps != "TMP00007" || c.NewPhelps != "BH00001ONE" {
+		t.Errorf("Expected the old code to be recorded: %+v", c)
+	}
+
+	if err := ApplyChangesetCommand("changeset_20260101_120000.json"); err == nil || !strings.Contains(err.Error(), "changeset_20260101_120000.sql") {
+		t.Errorf("Expected -apply-changeset to refuse a dry run, got %v", err)
+	}
+	if err := ServeReviewCommand("localhost:0"); err == nil || !strings.Contains(err.Error(), "-dry-run") {
+		t.Errorf("Expected -serve-review to refuse a dry run, got %v", err)
+	}
+
+	// New TMP codes continue after the highest proposed one
+	proposedChanges = append(proposedChanges, ProposedChange{Version: "en-9", NewPhelps: "TMP00012"})
+	if got := highestProposedTMP(); got != 12 {
+		t.Errorf("Expected highest proposed TMP 12, got %d", got)
+	}
+}
+
+// Test the SQL of changes and the staleness check of -apply-changeset
+func TestChangesetSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   ProposedChange
+		guard    string
+		expected string
+	}{
+		{"set code", ProposedChange{Version: "es-1", Language: "es", NewPhelps: "BH00001ONE"}, "",
+			"UPDATE writings SET phelps = 'BH00001ONE' WHERE version = 'es-1' AND language = 'es'"},
+		{"clear code", ProposedChange{Version: "it's"}, "",
+			"UPDATE writings SET phelps = NULL WHERE version = 'it''s'"},
+		{"unmatched only", ProposedChange{Version: "es-1", Language: "es", NewPhelps: "BH00001ONE"}, unchangedSQL(ProposedChange{}),
+			"UPDATE writings SET phelps = 'BH00001ONE' WHERE version = 'es-1' AND language = 'es' AND (phelps IS NULL OR TRIM(phelps) = '')"},
+		{"replace code", ProposedChange{Version: "es-3", NewPhelps: "BH00001ONE"}, unchangedSQL(ProposedChange{OldPhelps: "TMP00007"}),
+			"UPDATE writings SET phelps = 'BH00001ONE' WHERE version = 'es-3' AND phelps = 'TMP00007'"},
+	}
+	for _, tt := range tests {
+		if got := changeSQL(tt.change, tt.guard); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+	insert := changeSQL(ProposedChange{Version: "es_llm_X", Language: "es", NewPhelps: "X", Text: "l'amour"}, "")
+	if !strings.Contains(insert, "'l''amour'") || !strings.Contains(insert, "WHERE NOT EXISTS (SELECT 1 FROM writings WHERE version = 'es_llm_X')") {
+		t.Errorf("Unexpected insert %s", insert)
+	}
+
+	rows := map[string]phelpsRow{"es-1": {Language: "es"}, "es-2": {Language: "es", Phelps: "AB00002TWO"}, "es-3": {Language: "es", IsVerified: true}}
+	stale := []struct {
+		change ProposedChange
+		stale  bool
+	}{
+		{ProposedChange{Version: "es-1", NewPhelps: "BH00001ONE"}, false},
+		{ProposedChange{Version: "es-2", OldPhelps: "AB00002TWO", NewPhelps: "BH00001ONE"}, false},
+		{ProposedChange{Version: "es-2", NewPhelps: "BH00001ONE"}, true},
+		{ProposedChange{Version: "es-9", NewPhelps: "BH00001ONE"}, true},
+		{ProposedChange{Version: "es-3", NewPhelps: "BH00001ONE"}, true},
+		{ProposedChange{Version: "es-1", NewPhelps: "BH00001ONE", Text: "text"}, true},
+	}
+	for _, tt := range stale {
+		if got := staleChange(tt.change, rows) != ""; got != tt.stale {
+			t.Errorf("%s -> %s: expected stale %v", tt.change.Version, tt.change.NewPhelps, tt.stale)
+		}
+	}
+
+	// A reviewer's approval after the dry run is not overwritten
+	rows["es-1"] = phelpsRow{Language: "es", Reviewed: true}
+	if protectedChange(ProposedChange{Version: "es-1", NewPhelps: "BH00001ONE"}, rows) == "" {
+		t.Errorf("Expected a change to a reviewed prayer to be protected")
+	}
+	if protectedChange(ProposedChange{Version: "es-1", NewPhelps: "BH00001ONE", Source: reviewChangeSource}, rows) != "" {
+		t.Errorf("Expected a reviewer's own change to be allowed")
+	}
+
+	var sql strings.Builder
+	writeChangesetSQL(&sql, Changeset{RunArgs: "-ultra -dry-run", Changes: []ProposedChange{
+		{Version: "es-2", Language: "es", OldPhelps: "AB00002TWO", NewPhelps: "BH00001ONE", Passage: 2, Confidence: 91, MatchType: "LIKELY", Reasons: []string{"longest_words_match"}, Source: "ultra"},
+	}})
+	for _, expected := range []string{"-- ultra, ES: es-2 AB00002TWO -> BH00001ONE", "-- Reasons: longest_words_match", "-- Excerpt of BH00001ONE, passage 2", "AND phelps = 'AB00002TWO' AND (is_verified IS NULL OR is_verified = false);"} {
+		if !strings.Contains(sql.String(), expected) {
+			t.Errorf("Expected %q in changeset SQL:\n%s", expected, sql.String())
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Traditional to Simplified conversion tables in OpenCC dictionary format: one
+// "<traditional>\t<simplified> [alternatives...]" entry per line. Only the first
+// candidate is used. Phrases take priority over single characters by longest match,
+// which resolves context-dependent characters such as 乾, 著 and 藉. The tables come
+// from OpenCC (Apache-2.0, see opencc/LICENSE and opencc/NOTICE); go generate
+// replaces them with the full dictionaries of the pinned release. Characters that
+// OpenCC does not map fall back to ICU's Hant-Hans transform (see icu/NOTICE).
+
+//go:generate curl -sSfL -o opencc/TSCharacters.txt https://raw.githubusercontent.com/BYVoid/OpenCC/ver.1.1.9/data/dictionary/TSCharacters.txt
+//go:generate curl -sSfL -o opencc/TSPhrases.txt https://raw.githubusercontent.com/BYVoid/OpenCC/ver.1.1.9/data/dictionary/TSPhrases.txt
+
+//go:embed opencc/TSCharacters.txt
+var tsCharactersData string
+
+//go:embed opencc/TSPhrases.txt
+var tsPhrasesData string
+
+//go:embed icu/HantHansCharacters.txt
+var hantHansCharactersData string
+
+var (
+	tsConversionOnce sync.Once
+	tsCharacters     map[rune]rune
+	tsPhrases        map[string]string
+	tsMaxPhraseLen   int
+)
+
+// chinesePunctuationVariants folds Traditional-style corner brackets and
+// small-form punctuation onto the forms used in Simplified texts
+var chinesePunctuationVariants = map[rune]rune{
+	'「': '“', '」': '”', '『': '‘', '』': '’',
+	'﹐': '，', '﹑': '、', '﹔': '；', '﹕': '：', '﹖': '？', '﹗': '！',
+	'︰': '：', '﹝': '〔', '﹞': '〕',
+}
+
+// parseOpenCCDictionary parses OpenCC dictionary text into a source -> first target map
+func parseOpenCCDictionary(data string) map[string]string {
+	entries := make(map[string]string)
+	for _, line := range strings.Split(data, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		targets := strings.Fields(parts[1])
+		if len(targets) == 0 {
+			continue
+		}
+		entries[parts[0]] = targets[0]
+	}
+	return entries
+}
+
+// parseCharacterTable keeps the single character entries of a dictionary
+func parseCharacterTable(data string) map[rune]rune {
+	characters := make(map[rune]rune)
+	for trad, simp := range parseOpenCCDictionary(data) {
+		tradRunes, simpRunes := []rune(trad), []rune(simp)
+		if len(tradRunes) == 1 && len(simpRunes) == 1 {
+			characters[tradRunes[0]] = simpRunes[0]
+		}
+	}
+	return characters
+}
+
+// loadTSConversion parses the embedded tables once
+func loadTSConversion() {
+	tsConversionOnce.Do(func() {
+		tsCharacters = parseCharacterTable(tsCharactersData)
+
+		phraseChars := make(map[rune]bool)
+		tsPhrases = parseOpenCCDictionary(tsPhrasesData)
+		for phrase := range tsPhrases {
+			runes := []rune(phrase)
+			if len(runes) > tsMaxPhraseLen {
+				tsMaxPhraseLen = len(runes)
+			}
+			for _, r := range runes {
+				phraseChars[r] = true
+			}
+		}
+
+		// The ICU table only fills characters OpenCC leaves out; characters that
+		// appear in a phrase are context-dependent and left to the phrases
+		for trad, simp := range parseCharacterTable(hantHansCharactersData) {
+			if _, ok := tsCharacters[trad]; !ok && !phraseChars[trad] {
+				tsCharacters[trad] = simp
+			}
+		}
+	})
+}
+
+// convertTraditionalToSimplified converts text using longest phrase match first,
+// then single character mappings; non-Han text passes through unchanged
+func convertTraditionalToSimplified(text string) string {
+	loadTSConversion()
+
+	runes := []rune(text)
+	var result strings.Builder
+	result.Grow(len(text))
+
+	for i := 0; i < len(runes); {
+		if unicode.Is(unicode.Han, runes[i]) {
+			matched := false
+			maxLen := tsMaxPhraseLen
+			if remaining := len(runes) - i; remaining < maxLen {
+				maxLen = remaining
+			}
+			for length := maxLen; length >= 2; length-- {
+				if simplified, ok := tsPhrases[string(runes[i:i+length])]; ok {
+					result.WriteString(simplified)
+					i += length
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+		}
+
+		r := runes[i]
+		if simplified, ok := tsCharacters[r]; ok {
+			r = simplified
+		} else if folded, ok := chinesePunctuationVariants[r]; ok {
+			r = folded
+		}
+		result.WriteRune(r)
+		i++
+	}
+
+	return result.String()
+}
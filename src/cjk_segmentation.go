@@ -0,0 +1,366 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Script classes used to split Japanese text into kanji/kana runs
+const (
+	cjkOther = iota
+	cjkHan
+	cjkHiragana
+	cjkKatakana
+	cjkHangul
+	cjkAlnum
+)
+
+// cjkTheologicalChars is the rare kanji/hanja table used to rank rare_characters
+// for Japanese and Korean prayers; both shinjitai and traditional forms are listed.
+// Characters common in everyday words (全, 能, 使, 言, 信, 王, ...) are left out.
+var cjkTheologicalChars = map[rune]string{
+	'神': "god", '主': "lord", '天': "heaven", '聖': "holy", '霊': "spirit",
+	'靈': "spirit", '祈': "pray", '祷': "prayer", '禱': "prayer", '恩': "grace",
+	'恵': "bounty", '惠': "bounty", '慈': "mercy", '悲': "compassion", '愛': "love",
+	'光': "light", '栄': "glory", '榮': "glory", '輝': "splendor", '福': "blessing",
+	'祝': "bless", '赦': "forgive", '救': "salvation", '贖': "redemption", '護': "protect",
+	'導': "guide", '癒': "heal", '契': "covenant", '約': "covenant", '啓': "revelation",
+	'啟': "revelation", '僕': "servant", '婢': "handmaiden", '讃': "praise", '賛': "praise",
+	'讚': "praise", '崇': "exalted", '威': "majesty", '尊': "honor", '永': "eternal",
+	'創': "creator", '憐': "pity", '寛': "forbearing", '寬': "forbearing", '魂': "soul",
+	'徒': "believer", '預': "prophet", '顕': "manifest", '顯': "manifest", '帝': "sovereign",
+}
+
+// japaneseParticles are grammatical kana runs and very common words skipped when
+// picking distinctive words
+var japaneseParticles = map[string]bool{
+	"は": true, "が": true, "を": true, "に": true, "の": true, "で": true, "と": true,
+	"へ": true, "も": true, "や": true, "か": true, "よ": true, "ね": true, "て": true,
+	"から": true, "まで": true, "より": true, "して": true, "した": true, "する": true,
+	"です": true, "ます": true, "なる": true, "ある": true, "いる": true, "この": true,
+	"その": true, "あなた": true,
+}
+
+// koreanJosa are Korean case particles stripped from the end of an eojeol,
+// longest first so "으로" wins over "로"; a one-syllable particle is only stripped
+// when two syllables remain, so nouns like 기도 and 국가 stay whole
+var koreanJosa = []string{
+	"에게서", "께서는", "이시여", "으로써", "으로서",
+	"에게", "께서", "으로", "에서", "이여", "부터", "까지", "처럼", "보다",
+	"은", "는", "이", "가", "을", "를", "에", "의", "와", "과", "도", "로", "여", "께", "만",
+}
+
+// koreanJosaNouns end in a syllable that is also a one-syllable particle; an eojeol
+// ending in one of them keeps that syllable
+var koreanJosaNouns = []string{
+	"그리스도", "바하이", "사도", "성도", "신도", "전도", "제도", "지도", "태도", "정도",
+	"성가", "찬가", "국가", "누가", "정의", "회의", "예의", "결과", "효과",
+}
+
+// cjkKeyTerms maps Japanese and Korean theological terms to English glosses
+// and transliterations, used both as key terms and phonetic terms
+var cjkKeyTerms = map[string][]string{
+	// Japanese
+	"神":       {"god"},
+	"主":       {"lord"},
+	"バハオラ":    {"bahaullah"},
+	"アブドル・バハ": {"abdul-baha"},
+	"バブ":      {"bab"},
+	"祈り":      {"prayer"},
+	"栄光":      {"glory"},
+	"慈悲":      {"mercy"},
+	"恩恵":      {"grace"},
+	"王国":      {"kingdom"},
+	"聖約":      {"covenant"},
+	"聖霊":      {"holy-spirit"},
+	"赦し":      {"forgiveness"},
+	"守護":      {"protection"},
+	"導き":      {"guidance"},
+	"癒し":      {"healing"},
+	"賛美":      {"praise"},
+	// Korean
+	"하나님":  {"god"},
+	"하느님":  {"god"},
+	"주님":   {"lord"},
+	"바하올라": {"bahaullah"},
+	"압둘바하": {"abdul-baha"},
+	"기도":   {"prayer"},
+	"영광":   {"glory"},
+	"자비":   {"mercy"},
+	"은총":   {"grace"},
+	"은혜":   {"grace"},
+	"왕국":   {"kingdom"},
+	"성약":   {"covenant"},
+	"용서":   {"forgiveness"},
+	"보호":   {"protection"},
+	"인도":   {"guidance"},
+	"치유":   {"healing"},
+	"찬양":   {"praise"},
+	"축복":   {"blessing"},
+}
+
+// japaneseInvocations, koreanInvocations etc. mirror hasChineseInvocation and friends
+var (
+	japaneseInvocations   = []string{"おお神よ", "おお、神よ", "神よ", "主よ", "わが神", "わが主"}
+	japaneseBlessings     = []string{"祝福", "恵み", "恩恵"}
+	japaneseSupplications = []string{"ください", "給え", "たまえ", "願い", "求め"}
+	koreanInvocations     = []string{"오 하나님", "하나님이시여", "하나님이여", "주여", "오 주님", "나의 하나님"}
+	koreanBlessings       = []string{"축복", "은총", "은혜"}
+	koreanSupplications   = []string{"주소서", "주시옵소서", "하소서", "간구", "기원"}
+)
+
+// isJapanese checks if a language is Japanese
+func isJapanese(language string) bool {
+	return language == "ja"
+}
+
+// isKorean checks if a language is Korean
+func isKorean(language string) bool {
+	return language == "ko"
+}
+
+// isJapaneseOrKorean checks if a language needs CJK segmentation other than Chinese
+func isJapaneseOrKorean(language string) bool {
+	return isJapanese(language) || isKorean(language)
+}
+
+// isHangulSyllable checks if a rune is a precomposed Hangul syllable block
+func isHangulSyllable(r rune) bool {
+	return r >= 0xAC00 && r <= 0xD7A3
+}
+
+// cjkClass returns the script class of a rune for run segmentation
+func cjkClass(r rune) int {
+	switch {
+	case unicode.Is(unicode.Han, r) || r == '々':
+		return cjkHan
+	case r >= 0x3041 && r <= 0x309F:
+		return cjkHiragana
+	case (r >= 0x30A0 && r <= 0x30FF) || (r >= 0x31F0 && r <= 0x31FF):
+		// Includes the prolonged sound mark and the middle dot used in names
+		return cjkKatakana
+	case isHangulSyllable(r) || (r >= 0x1100 && r <= 0x11FF) || (r >= 0x3130 && r <= 0x318F):
+		return cjkHangul
+	case unicode.IsLetter(r) || unicode.IsDigit(r):
+		return cjkAlnum
+	default:
+		return cjkOther
+	}
+}
+
+// segmentJapanese splits Japanese text into kanji, hiragana and katakana runs
+func segmentJapanese(text string) []string {
+	var tokens []string
+	var current []rune
+	currentClass := cjkOther
+
+	flush := func() {
+		if len(current) > 0 {
+			tokens = append(tokens, string(current))
+			current = current[:0]
+		}
+	}
+
+	for _, r := range text {
+		class := cjkClass(r)
+		if class == cjkOther {
+			flush()
+			currentClass = cjkOther
+			continue
+		}
+		if class != currentClass {
+			flush()
+			currentClass = class
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	return tokens
+}
+
+// stripKoreanJosa removes a trailing case particle from an eojeol
+func stripKoreanJosa(eojeol string) string {
+	syllables := len([]rune(eojeol))
+	protected := false
+	for _, noun := range koreanJosaNouns {
+		if strings.HasSuffix(eojeol, noun) {
+			protected = true
+			break
+		}
+	}
+	for _, josa := range koreanJosa {
+		length := len([]rune(josa))
+		minStem := 1
+		if length == 1 {
+			if protected {
+				continue
+			}
+			minStem = 2
+		}
+		if strings.HasSuffix(eojeol, josa) && syllables-length >= minStem {
+			return strings.TrimSuffix(eojeol, josa)
+		}
+	}
+	return eojeol
+}
+
+// segmentKorean splits Korean text into eojeol (space-delimited units) with
+// punctuation trimmed and case particles stripped
+func segmentKorean(text string) []string {
+	var tokens []string
+	for _, field := range strings.Fields(text) {
+		clean := strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if clean == "" {
+			continue
+		}
+		tokens = append(tokens, stripKoreanJosa(clean))
+	}
+	return tokens
+}
+
+// segmentCJKWords returns the word-like units for Japanese or Korean text
+func segmentCJKWords(text, language string) []string {
+	if isKorean(language) {
+		return segmentKorean(text)
+	}
+	return segmentJapanese(text)
+}
+
+// cjkContentTokens filters out particles and single kana so only distinctive units remain
+func cjkContentTokens(tokens []string) []string {
+	var result []string
+	for _, token := range tokens {
+		runes := []rune(token)
+		if japaneseParticles[token] {
+			continue
+		}
+		if len(runes) == 1 && cjkClass(runes[0]) != cjkHan {
+			continue
+		}
+		result = append(result, token)
+	}
+	return result
+}
+
+// extractCJKLongestWords finds the longest segmented units (by character count)
+func extractCJKLongestWords(tokens []string) []string {
+	content := cjkContentTokens(tokens)
+
+	// Stable sort keeps first occurrence order for equal lengths
+	sorted := make([]string, len(content))
+	copy(sorted, content)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len([]rune(sorted[i])) > len([]rune(sorted[j]))
+	})
+
+	seen := make(map[string]bool)
+	var result []string
+	for _, token := range sorted {
+		if len([]rune(token)) < 2 || seen[token] {
+			continue
+		}
+		seen[token] = true
+		result = append(result, token)
+		if len(result) >= 5 {
+			break
+		}
+	}
+
+	return result
+}
+
+// extractCJKUniqueSequences finds distinctive three-unit sequences
+func extractCJKUniqueSequences(tokens []string, language string) []string {
+	separator := ""
+	if isKorean(language) {
+		separator = " "
+	}
+
+	sequences := make(map[string]bool)
+	for i := 0; i <= len(tokens)-3; i++ {
+		seq := strings.Join(tokens[i:i+3], separator)
+		if len([]rune(seq)) >= 6 {
+			sequences[seq] = true
+		}
+	}
+
+	var result []string
+	for seq := range sequences {
+		result = append(result, seq)
+	}
+	sort.Strings(result)
+
+	if len(result) > 10 {
+		result = result[:10]
+	}
+	return result
+}
+
+// findCJKKeyTerms identifies Japanese and Korean theological terms
+func findCJKKeyTerms(text string) []string {
+	var terms []string
+	for term := range cjkKeyTerms {
+		if strings.Contains(text, term) {
+			terms = append(terms, term)
+		}
+	}
+	sort.Strings(terms)
+
+	if len(terms) > 15 {
+		return terms[:15]
+	}
+	return terms
+}
+
+// extractCJKPhoneticTerms maps Japanese and Korean key terms to shared glosses
+func extractCJKPhoneticTerms(keyTerms []string) []string {
+	seen := make(map[string]bool)
+	var phonetic []string
+	for _, term := range keyTerms {
+		for _, p := range cjkKeyTerms[term] {
+			if !seen[p] {
+				seen[p] = true
+				phonetic = append(phonetic, p)
+			}
+		}
+	}
+	return phonetic
+}
+
+// containsAnyPattern checks text for any of the given patterns
+func containsAnyPattern(text string, patterns []string) bool {
+	for _, p := range patterns {
+		if strings.Contains(text, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCJKInvocation detects Japanese and Korean invocations
+func hasCJKInvocation(text, language string) bool {
+	if isKorean(language) {
+		return containsAnyPattern(text, koreanInvocations)
+	}
+	return containsAnyPattern(text, japaneseInvocations)
+}
+
+// hasCJKBlessings detects Japanese and Korean blessing patterns
+func hasCJKBlessings(text, language string) bool {
+	if isKorean(language) {
+		return containsAnyPattern(text, koreanBlessings)
+	}
+	return containsAnyPattern(text, japaneseBlessings)
+}
+
+// hasCJKSupplication detects Japanese and Korean supplication patterns
+func hasCJKSupplication(text, language string) bool {
+	if isKorean(language) {
+		return containsAnyPattern(text, koreanSupplications)
+	}
+	return containsAnyPattern(text, japaneseSupplications)
+}
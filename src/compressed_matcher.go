@@ -22,18 +22,6 @@ var chineseTheologicalChars = map[rune]bool{
 	'巴': true, '哈': true, // Bahá (specific to Bahá'í prayers)
 }
 
-// Traditional to Simplified Chinese character mappings for cross-script matching
-var traditionalToSimplified = map[rune]rune{
-	'經': '经', '學': '学', '國': '国', '門': '门', '來': '来', '時': '时', '無': '无', '長': '长',
-	'會': '会', '現': '现', '開': '开', '關': '关', '東': '东', '車': '车', '見': '见', '說': '说',
-	'語': '语', '電': '电', '話': '话', '網': '网', '間': '间', '問': '问', '題': '题', '業': '业',
-	'個': '个', '們': '们', '對': '对', '應': '应', '還': '还', '這': '这', '樣': '样', '點': '点',
-	'實': '实', '種': '种', '類': '类', '處': '处', '進': '进', '運': '运', '動': '动', '過': '过',
-	'發': '发', '場': '场', '機': '机', '聖': '圣', '靈': '灵', '書': '书', '義': '义', '愛': '爱',
-	'賜': '赐', '禱': '祷', '讚': '赞', '頌': '颂', '榮': '荣', '耀': '耀', '僕': '仆', '團': '团',
-	'轉': '转', '顯': '显', '徵': '征', '啟': '启', '潛': '潜', '於': '于',
-}
-
 // PrayerFingerprint contains compressed semantic information about a prayer
 type PrayerFingerprint struct {
 	Phelps   string `json:"phelps,omitempty"`  // English reference only
@@ -41,10 +29,12 @@ type PrayerFingerprint struct {
 	Language string `json:"language"`
 
 	// PRIMARY MATCHING (Language-agnostic)
-	TextHash      string `json:"text_hash"`      // MD5 of normalized text - MOST RELIABLE for cross-language
-	WordCount     int    `json:"word_count"`     // Prayer length indicator
-	CharCount     int    `json:"char_count"`     // Text size indicator
-	StructureHash string `json:"structure_hash"` // Hash of prayer structure (paragraphs, verses)
+	TextHash      string   `json:"text_hash"`      // MD5 of normalized text - MOST RELIABLE for cross-language
+	NearHash      string   `json:"-"`              // Hash with diacritics/punctuation folded, used for local pre-matching
+	MinHash       []uint64 `json:"-"`              // Shingle MinHash signature for near-duplicate detection
+	WordCount     int      `json:"word_count"`     // Prayer length indicator
+	CharCount     int      `json:"char_count"`     // Text size indicator
+	StructureHash string   `json:"structure_hash"` // Hash of prayer structure (paragraphs, verses)
 
 	// SECONDARY MATCHING (Language-aware)
 	OpeningPhrase  string   `json:"opening"`   // First 8-12 words (null for non-Latin scripts)
@@ -102,6 +92,7 @@ type CompressedMatchResult struct {
 	Confidence      float64  `json:"confidence"`
 	MatchReasons    []string `json:"match_reasons"`
 	AmbiguityReason string   `json:"ambiguity_reason,omitempty"`
+	Backend         string   `json:"-"` // backend whose response proposed the match
 }
 
 // CompressedBatchResponse is the LLM response for bulk matching
@@ -112,6 +103,7 @@ type CompressedBatchResponse struct {
 	AmbiguousCount  int                     `json:"ambiguous_count"`
 	NewTranslations int                     `json:"new_translations"`
 	Summary         string                  `json:"summary"`
+	Notes           []LLMNote               `json:"notes,omitempty"`
 }
 
 // Theological terms that are important for matching across languages
@@ -137,13 +129,14 @@ var prayerTypeKeywords = map[string][]string{
 	"gratitude":   {"thank", "grateful", "gratitude", "thankful", "appreciation"},
 }
 
-// CreatePrayerFingerprint generates a compressed semantic fingerprint of a prayer
-func CreatePrayerFingerprint(phelps, version, language, name, text string) PrayerFingerprint {
-	// For Chinese, apply variant normalization before general normalization
-	processedText := text
-	if isChinese(language) {
-		processedText = normalizeChineseVariants(text)
-	}
+// CreatePrayerFingerprint generates a compressed semantic fingerprint of a prayer from the
+// given source
+func CreatePrayerFingerprint(phelps, version, language, name, text, source string) PrayerFingerprint {
+	// Strip footnotes, headings and publication notes so they don't pollute the fingerprint
+	text, _ = CleanPrayerText(text, source)
+
+	// Apply script-specific variant normalization before general normalization
+	processedText := preprocessScriptText(text, language)
 
 	normalized := normalizeText(processedText)
 
@@ -157,6 +150,11 @@ func CreatePrayerFingerprint(phelps, version, language, name, text string) Praye
 		charCount = len([]rune(normalized))
 		// For Chinese, we'll handle word extraction differently
 		words = []string{} // Empty for Chinese processing
+	} else if isJapaneseOrKorean(language) {
+		// Kanji/kana runs for Japanese, particle-stripped eojeol for Korean
+		words = segmentCJKWords(normalized, language)
+		wordCount = len(words)
+		charCount = len([]rune(normalized))
 	} else {
 		words = strings.Fields(normalized)
 		wordCount = len(words)
@@ -171,14 +169,16 @@ func CreatePrayerFingerprint(phelps, version, language, name, text string) Praye
 		WordCount: wordCount,
 		CharCount: charCount,
 		TextHash:  textHash(normalized),
+		NearHash:  nearTextHash(text, language),
+		MinHash:   minHashSignature(text, language),
 	}
 
 	// Edge case detection
 	fingerprint.IsShortPrayer = len(words) < 50
 	fingerprint.IsRareLanguage = isRareLanguage(language)
 
-	// Extract opening phrase (Chinese-aware)
-	if isChinese(language) {
+	// Extract opening phrase (Chinese-aware, Japanese is also unspaced)
+	if isChinese(language) || isJapanese(language) {
 		fingerprint.OpeningPhrase = extractChineseOpeningPhrase(normalized)
 		fingerprint.ClosingPhrase = extractChineseClosingPhrase(normalized)
 	} else {
@@ -186,8 +186,14 @@ func CreatePrayerFingerprint(phelps, version, language, name, text string) Praye
 		fingerprint.ClosingPhrase = extractClosingPhrase(words)
 	}
 
-	// Find key theological terms
-	fingerprint.KeyTerms = findKeyTerms(normalized)
+	// Find key theological terms (Arabic-script aware)
+	if isArabicScript(language) {
+		fingerprint.KeyTerms = findArabicKeyTerms(normalized)
+	} else if isJapaneseOrKorean(language) {
+		fingerprint.KeyTerms = findCJKKeyTerms(normalized)
+	} else {
+		fingerprint.KeyTerms = findKeyTerms(normalized)
+	}
 
 	// Advanced matching features (Chinese-aware)
 	if isChinese(language) {
@@ -195,6 +201,15 @@ func CreatePrayerFingerprint(phelps, version, language, name, text string) Praye
 		fingerprint.RareCharacters = extractChineseRareCharacters(text, language)
 		fingerprint.RecurringPhrases = findChineseRecurringPhrases(normalized)
 		fingerprint.UniqueSequences = extractChineseSignatureSequences(normalized)
+	} else if isJapaneseOrKorean(language) {
+		fingerprint.LongestWords = extractCJKLongestWords(words)
+		fingerprint.RareCharacters = extractRareCharacters(text, language)
+		if isJapanese(language) {
+			fingerprint.RecurringPhrases = findChineseRecurringPhrases(normalized)
+		} else {
+			fingerprint.RecurringPhrases = findRecurringPhrases(normalized)
+		}
+		fingerprint.UniqueSequences = extractCJKUniqueSequences(words, language)
 	} else {
 		fingerprint.LongestWords = extractLongestWords(words)
 		fingerprint.RareCharacters = extractRareCharacters(text, language)
@@ -207,6 +222,14 @@ func CreatePrayerFingerprint(phelps, version, language, name, text string) Praye
 		fingerprint.HasInvocation = hasChineseInvocation(normalized)
 		fingerprint.HasBlessings = hasChineseBlessings(normalized)
 		fingerprint.HasSupplication = hasChineseSupplication(normalized)
+	} else if isArabicScript(language) {
+		fingerprint.HasInvocation = hasArabicInvocation(normalized)
+		fingerprint.HasBlessings = hasArabicBlessings(normalized)
+		fingerprint.HasSupplication = hasArabicSupplication(normalized)
+	} else if isJapaneseOrKorean(language) {
+		fingerprint.HasInvocation = hasCJKInvocation(normalized, language)
+		fingerprint.HasBlessings = hasCJKBlessings(normalized, language)
+		fingerprint.HasSupplication = hasCJKSupplication(normalized, language)
 	} else {
 		fingerprint.HasInvocation = hasInvocation(normalized)
 		fingerprint.HasBlessings = hasBlessings(normalized)
@@ -221,12 +244,18 @@ func CreatePrayerFingerprint(phelps, version, language, name, text string) Praye
 	// Determine prayer type (Chinese-aware)
 	if isChinese(language) {
 		fingerprint.PrayerType = determineChinesePrayerType(normalized)
+	} else if isArabicScript(language) {
+		fingerprint.PrayerType = determineArabicPrayerType(normalized)
 	} else {
 		fingerprint.PrayerType = determinePrayerType(normalized)
 	}
 
 	// Extract signature words (most distinctive terms)
-	fingerprint.SignatureWords = extractSignatureWords(words, fingerprint.KeyTerms)
+	if isJapaneseOrKorean(language) {
+		fingerprint.SignatureWords = extractSignatureWords(cjkContentTokens(words), fingerprint.KeyTerms)
+	} else {
+		fingerprint.SignatureWords = extractSignatureWords(words, fingerprint.KeyTerms)
+	}
 
 	// Add phonetic terms for cross-language matching
 	fingerprint.PhoneticTerms = extractPhoneticTerms(fingerprint.KeyTerms, language)
@@ -239,6 +268,18 @@ func CreatePrayerFingerprint(phelps, version, language, name, text string) Praye
 	return fingerprint
 }
 
+// preprocessScriptText folds script variants (Traditional Chinese, Arabic-script
+// letter forms) so the same text always normalizes and hashes identically
+func preprocessScriptText(text, language string) string {
+	if isChinese(language) {
+		return normalizeChineseVariants(text)
+	}
+	if isArabicScript(language) {
+		return normalizeArabicScript(text)
+	}
+	return text
+}
+
 // isRareLanguage checks if a language has very few total prayers
 func isRareLanguage(language string) bool {
 	// This could be enhanced to query the actual database
@@ -285,49 +326,59 @@ func extractLongestWords(words []string) []string {
 	return result
 }
 
-// extractRareCharacters finds uncommon characters for CJK languages
+// extractRareCharacters finds uncommon characters for CJK languages. Only
+// kanji/hanja (and Hangul syllables for Korean) are considered, since kana are
+// too frequent to be distinctive; theological characters are ranked first.
 func extractRareCharacters(text, language string) []string {
-	if language != "zh-Hans" && language != "zh-Hant" && language != "ja" && language != "ko" {
+	if !isChinese(language) && !isJapaneseOrKorean(language) {
 		return nil // Only applicable for CJK languages
 	}
 
 	// Common characters that appear in many texts (less distinctive)
 	commonChars := map[rune]bool{
 		'的': true, '了': true, '在': true, '是': true, '我': true, '有': true, '和': true,
-		'を': true, 'に': true, 'は': true, 'が': true, 'の': true, 'で': true, 'と': true,
+		'私': true, '汝': true, '者': true, '事': true, '人': true,
 		'이': true, '가': true, '을': true, '는': true, '에': true, '와': true, '의': true,
+		'하': true, '고': true, '다': true, '서': true, '로': true, '지': true, '리': true,
 	}
 
 	charCount := make(map[rune]int)
 	for _, r := range text {
-		if !commonChars[r] && (r >= 0x4E00 && r <= 0x9FFF) || // CJK Unified Ideographs
-			(r >= 0x3040 && r <= 0x309F) || // Hiragana
-			(r >= 0x30A0 && r <= 0x30FF) || // Katakana
-			(r >= 0xAC00 && r <= 0xD7AF) { // Hangul
+		if commonChars[r] {
+			continue
+		}
+		if unicode.Is(unicode.Han, r) || (isKorean(language) && isHangulSyllable(r)) {
 			charCount[r]++
 		}
 	}
 
-	// Sort by frequency (rarer chars first)
-	type charFreq struct {
-		char rune
-		freq int
+	// Score by rarity, boosting theological characters
+	type charScore struct {
+		char  rune
+		score float64
 	}
-	var chars []charFreq
-	for char, freq := range charCount {
-		chars = append(chars, charFreq{char, freq})
+	var scored []charScore
+	for char, count := range charCount {
+		score := 1.0 / float64(count)
+		if _, ok := cjkTheologicalChars[char]; ok {
+			score *= 3.0
+		}
+		scored = append(scored, charScore{char, score})
 	}
-	sort.Slice(chars, func(i, j int) bool {
-		return chars[i].freq < chars[j].freq
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].char < scored[j].char
 	})
 
 	// Return up to 10 rarest characters
 	var result []string
-	for i, cf := range chars {
+	for i, cs := range scored {
 		if i >= 10 {
 			break
 		}
-		result = append(result, string(cf.char))
+		result = append(result, string(cs.char))
 	}
 
 	return result
@@ -465,6 +516,14 @@ func generateStructureHash(text string) string {
 
 // extractPhoneticTerms creates transliterated versions for cross-language matching
 func extractPhoneticTerms(keyTerms []string, language string) []string {
+	// Arabic-script terms are transliterated from their own table
+	if isArabicScript(language) {
+		return extractArabicPhoneticTerms(keyTerms)
+	}
+	if isJapaneseOrKorean(language) {
+		return extractCJKPhoneticTerms(keyTerms)
+	}
+
 	// This is a simplified version - could be enhanced with proper transliteration
 	var phonetic []string
 
@@ -506,25 +565,14 @@ func normalizeChineseVariants(text string) string {
 		return text
 	}
 
-	runes := []rune(text)
-	for i, r := range runes {
-		if simplified, exists := traditionalToSimplified[r]; exists {
-			runes[i] = simplified
-		}
-	}
-	return string(runes)
+	return convertTraditionalToSimplified(text)
 }
 
-// normalizeText creates MD5 hash of normalized text for exact matching
+// textHash creates MD5 hash of normalized text for exact matching; Chinese text is
+// expected in Simplified form already (see preprocessScriptText)
 func textHash(text string) string {
-	// For Chinese, normalize Traditional/Simplified variants first
-	normalized := text
-	if strings.ContainsAny(text, "經學國門來時無長會現開關東車見說語電話網間問題業個們對應還這樣點") {
-		normalized = normalizeChineseVariants(text)
-	}
-
 	// Remove all punctuation and spacing for hash
-	clean := regexp.MustCompile(`[^\p{L}\p{N}]`).ReplaceAllString(normalized, "")
+	clean := regexp.MustCompile(`[^\p{L}\p{N}]`).ReplaceAllString(text, "")
 	hash := md5.Sum([]byte(clean))
 	return hex.EncodeToString(hash[:])[:12] // First 12 chars
 }
@@ -791,9 +839,10 @@ func CreateCompressedMatchingPrompt(englishFingerprints, targetFingerprints []Pr
 	prompt.WriteString("5. **FALLBACK: NEW_TRANSLATION** - When no reliable match exists\n\n")
 	prompt.WriteString("⚠️  **Cross-Language/Cross-Script Guidance:**\n")
 	prompt.WriteString("- **Chinese (zh-Hans/zh-Hant)**: Character-based analysis - prioritize rare_characters (theological chars: 神,主,天,圣,灵,祈,祷,恩,慈,巴,哈), longest_words (character sequences), recurring_phrases (repeated character patterns)\n")
-	prompt.WriteString("- **Chinese Traditional/Simplified**: Text normalized to Simplified for cross-script matching - same prayer in both scripts should have similar text_hash\n")
-	prompt.WriteString("- **CJK languages (Japanese/Korean)**: Use rare_characters, longest_words, structure_hash\n")
-	prompt.WriteString("- **Arabic script**: Use longest_words, phonetic_terms, recurring_phrases\n")
+	prompt.WriteString("- **Chinese Traditional/Simplified**: Text converted to Simplified (full character and phrase table) - the same prayer in both scripts has an identical text_hash\n")
+	prompt.WriteString("- **CJK languages (Japanese/Korean)**: Japanese is segmented into kanji/kana runs and Korean into particle-stripped eojeol - use rare_characters (theological kanji/hanja ranked first), longest_words, key_terms, phonetic_terms, structure_hash\n")
+	prompt.WriteString("- **Arabic script (ar/fa/ur/ps)**: Text is diacritic-folded with unified alef/yeh/kaf forms - compare key_terms and phonetic_terms (transliterated: allah, baha, abha, rabb) across ar/fa/ur/ps and against English terms, plus longest_words and recurring_phrases\n")
+	prompt.WriteString("- **Long Tablets (phelps ending in §n)**: Long references are also given as numbered passages - if a target is an excerpt of one passage, return the passage code (e.g. AB00001FIR§2) instead of the whole-Tablet code\n")
 	prompt.WriteString("- **Short prayers (is_short_prayer=true)**: Use full_text if available\n")
 	prompt.WriteString("- **Rare languages (is_rare_language=true)**: Use full_text and all available features\n")
 	prompt.WriteString("- **Chinese word_count**: Estimated from character count (~1.7 chars per word)\n")
@@ -819,6 +868,11 @@ func CreateCompressedMatchingPrompt(englishFingerprints, targetFingerprints []Pr
 		prompt.WriteString("- Match confidence: VERY HIGH (95%+) - same prayer, different script\n\n")
 	}
 
+	if notes := formatNotesForPrompt(getRelevantNotes(targetLang)); notes != "" {
+		prompt.WriteString("# NOTES FROM EARLIER SESSIONS\n")
+		prompt.WriteString(notes)
+	}
+
 	prompt.WriteString("# OUTPUT FORMAT\n")
 	prompt.WriteString("```json\n")
 	prompt.WriteString(`{
@@ -836,7 +890,10 @@ func CreateCompressedMatchingPrompt(englishFingerprints, targetFingerprints []Pr
   "likely_matches": 67,
   "ambiguous_count": 12,
   "new_translations": 31,
-  "summary": "Processed 155 prayers: 45 exact, 67 likely, 12 need review, 31 new translations needed"
+  "summary": "Processed 155 prayers: 45 exact, 67 likely, 12 need review, 31 new translations needed",
+  "notes": [
+    {"language": "fa", "phelps": "BH00002SHO", "type": "PATTERN", "content": "short obligatory prayer often confused with the medium one"}
+  ]
 }`)
 	prompt.WriteString("\n```\n\n")
 
@@ -853,7 +910,8 @@ func CreateCompressedMatchingPrompt(englishFingerprints, targetFingerprints []Pr
 	prompt.WriteString("7. **Rare languages: Weight all available features more heavily**\n")
 	prompt.WriteString("8. **Always include specific match_reasons** (e.g., 'longest_words_match', 'rare_characters_match')\n")
 	prompt.WriteString("9. **Never explain your process - just return the JSON**\n")
-	prompt.WriteString("10. **Never generate code - only JSON results**\n\n")
+	prompt.WriteString("10. **Never generate code - only JSON results**\n")
+	prompt.WriteString("11. **notes are optional** - add one only for a lesson later sessions should know (type: SUCCESS, FAILURE, PATTERN, STRATEGY or TIP)\n\n")
 
 	prompt.WriteString("🚨 **CRITICAL: Return ONLY the JSON response. No explanations, no code, no additional text.**\n\n")
 
@@ -873,34 +931,38 @@ func ProcessCompressedResults(results CompressedBatchResponse, targetLang string
 	log.Printf("  - New translations: %d", results.NewTranslations)
 
 	for _, match := range results.Matches {
+		// Passage codes (<Phelps>§n) store the Tablet code plus an excerpt note
+		phelps, passage := splitPassageCode(match.EnglishPhelps)
+
 		switch match.MatchType {
 		case "EXACT":
-			// High confidence updates
-			if match.Confidence >= 95 {
-				query := fmt.Sprintf("UPDATE writings SET phelps = '%s' WHERE version = '%s' AND language = '%s'",
-					strings.ReplaceAll(match.EnglishPhelps, "'", "''"),
-					strings.ReplaceAll(match.TargetVersion, "'", "''"),
-					targetLang)
-
-				if _, err := execDoltQuery(query); err != nil {
+			// Updates precise enough to skip review
+			if shouldAutoApply(match.Backend, match.MatchType, match.Confidence, match.MatchReasons) {
+				change := ProposedChange{Version: match.TargetVersion, Language: targetLang, NewPhelps: phelps, Passage: passage,
+					Confidence: match.Confidence, MatchType: match.MatchType, Reasons: match.MatchReasons, Source: "compressed"}
+				written, err := writePhelps(change, false)
+				if err != nil {
 					log.Printf("ERROR updating %s: %v", match.TargetVersion, err)
 					continue
 				}
+				if !written {
+					continue
+				}
 				exactCount++
 			}
 
 		case "LIKELY":
-			// Medium confidence updates
-			if match.Confidence >= 80 {
-				query := fmt.Sprintf("UPDATE writings SET phelps = '%s' WHERE version = '%s' AND language = '%s'",
-					strings.ReplaceAll(match.EnglishPhelps, "'", "''"),
-					strings.ReplaceAll(match.TargetVersion, "'", "''"),
-					targetLang)
-
-				if _, err := execDoltQuery(query); err != nil {
+			if shouldAutoApply(match.Backend, match.MatchType, match.Confidence, match.MatchReasons) {
+				change := ProposedChange{Version: match.TargetVersion, Language: targetLang, NewPhelps: phelps, Passage: passage,
+					Confidence: match.Confidence, MatchType: match.MatchType, Reasons: match.MatchReasons, Source: "compressed"}
+				written, err := writePhelps(change, false)
+				if err != nil {
 					log.Printf("ERROR updating %s: %v", match.TargetVersion, err)
 					continue
 				}
+				if !written {
+					continue
+				}
 				likelyCount++
 			}
 
@@ -919,99 +981,131 @@ func ProcessCompressedResults(results CompressedBatchResponse, targetLang string
 	return exactCount, likelyCount, ambiguousCount, nil
 }
 
-// CompressedLanguageMatchingWithTMPFallback performs matching with en -> ar -> fa -> TMP fallback
-func CompressedLanguageMatchingWithTMPFallback(targetLang string) error {
-	log.Printf("Starting compressed matching with TMP fallback for language: %s", targetLang)
+// failedResponsePrefix names the unparseable responses proposeCompressedMatches saves;
+// -retry repairs and applies files with the default prefix
+var failedResponsePrefix = "failed_response_"
 
-	// Load database
-	db, err := GetDatabase()
-	if err != nil {
-		return fmt.Errorf("failed to load database: %w", err)
-	}
-
-	// Get three-tier references (English, Arabic, Persian)
-	englishRefs, arabicRefs, persianRefs := BuildReferencesWithTMP(db)
+// proposeCompressedMatches runs the compressed pipeline for the given targets up to the
+// point of writing: text-hash matches found locally come first in the result (their
+// number is returned), followed by the LLM proposals after rejection, verification and
+// evaluation. With tmpFallback the prompt offers en, ar and fa references and TMP codes.
+func proposeCompressedMatches(db Database, targetLang string, targetPrayers []TargetPrayer, tmpFallback bool) (CompressedBatchResponse, int, error) {
+	var results CompressedBatchResponse
 
-	// Get target language prayers
-	targetPrayers := BuildTargetPrayers(db, targetLang)
+	// Create fingerprints for the references
+	var englishRefs, arabicRefs, persianRefs []EnglishReference
+	if tmpFallback {
+		englishRefs, arabicRefs, persianRefs = BuildReferencesWithTMP(db)
+		log.Printf("Loaded %d English, %d Arabic, %d Persian refs, %d %s prayers",
+			len(englishRefs), len(arabicRefs), len(persianRefs), len(targetPrayers), targetLang)
+	} else {
+		englishRefs = BuildEnglishReference(db)
+		log.Printf("Loaded %d English refs, %d %s prayers",
+			len(englishRefs), len(targetPrayers), targetLang)
+	}
 
-	log.Printf("Loaded %d English, %d Arabic, %d Persian refs, %d %s prayers",
-		len(englishRefs), len(arabicRefs), len(persianRefs), len(targetPrayers), targetLang)
+	// Arabic and Persian Tablets are only split into passages when there is no
+	// English text, so every § code is numbered by the text it is verified against
+	passageLangs := passageLanguages(db)
 
-	// Create fingerprints for all three tiers
 	var englishFingerprints []PrayerFingerprint
 	for _, ref := range englishRefs {
-		fp := CreatePrayerFingerprint(ref.Phelps, "", "en", ref.Name, ref.Text)
-		englishFingerprints = append(englishFingerprints, fp)
+		englishFingerprints = append(englishFingerprints, referenceFingerprints(ref, "en", passageLangs)...)
 	}
 
 	var arabicFingerprints []PrayerFingerprint
 	for _, ref := range arabicRefs {
-		fp := CreatePrayerFingerprint(ref.Phelps, "", "ar", ref.Name, ref.Text)
-		arabicFingerprints = append(arabicFingerprints, fp)
+		arabicFingerprints = append(arabicFingerprints, referenceFingerprints(ref, "ar", passageLangs)...)
 	}
 
 	var persianFingerprints []PrayerFingerprint
 	for _, ref := range persianRefs {
-		fp := CreatePrayerFingerprint(ref.Phelps, "", "fa", ref.Name, ref.Text)
-		persianFingerprints = append(persianFingerprints, fp)
+		persianFingerprints = append(persianFingerprints, referenceFingerprints(ref, "fa", passageLangs)...)
 	}
 
 	var targetFingerprints []PrayerFingerprint
 	for _, prayer := range targetPrayers {
-		fp := CreatePrayerFingerprint("", prayer.Version, targetLang, prayer.Name, prayer.Text)
+		fp := CreatePrayerFingerprint("", prayer.Version, targetLang, prayer.Name, prayer.Text, prayer.Source)
 		targetFingerprints = append(targetFingerprints, fp)
 	}
 
-	log.Printf("Created fingerprints: %d en + %d ar + %d fa + %d target",
-		len(englishFingerprints), len(arabicFingerprints), len(persianFingerprints), len(targetFingerprints))
-
-	// Create three-tier fallback prompt
-	prompt := CreateFallbackMatchingPrompt(
-		englishFingerprints,
-		arabicFingerprints,
-		persianFingerprints,
-		targetFingerprints,
-		targetLang,
-	)
+	if tmpFallback {
+		log.Printf("Created fingerprints: %d en + %d ar + %d fa + %d target",
+			len(englishFingerprints), len(arabicFingerprints), len(persianFingerprints), len(targetFingerprints))
+	} else {
+		log.Printf("Created %d English + %d target fingerprints",
+			len(englishFingerprints), len(targetFingerprints))
+	}
+
+	// Resolve duplicated texts (including TMP-coded ones) locally before building the prompt
+	localMatches, targetFingerprints := prematchLanguageTargets(BuildHashIndex(db), targetFingerprints, targetLang)
+	if len(targetFingerprints) == 0 {
+		log.Printf("✅ All %s prayers resolved by text hash, skipping LLM call", targetLang)
+		mergeLocalMatches(&results, localMatches)
+		return results, len(localMatches), nil
+	}
+
+	context := "compressed bulk matching"
+	var prompt string
+	if tmpFallback {
+		context = "TMP fallback matching"
+		prompt = CreateFallbackMatchingPrompt(
+			englishFingerprints,
+			arabicFingerprints,
+			persianFingerprints,
+			targetFingerprints,
+			targetLang,
+		)
+	} else {
+		prompt = CreateCompressedMatchingPrompt(englishFingerprints, targetFingerprints, targetLang, "bulk_match")
+	}
 
-	log.Printf("Calling LLM for three-tier fallback matching...")
-	response, err := callLLMWithBackendFallback(prompt, "TMP fallback matching", true)
+	log.Printf("Calling LLM for %s...", context)
+	response, err := callLLMWithBackendFallback(prompt, context, true)
 	if err != nil {
-		return fmt.Errorf("LLM call failed for TMP fallback matching: %w", err)
+		return results, 0, fmt.Errorf("LLM call failed for %s: %w", context, err)
 	}
+	backend := lastLLMBackend
 
 	// Parse response using robust JSON extraction
 	jsonStr, err := ExtractJSONFromResponse(response)
 	if err != nil {
-		failedResponseFile := fmt.Sprintf("failed_response_%s_%d.txt", targetLang, time.Now().Unix())
+		// Save failed response for later analysis
+		failedResponseFile := fmt.Sprintf("%s%s_%d.txt", failedResponsePrefix,
+			targetLang,
+			time.Now().Unix())
 		if writeErr := os.WriteFile(failedResponseFile, []byte(response), 0644); writeErr == nil {
 			log.Printf("❌ JSON extraction failed, saved response to: %s", failedResponseFile)
 		}
-		return fmt.Errorf("failed to extract JSON from response (saved to %s): %w", failedResponseFile, err)
+		return results, 0, fmt.Errorf("failed to extract JSON from response (saved to %s): %w", failedResponseFile, err)
 	}
 
-	var results CompressedBatchResponse
 	if err := json.Unmarshal([]byte(jsonStr), &results); err != nil {
-		failedResponseFile := fmt.Sprintf("failed_response_%s_%d.txt", targetLang, time.Now().Unix())
+		// Save failed response for later analysis
+		failedResponseFile := fmt.Sprintf("%s%s_%d.txt", failedResponsePrefix,
+			targetLang,
+			time.Now().Unix())
 		if writeErr := os.WriteFile(failedResponseFile, []byte(response), 0644); writeErr == nil {
 			log.Printf("❌ Parse failed, saved response to: %s", failedResponseFile)
 		}
-		return fmt.Errorf("failed to parse response (saved to %s): %w", failedResponseFile, err)
+		return results, 0, fmt.Errorf("failed to parse response (saved to %s): %w", failedResponseFile, err)
 	}
 
-	// Process results using TMP-aware processing
-	if err := ApplyTMPMatches(targetLang, results); err != nil {
-		return fmt.Errorf("failed to apply TMP matches: %w", err)
+	for i := range results.Matches {
+		results.Matches[i].Backend = backend
 	}
 
-	log.Printf("✅ TMP fallback matching completed for %s", targetLang)
-	return nil
+	// Verify LLM proposals against the full texts before anything is written
+	DropRejectedMatches(&results, loadRejectedPairs())
+	VerifyProposedMatches(db, &results)
+	EvaluateCompressedMatches(db, &results)
+	mergeLocalMatches(&results, localMatches)
+	return results, len(localMatches), nil
 }
 
-// CompressedLanguageMatching performs efficient bulk matching for a language
-func CompressedLanguageMatching(targetLang string) error {
-	log.Printf("Starting compressed matching for language: %s", targetLang)
+// CompressedLanguageMatchingWithTMPFallback performs matching with en -> ar -> fa -> TMP fallback
+func CompressedLanguageMatchingWithTMPFallback(targetLang string) error {
+	log.Printf("Starting compressed matching with TMP fallback for language: %s", targetLang)
 
 	// Load database
 	db, err := GetDatabase()
@@ -1019,66 +1113,37 @@ func CompressedLanguageMatching(targetLang string) error {
 		return fmt.Errorf("failed to load database: %w", err)
 	}
 
-	// Get English references
-	englishRefs := BuildEnglishReference(db)
-
-	// Get target language prayers
-	targetPrayers := BuildTargetPrayers(db, targetLang)
-
-	log.Printf("Loaded %d English refs, %d %s prayers",
-		len(englishRefs), len(targetPrayers), targetLang)
-
-	// Create fingerprints
-	var englishFingerprints []PrayerFingerprint
-	for _, ref := range englishRefs {
-		fp := CreatePrayerFingerprint(ref.Phelps, "", "en", ref.Name, ref.Text)
-		englishFingerprints = append(englishFingerprints, fp)
+	results, _, err := proposeCompressedMatches(db, targetLang, BuildTargetPrayers(db, targetLang), true)
+	if err != nil {
+		return err
 	}
 
-	var targetFingerprints []PrayerFingerprint
-	for _, prayer := range targetPrayers {
-		fp := CreatePrayerFingerprint("", prayer.Version, targetLang, prayer.Name, prayer.Text)
-		targetFingerprints = append(targetFingerprints, fp)
+	// Process using TMP-aware processing
+	recordLLMNotes(results.Notes, targetLang)
+	if err := ApplyTMPMatches(targetLang, results); err != nil {
+		return fmt.Errorf("failed to apply TMP matches: %w", err)
 	}
 
-	log.Printf("Created %d English + %d target fingerprints",
-		len(englishFingerprints), len(targetFingerprints))
+	log.Printf("✅ TMP fallback matching completed for %s", targetLang)
+	return nil
+}
 
-	// Create bulk matching prompt
-	prompt := CreateCompressedMatchingPrompt(englishFingerprints, targetFingerprints, targetLang, "bulk_match")
+// CompressedLanguageMatching performs efficient bulk matching for a language
+func CompressedLanguageMatching(targetLang string) error {
+	log.Printf("Starting compressed matching for language: %s", targetLang)
 
-	log.Printf("Calling LLM for compressed bulk matching...")
-	response, err := callLLMWithBackendFallback(prompt, "compressed bulk matching", true)
+	// Load database
+	db, err := GetDatabase()
 	if err != nil {
-		return fmt.Errorf("LLM call failed for compressed bulk matching: %w", err)
+		return fmt.Errorf("failed to load database: %w", err)
 	}
 
-	// Parse response using robust JSON extraction
-	jsonStr, err := ExtractJSONFromResponse(response)
+	results, _, err := proposeCompressedMatches(db, targetLang, BuildTargetPrayers(db, targetLang), false)
 	if err != nil {
-		// Save failed response for later analysis
-		failedResponseFile := fmt.Sprintf("failed_response_%s_%d.txt",
-			targetLang,
-			time.Now().Unix())
-		if writeErr := os.WriteFile(failedResponseFile, []byte(response), 0644); writeErr == nil {
-			log.Printf("❌ JSON extraction failed, saved response to: %s", failedResponseFile)
-		}
-		return fmt.Errorf("failed to extract JSON from response (saved to %s): %w", failedResponseFile, err)
+		return err
 	}
 
-	var results CompressedBatchResponse
-	if err := json.Unmarshal([]byte(jsonStr), &results); err != nil {
-		// Save failed response for later analysis
-		failedResponseFile := fmt.Sprintf("failed_response_%s_%d.txt",
-			targetLang,
-			time.Now().Unix())
-		if writeErr := os.WriteFile(failedResponseFile, []byte(response), 0644); writeErr == nil {
-			log.Printf("❌ Parse failed, saved response to: %s", failedResponseFile)
-		}
-		return fmt.Errorf("failed to parse response (saved to %s): %w", failedResponseFile, err)
-	}
-
-	// Process results
+	recordLLMNotes(results.Notes, targetLang)
 	exactCount, likelyCount, ambiguousCount, err := ProcessCompressedResults(results, targetLang)
 	if err != nil {
 		return fmt.Errorf("failed to process results: %w", err)
@@ -1092,9 +1157,14 @@ func CompressedLanguageMatching(targetLang string) error {
 	return nil
 }
 
-// isChinese checks if the language is Chinese (Traditional or Simplified)
+// isChinese checks if the language is Chinese (zh and its variants, or Cantonese)
 func isChinese(language string) bool {
-	return language == "zh-Hans" || language == "zh-Hant"
+	for _, code := range []string{"zh", "yue"} {
+		if language == code || strings.HasPrefix(language, code+"-") {
+			return true
+		}
+	}
+	return false
 }
 
 // getChineseWordCount estimates word count for Chinese text
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The coverage subcommand shows which prayers are missing where: one row per English
+// reference or TMP code, one column per language.
+//
+//	prayer-matcher coverage -format=html -output=coverage.html
+//	prayer-matcher coverage -format=csv -language=es,fr,de
+
+// Coverage cell states
+const (
+	CoverageMatched  = "matched"
+	CoverageMultiple = "multiple" // more than one version of the language has the code
+	CoverageMissing  = "missing"
+	CoverageLLM      = "llm" // only an LLM translation
+)
+
+const llmTranslationSource = "LLM_TRANSLATION"
+
+// CoverageRow is one prayer and its state in every language column
+type CoverageRow struct {
+	Phelps     string
+	Name       string
+	TMP        bool
+	Cells      []string
+	Translated int // languages with a matched version
+}
+
+// CoverageMatrix is the Phelps codes × languages table
+type CoverageMatrix struct {
+	Languages []string
+	Rows      []CoverageRow
+	Unmatched map[string]int // language -> versions without a code
+}
+
+// UnlinkedPrayer is a widely translated prayer still missing in languages that have
+// unmatched versions, where linking effort is most likely to pay off
+type UnlinkedPrayer struct {
+	Phelps     string
+	Name       string
+	Translated int
+	Missing    []string
+}
+
+// BuildCoverageMatrix tabulates codes of English references and TMP codes against languages
+func BuildCoverageMatrix(db Database, languages map[string]bool) CoverageMatrix {
+	matrix := CoverageMatrix{Unmatched: make(map[string]int)}
+	include := func(language string) bool {
+		return len(languages) == 0 || languages[language]
+	}
+
+	names := make(map[string]string)
+	isRow := make(map[string]bool)
+	versions := make(map[string]int)
+	for _, w := range db.Writing {
+		if w.Phelps != "" && (w.Language == "en" || strings.HasPrefix(w.Phelps, TMP_CODE_PREFIX)) {
+			isRow[w.Phelps] = true
+			if names[w.Phelps] == "" || w.Language == "en" {
+				names[w.Phelps] = w.Name
+			}
+		}
+		if include(w.Language) {
+			versions[w.Language]++
+			if w.Phelps == "" {
+				matrix.Unmatched[w.Language]++
+			}
+		}
+	}
+
+	for language := range versions {
+		matrix.Languages = append(matrix.Languages, language)
+	}
+	sort.Slice(matrix.Languages, func(i, j int) bool {
+		a, b := matrix.Languages[i], matrix.Languages[j]
+		if versions[a] != versions[b] {
+			return versions[a] > versions[b]
+		}
+		return a < b
+	})
+	column := make(map[string]int)
+	for i, language := range matrix.Languages {
+		column[language] = i
+	}
+
+	human := make(map[string][]int)
+	llm := make(map[string][]bool)
+	for code := range isRow {
+		human[code] = make([]int, len(matrix.Languages))
+		llm[code] = make([]bool, len(matrix.Languages))
+	}
+	for _, w := range db.Writing {
+		col, ok := column[w.Language]
+		if !ok || !isRow[w.Phelps] {
+			continue
+		}
+		if w.Source == llmTranslationSource {
+			llm[w.Phelps][col] = true
+		} else {
+			human[w.Phelps][col]++
+		}
+	}
+
+	for code := range isRow {
+		row := CoverageRow{Phelps: code, Name: names[code], TMP: strings.HasPrefix(code, TMP_CODE_PREFIX), Cells: make([]string, len(matrix.Languages))}
+		for col := range matrix.Languages {
+			switch count := human[code][col]; {
+			case count == 1:
+				row.Cells[col] = CoverageMatched
+			case count > 1:
+				row.Cells[col] = CoverageMultiple
+			case llm[code][col]:
+				row.Cells[col] = CoverageLLM
+			default:
+				row.Cells[col] = CoverageMissing
+			}
+			if human[code][col] > 0 {
+				row.Translated++
+			}
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+	sort.Slice(matrix.Rows, func(i, j int) bool {
+		if matrix.Rows[i].Translated != matrix.Rows[j].Translated {
+			return matrix.Rows[i].Translated > matrix.Rows[j].Translated
+		}
+		return matrix.Rows[i].Phelps < matrix.Rows[j].Phelps
+	})
+
+	return matrix
+}
+
+// MostTranslatedUnlinked returns the n most translated prayers that are missing in
+// languages which still have unmatched versions
+func (m CoverageMatrix) MostTranslatedUnlinked(n int) []UnlinkedPrayer {
+	var unlinked []UnlinkedPrayer
+	for _, row := range m.Rows {
+		prayer := UnlinkedPrayer{Phelps: row.Phelps, Name: row.Name, Translated: row.Translated}
+		for col, state := range row.Cells {
+			language := m.Languages[col]
+			if (state == CoverageMissing || state == CoverageLLM) && m.Unmatched[language] > 0 {
+				prayer.Missing = append(prayer.Missing, language)
+			}
+		}
+		if len(prayer.Missing) > 0 {
+			unlinked = append(unlinked, prayer)
+		}
+		if len(unlinked) == n {
+			break
+		}
+	}
+	return unlinked
+}
+
+// coverageSymbols are the compact cell renderings used in HTML and the terminal
+var coverageSymbols = map[string]string{
+	CoverageMatched:  "✓",
+	CoverageMultiple: "2+",
+	CoverageMissing:  "·",
+	CoverageLLM:      "L",
+}
+
+// writeCoverageCSV writes the matrix with one column per language
+func writeCoverageCSV(w io.Writer, matrix CoverageMatrix) error {
+	writer := csv.NewWriter(w)
+	writer.Write(append([]string{"phelps", "name", "translated"}, matrix.Languages...))
+	for _, row := range matrix.Rows {
+		writer.Write(append([]string{row.Phelps, row.Name, strconv.Itoa(row.Translated)}, row.Cells...))
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeUnlinkedCSV writes the most translated but unlinked prayers
+func writeUnlinkedCSV(w io.Writer, unlinked []UnlinkedPrayer) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"phelps", "name", "translated", "missing_count", "missing_languages"})
+	for _, p := range unlinked {
+		writer.Write([]string{p.Phelps, p.Name, strconv.Itoa(p.Translated), strconv.Itoa(len(p.Missing)), strings.Join(p.Missing, " ")})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+var coverageTemplate = template.Must(template.New("coverage").Funcs(template.FuncMap{
+	"symbol": func(state string) string { return coverageSymbols[state] },
+	"join":   strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Prayer coverage</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+table { border-collapse: collapse; font-size: 12px; }
+td, th { border: 1px solid #ddd; padding: 1px 4px; text-align: center; }
+th.lang { writing-mode: vertical-rl; }
+td.name, th.name { text-align: left; white-space: nowrap; max-width: 24em; overflow: hidden; }
+.matched { background: #9d9; } .multiple { background: #fc6; } .missing { background: #fff; color: #bbb; } .llm { background: #9cf; }
+.tmp td.name { font-style: italic; }
+</style>
+</head>
+<body>
+<h1>Prayer coverage</h1>
+<p>{{len .Matrix.Rows}} prayers × {{len .Matrix.Languages}} languages.
+<span class="matched">✓ matched</span> <span class="multiple">2+ multiple versions</span> <span class="llm">L LLM translation only</span> <span class="missing">· missing</span></p>
+
+<h2>Most translated but unlinked</h2>
+<table>
+<tr><th class="name">Prayer</th><th>Translated</th><th class="name">Missing in languages with unmatched prayers</th></tr>
+{{range .Unlinked}}<tr><td class="name">{{.Phelps}} {{.Name}}</td><td>{{.Translated}}</td><td class="name">{{join .Missing " "}}</td></tr>
+{{end}}</table>
+
+<h2>Matrix</h2>
+<table>
+<tr><th class="name">Prayer</th><th>#</th>{{range .Matrix.Languages}}<th class="lang">{{.}}</th>{{end}}</tr>
+{{range .Matrix.Rows}}<tr{{if .TMP}} class="tmp"{{end}}><td class="name" title="{{.Name}}">{{.Phelps}} {{.Name}}</td><td>{{.Translated}}</td>{{range .Cells}}<td class="{{.}}">{{symbol .}}</td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// CoverageCommand writes the coverage matrix as CSV or HTML and logs the prayers to link first
+func CoverageCommand(args []string) error {
+	fs := flag.NewFlagSet("coverage", flag.ContinueOnError)
+	format := fs.String("format", "html", "Output format: html, csv")
+	output := fs.String("output", "", "Output file, - for stdout (default coverage.<format>)")
+	languages := fs.String("language", "", "Only include these languages (comma-separated, e.g. es,fr)")
+	top := fs.Int("top", 50, "Most translated but unlinked prayers to list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	*format = strings.ToLower(*format)
+	if *format != "html" && *format != "csv" {
+		return fmt.Errorf("unknown format %q (use html, csv)", *format)
+	}
+	if *output == "" {
+		*output = "coverage." + *format
+	}
+	filter := make(map[string]bool)
+	for _, language := range strings.Split(*languages, ",") {
+		if language = strings.TrimSpace(language); language != "" {
+			filter[language] = true
+		}
+	}
+
+	db, err := GetDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load database: %w", err)
+	}
+	matrix := BuildCoverageMatrix(db, filter)
+	unlinked := matrix.MostTranslatedUnlinked(*top)
+
+	err = writeReviewExport(*output, func(w io.Writer) error {
+		if *format == "csv" {
+			return writeCoverageCSV(w, matrix)
+		}
+		return coverageTemplate.Execute(w, map[string]interface{}{"Matrix": matrix, "Unlinked": unlinked})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	if *format == "csv" && *output != "-" {
+		unlinkedFile := strings.TrimSuffix(*output, ".csv") + "_unlinked.csv"
+		if err := writeReviewExport(unlinkedFile, func(w io.Writer) error { return writeUnlinkedCSV(w, unlinked) }); err != nil {
+			return fmt.Errorf("failed to write %s: %w", unlinkedFile, err)
+		}
+	}
+
+	log.Printf("📊 Coverage: %d prayers × %d languages", len(matrix.Rows), len(matrix.Languages))
+	log.Printf("🎯 Most translated but unlinked:")
+	for i, p := range unlinked {
+		if i == 10 {
+			log.Printf("   ... %d more in %s", len(unlinked)-10, *output)
+			break
+		}
+		log.Printf("   %s %-40s translated in %d, missing in %d: %s", p.Phelps, truncateRunes(p.Name, 40), p.Translated, len(p.Missing), truncateRunes(strings.Join(p.Missing, " "), 60))
+	}
+	if *output != "-" {
+		log.Printf("✅ Coverage matrix written to %s", *output)
+	}
+	return nil
+}
+
+// truncateRunes shortens s to n runes for log columns
+func truncateRunes(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Test the coverage matrix cell states and the most translated but unlinked prayers
+func TestBuildCoverageMatrix(t *testing.T) {
+	db := Database{
+		Writing: []Writing{
+			{Version: "en-1", Language: "en", Phelps: "BH00001ONE", Name: "Guidance"},
+			{Version: "en-2", Language: "en", Phelps: "AB00002TWO", Name: "Healing"},
+			{Version: "es-1", Language: "es", Phelps: "BH00001ONE"},
+			{Version: "es-2", Language: "es", Phelps: "BH00001ONE"},
+			{Version: "es-3", Language: "es"},
+			{Version: "fr-1", Language: "fr", Phelps: "BH00001ONE"},
+			{Version: "fr_llm_AB00002TWO", Language: "fr", Phelps: "AB00002TWO", Source: llmTranslationSource},
+			{Version: "ar-1", Language: "ar", Phelps: "TMP00001", Name: "Munajat"},
+			{Version: "de-1", Language: "de", Phelps: "XX99999"}, // not an English reference
+		},
+	}
+
+	matrix := BuildCoverageMatrix(db, nil)
+	if len(matrix.Rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %+v", matrix.Rows)
+	}
+	cell := func(code, language string) string {
+		for _, row := range matrix.Rows {
+			if row.Phelps != code {
+				continue
+			}
+			for i, l := range matrix.Languages {
+				if l == language {
+					return row.Cells[i]
+				}
+			}
+		}
+		return ""
+	}
+
+	tests := []struct {
+		code, language, expected string
+	}{
+		{"BH00001ONE", "en", CoverageMatched},
+		{"BH00001ONE", "es", CoverageMultiple},
+		{"BH00001ONE", "de", CoverageMissing},
+		{"AB00002TWO", "fr", CoverageLLM},
+		{"AB00002TWO", "es", CoverageMissing},
+		{"TMP00001", "ar", CoverageMatched},
+	}
+	for _, tt := range tests {
+		if got := cell(tt.code, tt.language); got != tt.expected {
+			t.Errorf("%s in %s: expected %s, got %s", tt.code, tt.language, tt.expected, got)
+		}
+	}
+	if matrix.Rows[0].Phelps != "BH00001ONE" || matrix.Rows[0].Translated != 3 || !matrix.Rows[2].TMP || matrix.Rows[2].Name != "Munajat" {
+		t.Errorf("Unexpected row order or counts: %+v", matrix.Rows)
+	}
+
+	// Only es has unmatched prayers, so only es gaps are worth linking
+	unlinked := matrix.MostTranslatedUnlinked(10)
+	if len(unlinked) != 2 || unlinked[0].Phelps != "AB00002TWO" || strings.Join(unlinked[0].Missing, ",") != "es" || unlinked[1].Phelps != "TMP00001" {
+		t.Errorf("Unexpected unlinked prayers %+v", unlinked)
+	}
+
+	filtered := BuildCoverageMatrix(db, map[string]bool{"es": true, "fr": true})
+	if len(filtered.Languages) != 2 || len(filtered.Rows[0].Cells) != 2 {
+		t.Errorf("Expected 2 language columns, got %v", filtered.Languages)
+	}
+
+	var csvOut, htmlOut bytes.Buffer
+	if err := writeCoverageCSV(&csvOut, filtered); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(csvOut.String(), "phelps,name,translated,es,fr\n") || !strings.Contains(csvOut.String(), "BH00001ONE,Guidance,2,multiple,matched") {
+		t.Errorf("Unexpected CSV:\n%s", csvOut.String())
+	}
+	if err := coverageTemplate.Execute(&htmlOut, map[string]interface{}{"Matrix": matrix, "Unlinked": unlinked}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(htmlOut.String(), `<td class="llm">L</td>`) {
+		t.Errorf("Expected an LLM cell in the HTML")
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Second-opinion evaluation: LIKELY matches below the EXACT threshold and every
+// AMBIGUOUS match are shown to an evaluator LLM together with the full texts of
+// both prayers before anything is written.
+const (
+	evaluationMinConfidence = 80.0 // LIKELY matches from here up to EXACT are re-checked
+	evaluationExactAt       = 95.0
+	evaluationBoost         = 0.10 // added when the evaluator confirms a match
+	evaluationPenalty       = 0.7  // factor applied when the evaluator questions a match
+)
+
+// LLMResponse is a single proposed match as seen by the evaluator (confidence 0-1)
+type LLMResponse struct {
+	PhelpsCode string
+	Confidence float64
+	Reasoning  string
+}
+
+// LLMEvaluation is the parsed verdict of the evaluator
+type LLMEvaluation struct {
+	IsValid        bool    // the evaluator proposed an improved code
+	OriginalValid  bool    // VALID: YES
+	Questioned     bool    // VALID: NO
+	ImprovedCode   string  // empty when NONE
+	ImprovedConf   float64 // 0-1
+	ImprovedReason string
+	EvalReasoning  string
+}
+
+// buildEvaluationPrompt asks the evaluator to check a proposed match against the
+// text of the prayer it was matched to
+func buildEvaluationPrompt(db Database, prayerText string, resp LLMResponse) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("You are an expert evaluator of Bahá'í prayer matches. Another model matched a prayer to a Phelps code; check whether that match is correct.\n\n")
+
+	prompt.WriteString("PRAYER TEXT TO EVALUATE:\n")
+	prompt.WriteString(prayerText)
+	prompt.WriteString("\n\n")
+
+	prompt.WriteString("PROPOSED MATCH:\n")
+	prompt.WriteString(fmt.Sprintf("Phelps code: %s\n", resp.PhelpsCode))
+	prompt.WriteString(fmt.Sprintf("Confidence: %.1f%%\n", resp.Confidence*100))
+	if resp.Reasoning != "" {
+		prompt.WriteString(fmt.Sprintf("Reasoning: %s\n", resp.Reasoning))
+	}
+	prompt.WriteString("\n")
+
+	prompt.WriteString("MATCHED PRAYER TEXT:\n")
+	if matched, ok := evaluationReferenceText(db, resp.PhelpsCode); ok {
+		prompt.WriteString(matched)
+	} else {
+		prompt.WriteString("(not found in database)")
+	}
+	prompt.WriteString("\n\n")
+
+	prompt.WriteString(`Compare the two texts paragraph by paragraph. A translation may differ in wording and length, but it must follow the same sequence of ideas, invocations and names. A prayer that only shares common phrases ("O God", "Thou art the Merciful") is NOT a match. If the prayer is an excerpt, it must correspond to the matched passage.
+
+Respond in exactly this format:
+VALID: YES or NO
+CONFIDENCE_APPROPRIATE: YES or NO
+IMPROVED_CODE: [a better Phelps code, or NONE]
+IMPROVED_CONFIDENCE: [0-100, only with an improved code]
+IMPROVED_REASONING: [why the improved code fits better, only with an improved code]
+EVALUATION: [one or two sentences explaining your verdict]`)
+
+	return prompt.String()
+}
+
+// evaluationReferenceText finds the text behind a (passage) code, preferring English,
+// then Arabic, then Persian like the verifier
+func evaluationReferenceText(db Database, code string) (string, bool) {
+	phelps, passage := splitPassageCode(code)
+
+	var found *Writing
+	for i := range db.Writing {
+		w := &db.Writing[i]
+		if w.Phelps != phelps || strings.TrimSpace(w.Text) == "" {
+			continue
+		}
+		if found == nil || referenceLanguageRank[w.Language] > referenceLanguageRank[found.Language] {
+			found = w
+		}
+	}
+	if found == nil {
+		return "", false
+	}
+
+	text := cleanWritingText(*found)
+	if passage > 0 {
+		passages := SegmentPassages(text, found.Language)
+		if passage > len(passages) {
+			return "", false
+		}
+		text = passages[passage-1]
+	}
+	return text, true
+}
+
+// parseEvaluationResponse reads the structured fields of an evaluator response
+func parseEvaluationResponse(response string) LLMEvaluation {
+	var evaluation LLMEvaluation
+
+	for _, line := range strings.Split(response, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "VALID":
+			verdict := strings.ToUpper(value)
+			evaluation.OriginalValid = strings.HasPrefix(verdict, "YES")
+			evaluation.Questioned = strings.HasPrefix(verdict, "NO")
+		case "IMPROVED_CODE":
+			if code := strings.Trim(value, "[]` "); code != "" && !strings.EqualFold(code, "NONE") {
+				evaluation.ImprovedCode = code
+			}
+		case "IMPROVED_CONFIDENCE":
+			if conf, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64); err == nil {
+				if conf > 1 {
+					conf /= 100
+				}
+				evaluation.ImprovedConf = conf
+			}
+		case "IMPROVED_REASONING":
+			evaluation.ImprovedReason = value
+		case "EVALUATION":
+			evaluation.EvalReasoning = value
+		}
+	}
+
+	evaluation.IsValid = evaluation.ImprovedCode != ""
+	return evaluation
+}
+
+// applyEvaluation combines the original match with the evaluator's verdict: a better
+// code with higher confidence replaces the match, a confirmed match gains confidence
+// and a questioned match loses it
+func applyEvaluation(original LLMResponse, evaluation LLMEvaluation) LLMResponse {
+	switch {
+	case evaluation.IsValid && evaluation.ImprovedConf > original.Confidence:
+		return LLMResponse{
+			PhelpsCode: evaluation.ImprovedCode,
+			Confidence: evaluation.ImprovedConf,
+			Reasoning: fmt.Sprintf("EVALUATED & IMPROVED: %s (originally %s at %.0f%%: %s)",
+				evaluation.ImprovedReason, original.PhelpsCode, original.Confidence*100, original.Reasoning),
+		}
+	case evaluation.OriginalValid:
+		original.Confidence = math.Min(original.Confidence+evaluationBoost, 1.0)
+		original.Reasoning = strings.TrimSpace(original.Reasoning + " [EVALUATION CONFIRMED: " + evaluation.EvalReasoning + "]")
+	case evaluation.Questioned:
+		original.Confidence *= evaluationPenalty
+		original.Reasoning = strings.TrimSpace(original.Reasoning + " [EVALUATION QUESTIONED: " + evaluation.EvalReasoning + "]")
+	}
+	return original
+}
+
+// needsEvaluation reports whether a proposed match gets a second opinion
+func needsEvaluation(phelps, matchType string, confidence float64) bool {
+	if phelps == "" || phelps == "UNKNOWN" {
+		return false
+	}
+	switch matchType {
+	case "AMBIGUOUS":
+		return true
+	case "LIKELY":
+		return confidence >= evaluationMinConfidence && confidence < evaluationExactAt
+	}
+	return false
+}
+
+// evaluatedMatchType classifies an evaluated confidence (0-100); matches that were
+// AMBIGUOUS can at most become LIKELY
+func evaluatedMatchType(previous string, confidence float64) string {
+	switch {
+	case confidence >= evaluationExactAt && previous != "AMBIGUOUS":
+		return "EXACT"
+	case confidence >= evaluationMinConfidence:
+		return "LIKELY"
+	}
+	return "AMBIGUOUS"
+}
+
+// describeEvaluation turns a verdict into the reason recorded on the match
+func describeEvaluation(evaluation LLMEvaluation, original, final LLMResponse) string {
+	switch {
+	case final.PhelpsCode != original.PhelpsCode:
+		return fmt.Sprintf("second opinion: improved from %s to %s (%.0f%%): %s",
+			original.PhelpsCode, final.PhelpsCode, final.Confidence*100, evaluation.ImprovedReason)
+	case evaluation.OriginalValid:
+		return fmt.Sprintf("second opinion: confirmed (%.0f%% -> %.0f%%): %s",
+			original.Confidence*100, final.Confidence*100, evaluation.EvalReasoning)
+	case evaluation.Questioned:
+		return fmt.Sprintf("second opinion: questioned (%.0f%% -> %.0f%%): %s",
+			original.Confidence*100, final.Confidence*100, evaluation.EvalReasoning)
+	}
+	return "second opinion: no verdict"
+}
+
+// matchEvaluator holds the texts needed to evaluate a batch of matches
+type matchEvaluator struct {
+	db      Database
+	targets map[string]Writing
+	codes   map[string]bool
+}
+
+func newMatchEvaluator(db Database) matchEvaluator {
+	evaluator := matchEvaluator{
+		db:      db,
+		targets: make(map[string]Writing),
+		codes:   make(map[string]bool),
+	}
+	for _, w := range db.Writing {
+		evaluator.targets[w.Version] = w
+		if w.Phelps != "" {
+			evaluator.codes[w.Phelps] = true
+		}
+	}
+	return evaluator
+}
+
+// evaluate asks the evaluator about one proposed match; confidence is on the 0-100 scale
+func (e matchEvaluator) evaluate(phelps, version string, confidence float64, reasons []string) (LLMResponse, string, bool) {
+	target, ok := e.targets[version]
+	if !ok {
+		return LLMResponse{}, "", false
+	}
+
+	original := LLMResponse{
+		PhelpsCode: phelps,
+		Confidence: confidence / 100,
+		Reasoning:  strings.Join(reasons, "; "),
+	}
+	prompt := buildEvaluationPrompt(e.db, cleanWritingText(target), original)
+
+	response, err := callLLMWithBackendFallback(prompt, "evaluation of "+version, false)
+	if err != nil {
+		log.Printf("⚠️ Evaluation failed for %s -> %s, keeping original match: %v", phelps, version, err)
+		return LLMResponse{}, "", false
+	}
+
+	evaluation := parseEvaluationResponse(response)
+	if improved, _ := splitPassageCode(evaluation.ImprovedCode); evaluation.IsValid && !e.codes[improved] {
+		log.Printf("   ⚠️ Evaluator suggested unknown code %s for %s, ignoring it", evaluation.ImprovedCode, version)
+		evaluation.IsValid = false
+		evaluation.ImprovedCode = ""
+	}
+
+	final := applyEvaluation(original, evaluation)
+	if final.PhelpsCode != original.PhelpsCode {
+		// Remember the confusion so later prompts can avoid it
+		addSessionNote(target.Language, "FAILURE",
+			fmt.Sprintf("%s was proposed for a prayer that is %s: %s", original.PhelpsCode, final.PhelpsCode, evaluation.ImprovedReason),
+			final.PhelpsCode, final.Confidence)
+	}
+	return final, describeEvaluation(evaluation, original, final), true
+}
+
+// moveMatchCount keeps the batch counters in line when a match changes type
+func moveMatchCount(results *CompressedBatchResponse, from, to string) {
+	if from == to {
+		return
+	}
+	counters := map[string]*int{
+		"EXACT":     &results.ExactMatches,
+		"LIKELY":    &results.LikelyMatches,
+		"AMBIGUOUS": &results.AmbiguousCount,
+	}
+	if counter, ok := counters[from]; ok {
+		*counter--
+	}
+	if counter, ok := counters[to]; ok {
+		*counter++
+	}
+}
+
+// EvaluateCompressedMatches gets a second opinion on LIKELY (80-95) and AMBIGUOUS
+// matches, adjusting confidence and match type and recording the verdict
+func EvaluateCompressedMatches(db Database, results *CompressedBatchResponse) {
+	if !useSecondOpinion {
+		return
+	}
+
+	evaluator := newMatchEvaluator(db)
+	evaluated, changed := 0, 0
+
+	for i := range results.Matches {
+		match := &results.Matches[i]
+		if !needsEvaluation(match.EnglishPhelps, match.MatchType, match.Confidence) {
+			continue
+		}
+		final, verdict, ok := evaluator.evaluate(match.EnglishPhelps, match.TargetVersion, match.Confidence, match.MatchReasons)
+		if !ok {
+			continue
+		}
+		evaluated++
+
+		matchType := evaluatedMatchType(match.MatchType, final.Confidence*100)
+		if matchType != match.MatchType || final.PhelpsCode != match.EnglishPhelps {
+			changed++
+		}
+		moveMatchCount(results, match.MatchType, matchType)
+		log.Printf("   🧐 %s -> %s: %s (%s)", match.EnglishPhelps, match.TargetVersion, verdict, matchType)
+
+		match.EnglishPhelps = final.PhelpsCode
+		match.Confidence = final.Confidence * 100
+		match.MatchType = matchType
+		match.MatchReasons = append(match.MatchReasons, verdict)
+		if matchType == "AMBIGUOUS" {
+			match.AmbiguityReason = verdict
+		} else {
+			match.AmbiguityReason = ""
+		}
+	}
+
+	if evaluated > 0 {
+		log.Printf("🧐 Second opinion on %d matches, %d changed", evaluated, changed)
+	}
+}
+
+// EvaluateMultiLanguageMatches applies the same second opinion to ultra batch matches
+func EvaluateMultiLanguageMatches(db Database, matches []MultiLanguageMatchResult) {
+	if !useSecondOpinion {
+		return
+	}
+
+	evaluator := newMatchEvaluator(db)
+	evaluated, changed := 0, 0
+
+	for i := range matches {
+		match := &matches[i]
+		if !needsEvaluation(match.EnglishPhelps, match.MatchType, match.Confidence) {
+			continue
+		}
+		final, verdict, ok := evaluator.evaluate(match.EnglishPhelps, match.TargetVersion, match.Confidence, match.MatchReasons)
+		if !ok {
+			continue
+		}
+		evaluated++
+
+		matchType := evaluatedMatchType(match.MatchType, final.Confidence*100)
+		if matchType != match.MatchType || final.PhelpsCode != match.EnglishPhelps {
+			changed++
+		}
+		log.Printf("   🧐 %s -> %s (%s): %s (%s)", match.EnglishPhelps, match.TargetVersion, match.TargetLanguage, verdict, matchType)
+
+		match.EnglishPhelps = final.PhelpsCode
+		match.Confidence = final.Confidence * 100
+		match.MatchType = matchType
+		match.MatchReasons = append(match.MatchReasons, verdict)
+		if matchType == "AMBIGUOUS" {
+			match.AmbiguityReason = verdict
+		} else {
+			match.AmbiguityReason = ""
+		}
+	}
+
+	if evaluated > 0 {
+		log.Printf("🧐 Second opinion on %d matches, %d changed", evaluated, changed)
+	}
+}
@@ -263,7 +263,9 @@ func TestEvaluationThresholds(t *testing.T) {
 
 // Test persistent cross-session note system
 func TestCrossSessionNotes(t *testing.T) {
-	// Initialize session to create tables
+	// Keep the notes in memory so the stored notes of real sessions are untouched
+	dryRunMode = true
+	defer func() { dryRunMode = false }()
 	initializeSession()
 
 	// Clear any existing notes for testing
@@ -302,7 +304,9 @@ func TestCrossSessionNotes(t *testing.T) {
 }
 
 func TestSearchCrossSessionNotes(t *testing.T) {
-	// Initialize session to create tables
+	// Keep the notes in memory so the stored notes of real sessions are untouched
+	dryRunMode = true
+	defer func() { dryRunMode = false }()
 	initializeSession()
 
 	// Add some test notes with different types and languages
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test Arabic-script normalization and feature extraction
+func TestNormalizeArabicScript(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Harakat and tatweel are stripped",
+			input:    "بِسْمِ ٱللّٰهِ",
+			expected: "بسم الله",
+		},
+		{
+			name:     "Persian yeh and kaf are unified with Arabic forms",
+			input:    "یا کریم",
+			expected: "يا كريم",
+		},
+		{
+			name:     "Alef with hamza and alef maqsura are folded",
+			input:    "هو الأبهى",
+			expected: "هو الابهي",
+		},
+		{
+			name:     "Persian digits become ASCII",
+			input:    "۱۹",
+			expected: "19",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := normalizeArabicScript(tt.input)
+			if result != tt.expected {
+				t.Errorf("normalizeArabicScript(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestArabicFingerprintComparableAcrossSpellings(t *testing.T) {
+	arabic := "هُوَ ٱللهُ\n\nيا إلهي، أسألك بِبَهائك أن تحفظ عبادك."
+	persian := "هو الله\n\nیا الهی، اسالک ببهائک ان تحفظ عبادک."
+
+	arFP := CreatePrayerFingerprint("", "v1", "ar", "", arabic, "")
+	faFP := CreatePrayerFingerprint("", "v2", "fa", "", persian, "")
+
+	if arFP.TextHash != faFP.TextHash {
+		t.Errorf("Expected identical text hashes, got %s and %s", arFP.TextHash, faFP.TextHash)
+	}
+	if !arFP.HasInvocation || !faFP.HasInvocation {
+		t.Error("Expected invocation to be detected in both spellings")
+	}
+	if !arFP.HasSupplication {
+		t.Error("Expected supplication to be detected")
+	}
+
+	phonetic := strings.Join(arFP.PhoneticTerms, " ")
+	for _, term := range []string{"allah", "baha", "ilahi"} {
+		if !strings.Contains(phonetic, term) {
+			t.Errorf("Expected phonetic terms to contain %q, got %v", term, arFP.PhoneticTerms)
+		}
+	}
+}
+
+func TestHasArabicInvocation(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected bool
+	}{
+		{"بسم الله الرحمن الرحيم", true},
+		{"هو الله تعالى شأنه", true},
+		{"خداوندا این بنده را یاری فرما", true},
+		{"كتاب في التاريخ", false},
+	}
+
+	for _, tt := range tests {
+		result := hasArabicInvocation(normalizeArabicScript(tt.text))
+		if result != tt.expected {
+			t.Errorf("hasArabicInvocation(%q) = %v, want %v", tt.text, result, tt.expected)
+		}
+	}
+}
+
+// Test Japanese and Korean segmentation
+func TestSegmentJapanese(t *testing.T) {
+	tokens := segmentJapanese("おお神よ、バハオラの御名によりて祈ります。")
+	expected := []string{"おお", "神", "よ", "バハオラ", "の", "御名", "によりて", "祈", "ります"}
+
+	if strings.Join(tokens, "|") != strings.Join(expected, "|") {
+		t.Errorf("segmentJapanese() = %v, want %v", tokens, expected)
+	}
+}
+
+func TestSegmentKorean(t *testing.T) {
+	tokens := segmentKorean("오 하나님이시여! 당신의 종들을 보호하소서.")
+	expected := []string{"오", "하나님", "당신", "종들", "보호하소서"}
+
+	if strings.Join(tokens, "|") != strings.Join(expected, "|") {
+		t.Errorf("segmentKorean() = %v, want %v", tokens, expected)
+	}
+}
+
+// Test that nouns ending in a particle syllable are not cut down to one syllable
+func TestStripKoreanJosa(t *testing.T) {
+	tests := []struct {
+		eojeol   string
+		expected string
+	}{
+		{"기도", "기도"},
+		{"국가", "국가"},
+		{"인도", "인도"},
+		{"기도를", "기도"},
+		{"국가의", "국가"},
+		{"하나님이", "하나님"},
+		{"주께서", "주"},
+		{"빛으로", "빛"},
+		{"그리스도", "그리스도"},
+		{"그리스도를", "그리스도"},
+		{"예수그리스도", "예수그리스도"},
+		{"바하이", "바하이"},
+		{"바하이의", "바하이"},
+	}
+	for _, tt := range tests {
+		if got := stripKoreanJosa(tt.eojeol); got != tt.expected {
+			t.Errorf("stripKoreanJosa(%q) = %q, want %q", tt.eojeol, got, tt.expected)
+		}
+	}
+}
+
+func TestJapaneseKoreanFingerprints(t *testing.T) {
+	ja := CreatePrayerFingerprint("", "v1", "ja", "", "おお神よ！汝の僕らを守護し、汝の栄光の王国へと導きたまえ。", "")
+	if ja.WordCount < 10 {
+		t.Errorf("Expected Japanese word count from kanji/kana runs, got %d", ja.WordCount)
+	}
+	if !ja.HasInvocation || !ja.HasSupplication {
+		t.Error("Expected Japanese invocation and supplication to be detected")
+	}
+	if len(ja.RareCharacters) == 0 {
+		t.Fatal("Expected rare characters for Japanese")
+	}
+	if _, ok := cjkTheologicalChars[[]rune(ja.RareCharacters[0])[0]]; !ok {
+		t.Errorf("Expected a theological kanji first in rare characters, got %v", ja.RareCharacters)
+	}
+
+	ko := CreatePrayerFingerprint("", "v2", "ko", "", "오 하나님이시여! 당신의 종들을 보호하소서. 당신의 영광의 왕국으로 인도하소서.", "")
+	if ko.WordCount != 9 {
+		t.Errorf("Expected 9 eojeol, got %d", ko.WordCount)
+	}
+	phonetic := strings.Join(ko.PhoneticTerms, " ")
+	for _, term := range []string{"god", "glory", "kingdom"} {
+		if !strings.Contains(phonetic, term) {
+			t.Errorf("Expected Korean phonetic terms to contain %q, got %v", term, ko.PhoneticTerms)
+		}
+	}
+}
+
+// Test Traditional/Simplified Chinese conversion
+func TestConvertTraditionalToSimplified(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Character mapping",
+			input:    "讚美榮耀歸於神，願祂的靈護佑眾僕。",
+			expected: "赞美荣耀归于神，愿祂的灵护佑众仆。",
+		},
+		{
+			name:     "Phrase mapping resolves context-dependent characters",
+			input:    "乾坤之主啊，求祢使我乾淨，憑藉祢的恩典活著。",
+			expected: "乾坤之主啊，求祢使我干净，凭借祢的恩典活着。",
+		},
+		{
+			name:     "Characters outside the OpenCC selection use the ICU table",
+			input:    "鸝鳴驤躍，宮中唸誦",
+			expected: "鹂鸣骧跃，宫中念诵",
+		},
+		{
+			name:     "Context-dependent characters stay unless a phrase converts them",
+			input:    "聲名狼藉，乾隆",
+			expected: "声名狼藉，乾隆",
+		},
+		{
+			name:     "Corner brackets are folded",
+			input:    "「主啊」",
+			expected: "“主啊”",
+		},
+		{
+			name:     "Simplified text is unchanged",
+			input:    "主啊！我的上帝！求祢护佑我。",
+			expected: "主啊！我的上帝！求祢护佑我。",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := convertTraditionalToSimplified(tt.input)
+			if result != tt.expected {
+				t.Errorf("convertTraditionalToSimplified(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestChineseScriptsShareTextHash(t *testing.T) {
+	hant := "主啊！我的上帝！我的庇護者！求祢以祢的恩典護佑我，使我遠離一切邪惡，在祢的聖道上堅定不移。"
+	hans := "主啊！我的上帝！我的庇护者！求祢以祢的恩典护佑我，使我远离一切邪恶，在祢的圣道上坚定不移。"
+
+	hantFP := CreatePrayerFingerprint("", "v1", "zh-Hant", "", hant, "")
+	hansFP := CreatePrayerFingerprint("", "v2", "zh-Hans", "", hans, "")
+
+	if normalizeText(normalizeChineseVariants(hant)) != normalizeText(normalizeChineseVariants(hans)) {
+		t.Error("Expected identical normalized text for Traditional and Simplified versions")
+	}
+	if hantFP.TextHash != hansFP.TextHash {
+		t.Errorf("Expected identical text hashes, got %s and %s", hantFP.TextHash, hansFP.TextHash)
+	}
+	yue := CreatePrayerFingerprint("", "v3", "yue", "", hant, "")
+	if yue.TextHash != hansFP.TextHash {
+		t.Errorf("Expected Cantonese text to be converted as well, got %s", yue.TextHash)
+	}
+
+	// Kanji in Japanese are not Traditional Chinese and keep their form
+	if CreatePrayerFingerprint("", "v4", "ja", "", "神の國", "").TextHash == CreatePrayerFingerprint("", "v5", "ja", "", "神の国", "").TextHash {
+		t.Error("Expected Japanese kanji variants not to be converted")
+	}
+}
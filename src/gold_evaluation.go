@@ -0,0 +1,470 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// -evaluate measures accuracy against a gold set of human-verified (version, Phelps)
+// pairs: rows with is_verified = true, or a curated CSV given with -gold. The gold codes
+// are hidden from a copy of the database, the chosen mode runs on the gold prayers with
+// the chosen backend, and nothing is written; the proposals are scored instead. Only
+// compressed matching, with or without the TMP fallback, can be evaluated.
+//
+//	prayer-matcher -evaluate -cli -language=es
+//	prayer-matcher -evaluate -gemini -use-tmp-fallback -gold=gold_pairs.csv
+
+// Evaluation modes
+const (
+	EvaluateCompressed  = "compressed"
+	EvaluateTMPFallback = "tmp-fallback"
+)
+
+const hashBackend = "text-hash" // backend recorded for local text-hash matches
+
+// GoldPair is a human-verified match the matcher should reproduce
+type GoldPair struct {
+	Version  string
+	Language string
+	Phelps   string
+}
+
+// EvaluationOutcome is how the matcher handled one gold pair
+type EvaluationOutcome struct {
+	Version    string   `json:"version"`
+	Language   string   `json:"language"`
+	Gold       string   `json:"gold"`
+	Predicted  string   `json:"predicted,omitempty"`
+	MatchType  string   `json:"match_type"`
+	Confidence float64  `json:"confidence"`
+	Reasons    []string `json:"reasons,omitempty"`
+	Backend    string   `json:"backend,omitempty"`
+	Applied    bool     `json:"applied"` // the mode would have written the prediction
+	Correct    bool     `json:"correct"`
+}
+
+// EvaluationMetrics are precision and recall over a group of outcomes
+type EvaluationMetrics struct {
+	Gold              int     `json:"gold"`
+	Proposed          int     `json:"proposed"`
+	ProposedCorrect   int     `json:"proposed_correct"`
+	Applied           int     `json:"applied"`
+	AppliedCorrect    int     `json:"applied_correct"`
+	Precision         float64 `json:"precision"`          // of applied predictions
+	Recall            float64 `json:"recall"`             // of gold pairs, applied and correct
+	ProposalPrecision float64 `json:"proposal_precision"` // of all proposed codes, applied or not
+}
+
+// ConfusionPair is a gold code the matcher mistook for another
+type ConfusionPair struct {
+	Gold      string `json:"gold"`
+	Predicted string `json:"predicted"`
+	Count     int    `json:"count"`
+}
+
+// CalibrationBucket compares stated confidence with accuracy for a confidence range
+type CalibrationBucket struct {
+	Low            int     `json:"low"`
+	High           int     `json:"high"`
+	Count          int     `json:"count"`
+	Correct        int     `json:"correct"`
+	MeanConfidence float64 `json:"mean_confidence"`
+	Accuracy       float64 `json:"accuracy"`
+}
+
+// EvaluationReport is the written result of an evaluation run
+type EvaluationReport struct {
+	Generated   time.Time                    `json:"generated"`
+	Mode        string                       `json:"mode"`
+	GoldSource  string                       `json:"gold_source"`
+	Overall     EvaluationMetrics            `json:"overall"`
+	ByLanguage  map[string]EvaluationMetrics `json:"by_language"`
+	ByMatchType map[string]EvaluationMetrics `json:"by_match_type"`
+	Confusion   []ConfusionPair              `json:"confusion"`
+	Calibration []CalibrationBucket          `json:"calibration"`
+	Failed      []string                     `json:"failed_languages,omitempty"`
+	Outcomes    []EvaluationOutcome          `json:"outcomes"`
+}
+
+// verifiedGoldPairs uses the verified, really coded non-English writings as gold set
+func verifiedGoldPairs(db Database) []GoldPair {
+	var gold []GoldPair
+	for _, w := range db.Writing {
+		if w.IsVerified && w.Phelps != "" && w.Language != "en" && !strings.HasPrefix(w.Phelps, TMP_CODE_PREFIX) {
+			gold = append(gold, GoldPair{Version: w.Version, Language: w.Language, Phelps: w.Phelps})
+		}
+	}
+	return gold
+}
+
+// loadGoldCSV reads a curated gold set with version and phelps columns; the language is
+// taken from the database, and rows for unknown versions are skipped
+func loadGoldCSV(r io.Reader, db Database) ([]GoldPair, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty gold file")
+	}
+	versionCol, phelpsCol := -1, -1
+	for i, column := range records[0] {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "version":
+			versionCol = i
+		case "phelps":
+			phelpsCol = i
+		}
+	}
+	if versionCol < 0 || phelpsCol < 0 {
+		return nil, fmt.Errorf("gold file needs version and phelps columns, got %v", records[0])
+	}
+
+	languages := make(map[string]string)
+	for _, w := range db.Writing {
+		languages[w.Version] = w.Language
+	}
+	var gold []GoldPair
+	skipped := 0
+	for _, rec := range records[1:] {
+		if len(rec) <= max(versionCol, phelpsCol) {
+			continue
+		}
+		version, phelps := strings.TrimSpace(rec[versionCol]), strings.TrimSpace(rec[phelpsCol])
+		language, ok := languages[version]
+		if !ok || phelps == "" {
+			skipped++
+			continue
+		}
+		gold = append(gold, GoldPair{Version: version, Language: language, Phelps: phelps})
+	}
+	if skipped > 0 {
+		log.Printf("⚠️ Skipped %d gold rows with unknown versions or empty codes", skipped)
+	}
+	return gold, nil
+}
+
+// maskGold returns a copy of the database without the gold codes, so neither the
+// references nor the text-hash index can give the answers away
+func maskGold(db Database, gold []GoldPair) Database {
+	hidden := make(map[string]bool)
+	for _, pair := range gold {
+		hidden[pair.Version] = true
+	}
+	masked := Database{Languages: db.Languages, Writing: make([]Writing, len(db.Writing))}
+	copy(masked.Writing, db.Writing)
+	for i := range masked.Writing {
+		if hidden[masked.Writing[i].Version] {
+			masked.Writing[i].Phelps = ""
+			masked.Writing[i].IsVerified = false
+		}
+	}
+	return masked
+}
+
+// wouldApplyCompressed is the rule by which the compressed modes write a match
+func wouldApplyCompressed(match CompressedMatchResult, backend string) bool {
+	if match.MatchType != "EXACT" && match.MatchType != "LIKELY" {
+		return false
+	}
+	return currentCalibration().Precision(backend, match.MatchType, match.Confidence, match.MatchReasons) >= targetPrecision
+}
+
+// sameTablet compares codes ignoring passage suffixes
+func sameTablet(a, b string) bool {
+	a, _ = splitPassageCode(a)
+	b, _ = splitPassageCode(b)
+	return a != "" && a == b
+}
+
+// scoreProposals matches the proposals for one language against its gold pairs; the
+// first localCount matches came from the text-hash index
+func scoreProposals(gold []GoldPair, results CompressedBatchResponse, localCount int) []EvaluationOutcome {
+	proposals := make(map[string]CompressedMatchResult)
+	sources := make(map[string]string)
+	for i, match := range results.Matches {
+		if match.TargetVersion == "" || match.EnglishPhelps == "" || match.MatchType == "NEW_TRANSLATION" || match.MatchType == "NEW_TMP_CODE" {
+			continue
+		}
+		// The first proposal for a version wins, as it does when writing
+		if _, seen := proposals[match.TargetVersion]; seen {
+			continue
+		}
+		proposals[match.TargetVersion] = match
+		sources[match.TargetVersion] = match.Backend
+		if i < localCount {
+			sources[match.TargetVersion] = hashBackend
+		}
+	}
+
+	var outcomes []EvaluationOutcome
+	for _, pair := range gold {
+		outcome := EvaluationOutcome{Version: pair.Version, Language: pair.Language, Gold: pair.Phelps, MatchType: "NONE"}
+		if match, ok := proposals[pair.Version]; ok {
+			outcome.Predicted = match.EnglishPhelps
+			outcome.MatchType = match.MatchType
+			outcome.Confidence = match.Confidence
+			outcome.Reasons = match.MatchReasons
+			outcome.Backend = sources[pair.Version]
+			outcome.Applied = wouldApplyCompressed(match, sources[pair.Version])
+			outcome.Correct = sameTablet(match.EnglishPhelps, pair.Phelps)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+// computeMetrics sums a group of outcomes into precision and recall
+func computeMetrics(outcomes []EvaluationOutcome) EvaluationMetrics {
+	m := EvaluationMetrics{Gold: len(outcomes)}
+	for _, o := range outcomes {
+		if o.Predicted == "" {
+			continue
+		}
+		m.Proposed++
+		if o.Correct {
+			m.ProposedCorrect++
+		}
+		if o.Applied {
+			m.Applied++
+			if o.Correct {
+				m.AppliedCorrect++
+			}
+		}
+	}
+	if m.Applied > 0 {
+		m.Precision = float64(m.AppliedCorrect) / float64(m.Applied)
+	}
+	if m.Gold > 0 {
+		m.Recall = float64(m.AppliedCorrect) / float64(m.Gold)
+	}
+	if m.Proposed > 0 {
+		m.ProposalPrecision = float64(m.ProposedCorrect) / float64(m.Proposed)
+	}
+	return m
+}
+
+// metricsBy computes metrics per value of key
+func metricsBy(outcomes []EvaluationOutcome, key func(EvaluationOutcome) string) map[string]EvaluationMetrics {
+	groups := make(map[string][]EvaluationOutcome)
+	for _, o := range outcomes {
+		groups[key(o)] = append(groups[key(o)], o)
+	}
+	metrics := make(map[string]EvaluationMetrics)
+	for k, group := range groups {
+		metrics[k] = computeMetrics(group)
+	}
+	return metrics
+}
+
+// confusionPairs counts wrong predictions by gold and predicted code, most frequent first
+func confusionPairs(outcomes []EvaluationOutcome) []ConfusionPair {
+	counts := make(map[[2]string]int)
+	for _, o := range outcomes {
+		if o.Predicted != "" && !o.Correct {
+			counts[[2]string{o.Gold, o.Predicted}]++
+		}
+	}
+	var pairs []ConfusionPair
+	for k, count := range counts {
+		pairs = append(pairs, ConfusionPair{Gold: k[0], Predicted: k[1], Count: count})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Count != pairs[j].Count {
+			return pairs[i].Count > pairs[j].Count
+		}
+		if pairs[i].Gold != pairs[j].Gold {
+			return pairs[i].Gold < pairs[j].Gold
+		}
+		return pairs[i].Predicted < pairs[j].Predicted
+	})
+	return pairs
+}
+
+// calibrationBuckets groups proposals by stated confidence in steps of ten
+func calibrationBuckets(outcomes []EvaluationOutcome) []CalibrationBucket {
+	buckets := make([]CalibrationBucket, 10)
+	for i := range buckets {
+		buckets[i].Low, buckets[i].High = i*10, i*10+9
+	}
+	buckets[9].High = 100
+	for _, o := range outcomes {
+		if o.Predicted == "" {
+			continue
+		}
+		b := &buckets[min(max(int(o.Confidence)/10, 0), 9)]
+		b.Count++
+		b.MeanConfidence += o.Confidence
+		if o.Correct {
+			b.Correct++
+		}
+	}
+	var used []CalibrationBucket
+	for _, b := range buckets {
+		if b.Count == 0 {
+			continue
+		}
+		b.MeanConfidence /= float64(b.Count)
+		b.Accuracy = float64(b.Correct) / float64(b.Count)
+		used = append(used, b)
+	}
+	return used
+}
+
+// BuildEvaluationReport computes all metrics of an evaluation run
+func BuildEvaluationReport(mode, goldSource string, outcomes []EvaluationOutcome, failed []string) EvaluationReport {
+	return EvaluationReport{
+		Generated:   time.Now(),
+		Mode:        mode,
+		GoldSource:  goldSource,
+		Overall:     computeMetrics(outcomes),
+		ByLanguage:  metricsBy(outcomes, func(o EvaluationOutcome) string { return o.Language }),
+		ByMatchType: metricsBy(outcomes, func(o EvaluationOutcome) string { return o.MatchType }),
+		Confusion:   confusionPairs(outcomes),
+		Calibration: calibrationBuckets(outcomes),
+		Failed:      failed,
+		Outcomes:    outcomes,
+	}
+}
+
+// logEvaluationReport prints the headline numbers of a report
+func logEvaluationReport(report EvaluationReport) {
+	logMetrics := func(label string, m EvaluationMetrics) {
+		log.Printf("  %-16s gold %4d  proposed %4d  applied %4d  precision %5.1f%%  recall %5.1f%%  (all proposals %5.1f%%)",
+			label, m.Gold, m.Proposed, m.Applied, 100*m.Precision, 100*m.Recall, 100*m.ProposalPrecision)
+	}
+
+	log.Printf("🎯 Evaluation of %s mode against %s", report.Mode, report.GoldSource)
+	log.Printf("------------------------------------")
+	logMetrics("overall", report.Overall)
+
+	log.Printf("By language:")
+	var languages []string
+	for language := range report.ByLanguage {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	for _, language := range languages {
+		logMetrics(language, report.ByLanguage[language])
+	}
+
+	log.Printf("By match type:")
+	for _, matchType := range []string{"EXACT", "LIKELY", "AMBIGUOUS", "NONE"} {
+		if m, ok := report.ByMatchType[matchType]; ok {
+			logMetrics(matchType, m)
+		}
+	}
+
+	log.Printf("Calibration (stated confidence vs accuracy):")
+	for _, b := range report.Calibration {
+		log.Printf("  %3d-%3d: %4d proposals, mean confidence %5.1f, accuracy %5.1f%%", b.Low, b.High, b.Count, b.MeanConfidence, 100*b.Accuracy)
+	}
+
+	if len(report.Confusion) > 0 {
+		log.Printf("Most frequent confusions:")
+		for i, pair := range report.Confusion {
+			if i == 10 {
+				break
+			}
+			log.Printf("  %s mistaken for %s (%dx)", pair.Gold, pair.Predicted, pair.Count)
+		}
+	}
+	if len(report.Failed) > 0 {
+		log.Printf("⚠️ Not evaluated (matching failed): %s", strings.Join(report.Failed, ", "))
+	}
+}
+
+// EvaluateCommand runs a matching mode on the gold set without writing and reports its accuracy
+func EvaluateCommand(goldFile, mode, language string) error {
+	db, err := GetDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load database: %w", err)
+	}
+
+	var gold []GoldPair
+	goldSource := "verified writings"
+	if goldFile != "" {
+		file, err := os.Open(goldFile)
+		if err != nil {
+			return fmt.Errorf("failed to open gold file: %w", err)
+		}
+		gold, err = loadGoldCSV(file, db)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read gold file %s: %w", goldFile, err)
+		}
+		goldSource = goldFile
+	} else {
+		gold = verifiedGoldPairs(db)
+	}
+
+	byLanguage := make(map[string][]GoldPair)
+	for _, pair := range gold {
+		if language == "" || pair.Language == language {
+			byLanguage[pair.Language] = append(byLanguage[pair.Language], pair)
+		}
+	}
+	if len(byLanguage) == 0 {
+		return fmt.Errorf("no gold pairs to evaluate")
+	}
+	var languages []string
+	for l := range byLanguage {
+		languages = append(languages, l)
+	}
+	sort.Strings(languages)
+
+	// Run as a dry run so second-opinion notes stay in memory, and keep unparseable
+	// responses out of reach of -retry, which would apply their matches
+	dryRunMode = true
+	failedResponsePrefix = "evaluation_failed_response_"
+	defer func() { failedResponsePrefix = "failed_response_" }()
+
+	masked := maskGold(db, gold)
+	writings := make(map[string]Writing)
+	for _, w := range masked.Writing {
+		writings[w.Version] = w
+	}
+
+	var outcomes []EvaluationOutcome
+	var failed []string
+	for _, l := range languages {
+		pairs := byLanguage[l]
+		log.Printf("🎯 Evaluating %d gold pairs for %s", len(pairs), l)
+
+		var targets []TargetPrayer
+		for _, pair := range pairs {
+			w := writings[pair.Version]
+			targets = append(targets, TargetPrayer{Version: w.Version, Name: w.Name, Text: cleanWritingText(w), Link: w.Link, Source: w.Source})
+		}
+
+		results, localCount, err := proposeCompressedMatches(masked, l, targets, mode == EvaluateTMPFallback)
+		if err != nil {
+			log.Printf("❌ %s: %v", l, err)
+			failed = append(failed, l)
+			continue
+		}
+		outcomes = append(outcomes, scoreProposals(pairs, results, localCount)...)
+	}
+
+	report := BuildEvaluationReport(mode, goldSource, outcomes, failed)
+	logEvaluationReport(report)
+
+	output := fmt.Sprintf("evaluation_%s_%s.json", mode, time.Now().Format("20060102_150405"))
+	err = writeReviewExport(output, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	log.Printf("✅ Evaluation written to %s", output)
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test gold set loading, masking and scoring of proposals
+func TestGoldEvaluation(t *testing.T) {
+	db := Database{Writing: []Writing{
+		{Version: "en-1", Language: "en", Phelps: "BH00001ONE", IsVerified: true},
+		{Version: "es-1", Language: "es", Phelps: "BH00001ONE", IsVerified: true},
+		{Version: "es-2", Language: "es", Phelps: "AB00002TWO", IsVerified: true},
+		{Version: "es-3", Language: "es", Phelps: "AB00003THR", IsVerified: true},
+		{Version: "es-4", Language: "es", Phelps: "TMP00001", IsVerified: true},
+		{Version: "fr-1", Language: "fr", Phelps: "BH00001ONE"},
+	}}
+
+	gold := verifiedGoldPairs(db)
+	if len(gold) != 3 || gold[0].Version != "es-1" {
+		t.Fatalf("Expected the 3 verified es pairs, got %+v", gold)
+	}
+	fromCSV, err := loadGoldCSV(strings.NewReader("phelps,version\nBH00001ONE,fr-1\nXX,missing\n"), db)
+	if err != nil || len(fromCSV) != 1 || fromCSV[0].Language != "fr" {
+		t.Errorf("Unexpected CSV gold %+v (%v)", fromCSV, err)
+	}
+	if _, err := loadGoldCSV(strings.NewReader("code\nBH00001ONE\n"), db); err == nil {
+		t.Errorf("Expected an error for a gold file without version column")
+	}
+
+	masked := maskGold(db, gold)
+	if masked.Writing[1].Phelps != "" || masked.Writing[1].IsVerified || db.Writing[1].Phelps != "BH00001ONE" || masked.Writing[0].Phelps != "BH00001ONE" {
+		t.Errorf("Expected gold codes hidden in a copy only: %+v", masked.Writing[:2])
+	}
+
+	results := CompressedBatchResponse{Matches: []CompressedMatchResult{
+		{EnglishPhelps: "BH00001ONE", TargetVersion: "es-1", MatchType: "EXACT", Confidence: 98},
+		{EnglishPhelps: "BH00001ONE", TargetVersion: "es-2", MatchType: "LIKELY", Confidence: 92, Backend: "Claude CLI"},
+		{EnglishPhelps: "AB00003THR§2", TargetVersion: "es-3", MatchType: "AMBIGUOUS", Confidence: 55, Backend: "Claude CLI"},
+		{EnglishPhelps: "AB00002TWO", TargetVersion: "es-2", MatchType: "EXACT", Confidence: 99, Backend: "Claude CLI"}, // later duplicate is ignored
+	}}
+	outcomes := scoreProposals(gold, results, 1)
+	if len(outcomes) != 3 {
+		t.Fatalf("Expected one outcome per gold pair, got %+v", outcomes)
+	}
+	if o := outcomes[0]; !o.Correct || !o.Applied || o.Backend != hashBackend {
+		t.Errorf("Unexpected first outcome %+v", o)
+	}
+	if o := outcomes[1]; o.Correct || !o.Applied || o.Backend != "Claude CLI" {
+		t.Errorf("Unexpected second outcome %+v", o)
+	}
+	if o := outcomes[2]; !o.Correct || o.Applied {
+		t.Errorf("Passage proposal should count as correct but not applied: %+v", o)
+	}
+
+	m := computeMetrics(outcomes)
+	if m.Applied != 2 || m.AppliedCorrect != 1 || m.Precision != 0.5 || m.Recall != 1.0/3 || m.Proposed != 3 || m.ProposedCorrect != 2 {
+		t.Errorf("Unexpected metrics %+v", m)
+	}
+
+	report := BuildEvaluationReport(EvaluateCompressed, "verified writings", outcomes, nil)
+	if len(report.Confusion) != 1 || report.Confusion[0].Gold != "AB00002TWO" || report.Confusion[0].Predicted != "BH00001ONE" {
+		t.Errorf("Unexpected confusion %+v", report.Confusion)
+	}
+	if report.ByMatchType["EXACT"].Precision != 1 || report.ByLanguage["es"].Gold != 3 {
+		t.Errorf("Unexpected breakdown %+v %+v", report.ByMatchType, report.ByLanguage)
+	}
+	if len(report.Calibration) != 2 || report.Calibration[1].Low != 90 || report.Calibration[1].High != 100 || report.Calibration[1].Accuracy != 0.5 {
+		t.Errorf("Unexpected calibration %+v", report.Calibration)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// latinDiacriticFolds maps accented Latin letters to their base letter for near-hash comparison
+var latinDiacriticFolds = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a', 'ã': 'a', 'å': 'a', 'ā': 'a', 'ă': 'a', 'ą': 'a',
+	'ç': 'c', 'ć': 'c', 'č': 'c', 'ď': 'd', 'đ': 'd',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e', 'ė': 'e', 'ę': 'e', 'ě': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i', 'į': 'i', 'ı': 'i',
+	'ñ': 'n', 'ń': 'n', 'ň': 'n', 'ł': 'l', 'ľ': 'l', 'ĺ': 'l',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o', 'õ': 'o', 'ø': 'o', 'ō': 'o', 'ő': 'o',
+	'ř': 'r', 'ŕ': 'r', 'ś': 's', 'š': 's', 'ş': 's', 'ș': 's', 'ß': 's',
+	'ť': 't', 'ţ': 't', 'ț': 't',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u', 'ů': 'u', 'ű': 'u', 'ų': 'u',
+	'ý': 'y', 'ÿ': 'y', 'ź': 'z', 'ż': 'z', 'ž': 'z',
+	'ḥ': 'h', 'ḍ': 'd', 'ṭ': 't', 'ẓ': 'z', 'ṣ': 's', 'ṇ': 'n', 'ṃ': 'm',
+	'ё': 'е', 'ʼ': 0, 'ʻ': 0, 'ʽ': 0, '\'': 0, '’': 0, '‘': 0,
+}
+
+// hashIndexEntry is an already matched writing reachable through its text hash
+type hashIndexEntry struct {
+	Phelps   string
+	Version  string
+	Language string
+}
+
+// HashIndex finds already matched writings by exact and near text hash, so
+// duplicated texts (en vs en-US, zh-Hant vs zh-Hans) are paired without the LLM.
+// Texts differing by footnotes or an extra line are caught by the MinHash index.
+type HashIndex struct {
+	exact     map[string][]hashIndexEntry
+	near      map[string][]hashIndexEntry
+	minhash   *NearDuplicateIndex
+	byVersion map[string]string // version -> current phelps
+}
+
+// nearTextHash hashes text with diacritics, apostrophes, digits and script variants
+// folded away, so punctuation and spelling-convention differences still collide
+func nearTextHash(text, language string) string {
+	hash := md5.Sum([]byte(foldedLetters(text, language)))
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+// BuildHashIndex indexes every writing that already carries a Phelps or TMP code
+func BuildHashIndex(db Database) *HashIndex {
+	index := &HashIndex{
+		exact:     make(map[string][]hashIndexEntry),
+		near:      make(map[string][]hashIndexEntry),
+		minhash:   NewNearDuplicateIndex(),
+		byVersion: make(map[string]string),
+	}
+
+	for _, w := range db.Writing {
+		index.byVersion[w.Version] = w.Phelps
+		if w.Phelps == "" || w.Text == "" {
+			continue
+		}
+
+		text := cleanWritingText(w)
+		entry := hashIndexEntry{Phelps: w.Phelps, Version: w.Version, Language: w.Language}
+		exactHash := textHash(normalizeText(preprocessScriptText(text, w.Language)))
+		nearHash := nearTextHash(text, w.Language)
+		index.exact[exactHash] = append(index.exact[exactHash], entry)
+		index.near[nearHash] = append(index.near[nearHash], entry)
+		index.minhash.Add(w.Version, w.Language, w.Phelps, minHashSignature(text, w.Language))
+	}
+
+	return index
+}
+
+// lookup returns the distinct Phelps codes and entries for a hash, excluding the target itself
+func (idx *HashIndex) lookup(table map[string][]hashIndexEntry, hash, version string) ([]string, []hashIndexEntry) {
+	seen := make(map[string]bool)
+	var codes []string
+	var entries []hashIndexEntry
+	for _, entry := range table[hash] {
+		if entry.Version == version {
+			continue
+		}
+		entries = append(entries, entry)
+		if !seen[entry.Phelps] {
+			seen[entry.Phelps] = true
+			codes = append(codes, entry.Phelps)
+		}
+	}
+	sort.Strings(codes)
+	return codes, entries
+}
+
+// lookupSimilar returns the distinct Phelps codes of near-duplicate texts, excluding the target itself
+func (idx *HashIndex) lookupSimilar(signature []uint64, version string) ([]string, []hashIndexEntry, float64) {
+	seen := make(map[string]bool)
+	var codes []string
+	var entries []hashIndexEntry
+	best := 0.0
+	for _, dup := range idx.minhash.Query(signature, nearDuplicateThreshold) {
+		if dup.Version == version {
+			continue
+		}
+		if dup.Similarity > best {
+			best = dup.Similarity
+		}
+		entries = append(entries, hashIndexEntry{Phelps: dup.Phelps, Version: dup.Version, Language: dup.Language})
+		if !seen[dup.Phelps] {
+			seen[dup.Phelps] = true
+			codes = append(codes, dup.Phelps)
+		}
+	}
+	sort.Strings(codes)
+	return codes, entries, best
+}
+
+// describeHashEntries formats the colliding writings for match reasons
+func describeHashEntries(entries []hashIndexEntry) string {
+	var parts []string
+	for i, entry := range entries {
+		if i >= 3 {
+			parts = append(parts, fmt.Sprintf("+%d more", len(entries)-i))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s/%s", entry.Language, entry.Version))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PrematchByTextHash resolves target fingerprints whose text collides with an already
+// matched writing. Exact hash hits become EXACT matches, near-hash and MinHash
+// near-duplicate hits become LIKELY matches, and hits on conflicting codes become AMBIGUOUS. Targets that already carry
+// the colliding code are dropped; everything else is returned for the LLM prompt.
+func PrematchByTextHash(index *HashIndex, targets []PrayerFingerprint) ([]CompressedMatchResult, []PrayerFingerprint) {
+	var matches []CompressedMatchResult
+	var remaining []PrayerFingerprint
+
+	for _, fp := range targets {
+		current := index.byVersion[fp.Version]
+
+		codes, entries := index.lookup(index.exact, fp.TextHash, fp.Version)
+		matchType, confidence, method := "EXACT", 100.0, "exact text hash"
+		if len(codes) == 0 {
+			codes, entries = index.lookup(index.near, fp.NearHash, fp.Version)
+			matchType, confidence, method = "LIKELY", 90.0, "likely text hash"
+		}
+		if len(codes) == 0 {
+			var similarity float64
+			codes, entries, similarity = index.lookupSimilar(fp.MinHash, fp.Version)
+			matchType, confidence = "LIKELY", 85.0
+			method = fmt.Sprintf("near-duplicate text (%.0f%% similar)", similarity*100)
+		}
+
+		switch {
+		case len(codes) == 0:
+			remaining = append(remaining, fp)
+
+		case len(codes) == 1 && codes[0] == current:
+			// Already linked to the same code as its duplicate text - nothing to ask
+			continue
+
+		case len(codes) == 1 && current != "" && !isTMPCode(current):
+			// Text duplicates a different prayer than the one it is linked to; let the LLM judge
+			remaining = append(remaining, fp)
+
+		case len(codes) == 1:
+			matches = append(matches, CompressedMatchResult{
+				EnglishPhelps:  codes[0],
+				TargetVersion:  fp.Version,
+				TargetLanguage: fp.Language,
+				MatchType:      matchType,
+				Confidence:     confidence,
+				MatchReasons: []string{
+					fmt.Sprintf("local %s match with %s", method, describeHashEntries(entries)),
+				},
+			})
+
+		default:
+			matches = append(matches, CompressedMatchResult{
+				EnglishPhelps:   codes[0],
+				TargetVersion:   fp.Version,
+				TargetLanguage:  fp.Language,
+				MatchType:       "AMBIGUOUS",
+				Confidence:      50,
+				MatchReasons:    []string{fmt.Sprintf("%s collides with %s", method, describeHashEntries(entries))},
+				AmbiguityReason: fmt.Sprintf("duplicate text is linked to %d different codes: %s", len(codes), strings.Join(codes, ", ")),
+			})
+		}
+	}
+
+	return matches, remaining
+}
+
+// prematchLanguageTargets runs PrematchByTextHash and logs what was resolved locally
+func prematchLanguageTargets(index *HashIndex, targets []PrayerFingerprint, language string) ([]CompressedMatchResult, []PrayerFingerprint) {
+	matches, remaining := PrematchByTextHash(index, targets)
+	if resolved := len(targets) - len(remaining); resolved > 0 {
+		log.Printf("🔍 %s: %d prayers resolved by local text hash (%d proposed matches), %d left for the LLM",
+			language, resolved, len(matches), len(remaining))
+	}
+	return matches, remaining
+}
+
+// mergeLocalMatches prepends locally found matches to an LLM response and updates its counters
+func mergeLocalMatches(results *CompressedBatchResponse, local []CompressedMatchResult) {
+	for _, match := range local {
+		switch match.MatchType {
+		case "EXACT":
+			results.ExactMatches++
+		case "LIKELY":
+			results.LikelyMatches++
+		case "AMBIGUOUS":
+			results.AmbiguousCount++
+		}
+	}
+	results.Matches = append(local, results.Matches...)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+)
+
+// Test local text hash pre-matching
+func TestPrematchByTextHash(t *testing.T) {
+	db := Database{
+		Writing: []Writing{
+			{Phelps: "AB00001FIR", Version: "en-1", Language: "en", Text: "O God, guide me, protect me, make of me a shining lamp and a brilliant star."},
+			{Phelps: "BH00002SEC", Version: "en-2", Language: "en", Text: "Blessed is the spot, and the house, and the place, and the city."},
+			{Phelps: "BH00003THI", Version: "en-3", Language: "en", Text: "Is there any Remover of difficulties save God?"},
+			{Phelps: "BH00004FOU", Version: "en-4", Language: "en", Text: "Is there any Remover of difficulties save God?"},
+			{Phelps: "TMP00001", Version: "zh-1", Language: "zh-Hans", Text: "主啊！我的上帝！求祢护佑我。"},
+		},
+	}
+	index := BuildHashIndex(db)
+
+	targets := []PrayerFingerprint{
+		CreatePrayerFingerprint("", "us-1", "en-US", "", "O God, guide me, protect me, make of me a shining lamp and a brilliant star.", ""),
+		CreatePrayerFingerprint("", "us-2", "en-US", "", "Blessèd is the spot — and the house, and the place, and the city!", ""),
+		CreatePrayerFingerprint("", "us-3", "en-US", "", "Is there any Remover of difficulties save God?", ""),
+		CreatePrayerFingerprint("", "zh-2", "zh-Hant", "", "主啊！我的上帝！求祢護佑我。", ""),
+		CreatePrayerFingerprint("", "us-4", "en-US", "", "A prayer that has no duplicate anywhere.", ""),
+	}
+
+	matches, remaining := PrematchByTextHash(index, targets)
+
+	if len(remaining) != 1 || remaining[0].Version != "us-4" {
+		t.Fatalf("Expected only us-4 to remain for the LLM, got %d remaining", len(remaining))
+	}
+
+	expected := map[string]struct {
+		phelps    string
+		matchType string
+	}{
+		"us-1": {"AB00001FIR", "EXACT"},
+		"us-2": {"BH00002SEC", "LIKELY"},
+		"us-3": {"BH00003THI", "AMBIGUOUS"},
+		"zh-2": {"TMP00001", "EXACT"},
+	}
+
+	if len(matches) != len(expected) {
+		t.Fatalf("Expected %d local matches, got %d: %+v", len(expected), len(matches), matches)
+	}
+	for _, m := range matches {
+		want, ok := expected[m.TargetVersion]
+		if !ok {
+			t.Errorf("Unexpected match for %s", m.TargetVersion)
+			continue
+		}
+		if m.EnglishPhelps != want.phelps || m.MatchType != want.matchType {
+			t.Errorf("%s: got %s/%s, want %s/%s", m.TargetVersion, m.EnglishPhelps, m.MatchType, want.phelps, want.matchType)
+		}
+	}
+}
+
+func TestPrematchSkipsAlreadyLinkedDuplicates(t *testing.T) {
+	text := "O God, guide me, protect me, make of me a shining lamp and a brilliant star."
+	db := Database{
+		Writing: []Writing{
+			{Phelps: "AB00001FIR", Version: "en-1", Language: "en", Text: text},
+			{Phelps: "AB00001FIR", Version: "us-1", Language: "en-US", Text: text},
+		},
+	}
+
+	matches, remaining := PrematchByTextHash(BuildHashIndex(db), []PrayerFingerprint{
+		CreatePrayerFingerprint("", "us-1", "en-US", "", text, ""),
+	})
+
+	if len(matches) != 0 || len(remaining) != 0 {
+		t.Errorf("Expected already-linked duplicate to be dropped, got %d matches and %d remaining", len(matches), len(remaining))
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The export subcommand publishes the matches as a cross-language link dataset: per
+// Phelps code, every language version with its name, link and source, plus per-language
+// completeness. Downstream sites can consume the JSON directly.
+//
+//	prayer-matcher export -format=json -output=prayer_links.json
+//	prayer-matcher export -format=html -language=en,es,fr
+
+// LinkedVersion is one language version of a prayer
+type LinkedVersion struct {
+	Version      string `json:"version"`
+	Language     string `json:"language"`
+	LanguageName string `json:"language_name,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Link         string `json:"link,omitempty"`
+	Source       string `json:"source,omitempty"`
+	SourceID     string `json:"source_id,omitempty"`
+	Verified     bool   `json:"verified"`
+	Passage      int    `json:"passage,omitempty"` // passage of the Tablet this version is an excerpt of
+}
+
+// LinkedPrayer is a Phelps code with all its language versions
+type LinkedPrayer struct {
+	Phelps    string          `json:"phelps"`
+	Name      string          `json:"name,omitempty"` // English name when there is one
+	Languages int             `json:"languages"`
+	Versions  []LinkedVersion `json:"versions"`
+}
+
+// LanguageCompleteness tells how much of a language is linked
+type LanguageCompleteness struct {
+	Language     string  `json:"language"`
+	Name         string  `json:"name,omitempty"`
+	Versions     int     `json:"versions"`      // prayers in the language
+	Linked       int     `json:"linked"`        // of which have a Phelps code
+	PercentDone  float64 `json:"percent_done"`  // linked / versions
+	Codes        int     `json:"codes"`         // distinct codes the language has
+	CodeCoverage float64 `json:"code_coverage"` // codes / all exported codes
+}
+
+// LinkDataset is the exported dataset
+type LinkDataset struct {
+	Generated time.Time              `json:"generated"`
+	Codes     int                    `json:"codes"`
+	Prayers   []LinkedPrayer         `json:"prayers"`
+	Languages []LanguageCompleteness `json:"languages"`
+}
+
+// BuildLinkDataset groups matched writings by Phelps code; TMP codes are left out unless
+// includeTMP, since they only link en/ar/fa prayers that have no real code yet
+func BuildLinkDataset(db Database, languages map[string]bool, includeTMP bool) LinkDataset {
+	languageNames := make(map[string]string)
+	for _, l := range db.Languages {
+		languageNames[l.LangCode] = l.Name
+	}
+	include := func(language string) bool {
+		return len(languages) == 0 || languages[language]
+	}
+
+	byCode := make(map[string]*LinkedPrayer)
+	stats := make(map[string]*LanguageCompleteness)
+	langCodes := make(map[string]map[string]bool)
+	for _, w := range db.Writing {
+		if !include(w.Language) {
+			continue
+		}
+		stat, ok := stats[w.Language]
+		if !ok {
+			stat = &LanguageCompleteness{Language: w.Language, Name: languageNames[w.Language]}
+			stats[w.Language] = stat
+			langCodes[w.Language] = make(map[string]bool)
+		}
+		stat.Versions++
+
+		if w.Phelps == "" || (!includeTMP && strings.HasPrefix(w.Phelps, TMP_CODE_PREFIX)) {
+			continue
+		}
+		stat.Linked++
+		langCodes[w.Language][w.Phelps] = true
+
+		prayer, ok := byCode[w.Phelps]
+		if !ok {
+			prayer = &LinkedPrayer{Phelps: w.Phelps}
+			byCode[w.Phelps] = prayer
+		}
+		if w.Language == "en" && prayer.Name == "" {
+			prayer.Name = w.Name
+		}
+
+		version := LinkedVersion{
+			Version:      w.Version,
+			Language:     w.Language,
+			LanguageName: languageNames[w.Language],
+			Name:         w.Name,
+			Link:         w.Link,
+			Source:       w.Source,
+			SourceID:     w.SourceID,
+			Verified:     w.IsVerified,
+		}
+		if phelps, passage, ok := parseExcerptNote(w.Notes); ok && phelps == w.Phelps {
+			version.Passage = passage
+		}
+		prayer.Versions = append(prayer.Versions, version)
+	}
+
+	dataset := LinkDataset{Generated: time.Now(), Codes: len(byCode)}
+	for _, prayer := range byCode {
+		sort.Slice(prayer.Versions, func(i, j int) bool {
+			if prayer.Versions[i].Language != prayer.Versions[j].Language {
+				return prayer.Versions[i].Language < prayer.Versions[j].Language
+			}
+			return prayer.Versions[i].Version < prayer.Versions[j].Version
+		})
+		seen := make(map[string]bool)
+		for _, v := range prayer.Versions {
+			seen[v.Language] = true
+		}
+		prayer.Languages = len(seen)
+		if prayer.Name == "" && len(prayer.Versions) > 0 {
+			prayer.Name = prayer.Versions[0].Name
+		}
+		dataset.Prayers = append(dataset.Prayers, *prayer)
+	}
+	sort.Slice(dataset.Prayers, func(i, j int) bool {
+		return dataset.Prayers[i].Phelps < dataset.Prayers[j].Phelps
+	})
+
+	for language, stat := range stats {
+		stat.Codes = len(langCodes[language])
+		if stat.Versions > 0 {
+			stat.PercentDone = 100 * float64(stat.Linked) / float64(stat.Versions)
+		}
+		if dataset.Codes > 0 {
+			stat.CodeCoverage = 100 * float64(stat.Codes) / float64(dataset.Codes)
+		}
+		dataset.Languages = append(dataset.Languages, *stat)
+	}
+	sort.Slice(dataset.Languages, func(i, j int) bool {
+		if dataset.Languages[i].Codes != dataset.Languages[j].Codes {
+			return dataset.Languages[i].Codes > dataset.Languages[j].Codes
+		}
+		return dataset.Languages[i].Language < dataset.Languages[j].Language
+	})
+
+	return dataset
+}
+
+// writeLinkDatasetCSV writes one row per language version
+func writeLinkDatasetCSV(w io.Writer, dataset LinkDataset) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"phelps", "prayer_name", "language", "language_name", "version", "name", "link", "source", "source_id", "verified", "passage"})
+	for _, prayer := range dataset.Prayers {
+		for _, v := range prayer.Versions {
+			passage := ""
+			if v.Passage > 0 {
+				passage = strconv.Itoa(v.Passage)
+			}
+			writer.Write([]string{prayer.Phelps, prayer.Name, v.Language, v.LanguageName, v.Version, v.Name, v.Link, v.Source, v.SourceID, strconv.FormatBool(v.Verified), passage})
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeLanguageCompletenessCSV writes the per-language stats that accompany the CSV export
+func writeLanguageCompletenessCSV(w io.Writer, dataset LinkDataset) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"language", "name", "versions", "linked", "percent_done", "codes", "code_coverage"})
+	for _, l := range dataset.Languages {
+		writer.Write([]string{
+			l.Language, l.Name, strconv.Itoa(l.Versions), strconv.Itoa(l.Linked),
+			strconv.FormatFloat(l.PercentDone, 'f', 1, 64), strconv.Itoa(l.Codes),
+			strconv.FormatFloat(l.CodeCoverage, 'f', 1, 64),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+var linkIndexTemplate = template.Must(template.New("links").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Bahá'í prayers across languages</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+td, th { border: 1px solid #ccc; padding: 3px 8px; text-align: left; font-size: 14px; }
+.prayer { margin-bottom: 1em; }
+.prayer h3 { margin: 0; font-size: 16px; }
+.versions { font-size: 14px; }
+.versions span { margin-right: 1em; white-space: nowrap; }
+</style>
+</head>
+<body>
+<h1>Bahá'í prayers across languages</h1>
+<p>{{.Codes}} prayers, generated {{.Generated.Format "2006-01-02 15:04"}}</p>
+
+<h2>Languages</h2>
+<table>
+<tr><th>Language</th><th>Prayers</th><th>Linked</th><th>Done</th><th>Codes</th><th>Coverage</th></tr>
+{{range .Languages}}<tr><td>{{.Language}}{{if .Name}} ({{.Name}}){{end}}</td><td>{{.Versions}}</td><td>{{.Linked}}</td><td>{{printf "%.1f" .PercentDone}}%</td><td>{{.Codes}}</td><td>{{printf "%.1f" .CodeCoverage}}%</td></tr>
+{{end}}</table>
+
+<h2>Prayers</h2>
+{{range .Prayers}}<div class="prayer" id="{{.Phelps}}">
+<h3>{{.Phelps}}{{if .Name}} – {{.Name}}{{end}} <small>({{.Languages}} languages)</small></h3>
+<div class="versions">{{range .Versions}}<span>{{.Language}}: {{if .Link}}<a href="{{.Link}}">{{if .Name}}{{.Name}}{{else}}{{.Version}}{{end}}</a>{{else}}{{if .Name}}{{.Name}}{{else}}{{.Version}}{{end}}{{end}}{{if .Passage}} §{{.Passage}}{{end}}</span>{{end}}</div>
+</div>
+{{end}}
+</body>
+</html>
+`))
+
+// ExportLinksCommand writes the link dataset as JSON, CSV or a static HTML index
+func ExportLinksCommand(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "json", "Output format: json, csv, html")
+	output := fs.String("output", "", "Output file, - for stdout (default prayer_links.<format>)")
+	languages := fs.String("language", "", "Only include these languages (comma-separated, e.g. en,es)")
+	includeTMP := fs.Bool("include-tmp", false, "Include prayers that only have temporary TMP codes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	*format = strings.ToLower(*format)
+	if *format != "json" && *format != "csv" && *format != "html" {
+		return fmt.Errorf("unknown format %q (use json, csv, html)", *format)
+	}
+	if *output == "" {
+		*output = "prayer_links." + *format
+	}
+	filter := make(map[string]bool)
+	for _, language := range strings.Split(*languages, ",") {
+		if language = strings.TrimSpace(language); language != "" {
+			filter[language] = true
+		}
+	}
+
+	db, err := GetDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load database: %w", err)
+	}
+	dataset := BuildLinkDataset(db, filter, *includeTMP)
+	log.Printf("🔗 %d prayers linked across %d languages", dataset.Codes, len(dataset.Languages))
+
+	err = writeReviewExport(*output, func(w io.Writer) error {
+		switch *format {
+		case "csv":
+			return writeLinkDatasetCSV(w, dataset)
+		case "html":
+			return linkIndexTemplate.Execute(w, dataset)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(dataset)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+
+	// CSV rows have no room for the language stats, so they get a file of their own
+	if *format == "csv" && *output != "-" {
+		statsFile := strings.TrimSuffix(*output, ".csv") + "_languages.csv"
+		if err := writeReviewExport(statsFile, func(w io.Writer) error { return writeLanguageCompletenessCSV(w, dataset) }); err != nil {
+			return fmt.Errorf("failed to write %s: %w", statsFile, err)
+		}
+		log.Printf("✅ Language completeness written to %s", statsFile)
+	}
+	if *output != "-" {
+		log.Printf("✅ Link dataset written to %s", *output)
+	}
+	return nil
+}
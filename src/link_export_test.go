@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test building and rendering the cross-language link dataset
+func TestBuildLinkDataset(t *testing.T) {
+	db := Database{
+		Writing: []Writing{
+			{Version: "en-1", Language: "en", Phelps: "BH00001ONE", Name: "Guidance", Link: "https://example.org/en-1", Source: "bahaiprayers.net", IsVerified: true},
+			{Version: "es-1", Language: "es", Phelps: "BH00001ONE", Name: "Guía"},
+			{Version: "es-2", Language: "es", Phelps: "AB00002TWO", Notes: excerptNote("AB00002TWO", 3)},
+			{Version: "es-3", Language: "es"},
+			{Version: "ar-1", Language: "ar", Phelps: "TMP00001"},
+			{Version: "fr-1", Language: "fr", Phelps: "BH00001ONE", Name: "Direction"},
+		},
+		Languages: []Language{{LangCode: "es", Name: "Spanish"}, {LangCode: "en", Name: "English"}},
+	}
+
+	dataset := BuildLinkDataset(db, nil, false)
+	if dataset.Codes != 2 || len(dataset.Prayers) != 2 {
+		t.Fatalf("Expected 2 codes without TMP, got %+v", dataset.Prayers)
+	}
+	prayer := dataset.Prayers[1]
+	if prayer.Phelps != "BH00001ONE" || prayer.Name != "Guidance" || prayer.Languages != 3 || prayer.Versions[1].LanguageName != "Spanish" {
+		t.Errorf("Unexpected prayer %+v", prayer)
+	}
+	if v := dataset.Prayers[0].Versions[0]; v.Passage != 3 || dataset.Prayers[0].Name != "" {
+		t.Errorf("Expected the excerpt passage and no name, got %+v", dataset.Prayers[0])
+	}
+
+	var spanish LanguageCompleteness
+	for _, l := range dataset.Languages {
+		if l.Language == "es" {
+			spanish = l
+		}
+	}
+	if spanish.Versions != 3 || spanish.Linked != 2 || spanish.Codes != 2 || spanish.CodeCoverage != 100 {
+		t.Errorf("Unexpected Spanish completeness %+v", spanish)
+	}
+	if dataset.Languages[0].Language != "es" {
+		t.Errorf("Expected languages ordered by codes, got %+v", dataset.Languages)
+	}
+
+	withTMP := BuildLinkDataset(db, map[string]bool{"ar": true}, true)
+	if withTMP.Codes != 1 || withTMP.Prayers[0].Phelps != "TMP00001" || len(withTMP.Languages) != 1 {
+		t.Errorf("Expected only the Arabic TMP prayer, got %+v", withTMP)
+	}
+
+	var csvOut, html strings.Builder
+	if err := writeLinkDatasetCSV(&csvOut, dataset); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n"); len(lines) != 5 || !strings.Contains(csvOut.String(), "AB00002TWO,,es,Spanish,es-2,,,,,false,3") {
+		t.Errorf("Unexpected CSV:\n%s", csvOut.String())
+	}
+	if err := linkIndexTemplate.Execute(&html, dataset); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), `<a href="https://example.org/en-1">Guidance</a>`) || !strings.Contains(html.String(), "es-2 §3") {
+		t.Errorf("Unexpected HTML:\n%s", html.String())
+	}
+}
@@ -31,6 +31,8 @@ var useSmartFallback bool
 var useStatusCheck bool
 var useRetryBatches bool
 var useCsvProcessing bool
+var useSecondOpinion = true
+var lastLLMBackend string // backend that answered the last callLLMWithBackendFallback
 var logFile string
 var claudeModel = "claude-sonnet-4-20250514" // Latest Sonnet 4
 
@@ -56,7 +58,7 @@ type Language struct {
 }
 
 type Database struct {
-	Writings  []Writing
+	Writing   []Writing
 	Languages []Language
 }
 
@@ -66,6 +68,7 @@ type EnglishReference struct {
 	Name     string
 	Text     string
 	Category string
+	Source   string
 }
 
 // TargetPrayer represents a prayer in the target language to be matched
@@ -162,7 +165,7 @@ func parseBool(s string) bool {
 // GetDatabase loads the writings and languages from Dolt
 func GetDatabase() (Database, error) {
 	db := Database{
-		Writings:  []Writing{},
+		Writing:   []Writing{},
 		Languages: []Language{},
 	}
 
@@ -183,7 +186,7 @@ func GetDatabase() (Database, error) {
 			continue
 		}
 		w := Writing{
-			Phelps:     rec[0],
+			Phelps:     normalizePhelps(rec[0]),
 			Language:   rec[1],
 			Version:    rec[2],
 			Name:       rec[3],
@@ -195,7 +198,7 @@ func GetDatabase() (Database, error) {
 			SourceID:   rec[9],
 			IsVerified: parseBool(rec[10]),
 		}
-		db.Writings = append(db.Writings, w)
+		db.Writing = append(db.Writing, w)
 	}
 
 	// Load languages
@@ -394,16 +397,23 @@ func CallClaude(prompt string, maxTokens int) (string, error) {
 
 // --- Matching Logic ---
 
+// cleanWritingText returns the writing's text with editorial material removed
+func cleanWritingText(w Writing) string {
+	text, _ := CleanPrayerText(w.Text, w.Source)
+	return text
+}
+
 // BuildEnglishReference extracts all English prayers with Phelps codes
 func BuildEnglishReference(db Database) []EnglishReference {
 	var refs []EnglishReference
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Language == "en" && w.Phelps != "" && w.Text != "" {
 			refs = append(refs, EnglishReference{
 				Phelps:   w.Phelps,
 				Name:     w.Name,
-				Text:     w.Text,
+				Text:     cleanWritingText(w),
 				Category: w.Type,
+				Source:   w.Source,
 			})
 		}
 	}
@@ -412,54 +422,50 @@ func BuildEnglishReference(db Database) []EnglishReference {
 
 // getAttemptedPrayersFromReviews returns a set of prayer IDs that have already been attempted
 func getAttemptedPrayersFromReviews(language string) map[string]bool {
-	attempted := make(map[string]bool)
-
-	// Look for review files for this language
-	pattern := fmt.Sprintf("review_*_%s_*.txt", language)
-	files, err := filepath.Glob(pattern)
+	items, err := openReviewQueue()
 	if err != nil {
-		return attempted
+		log.Printf("⚠️ Could not read review queue: %v", err)
+		return make(map[string]bool)
 	}
+	return ReviewedVersions(items, language)
+}
 
-	for _, file := range files {
-		// Skip summary files
-		if strings.Contains(file, "review_summary_") {
-			continue
-		}
-
-		// Parse review file to extract prayer IDs
-		prayerIDs := extractPrayerIDsFromReviewFile(file)
-		for _, id := range prayerIDs {
-			attempted[id] = true
-		}
+// openReviewQueue loads the review queue; before the queue file exists the review text
+// files of earlier runs are imported in memory, and the commands that change the queue
+// save them with their change
+func openReviewQueue() ([]ReviewItem, error) {
+	if _, err := os.Stat(reviewQueueFile); err == nil {
+		return LoadReviewQueue(reviewQueueFile)
 	}
 
-	return attempted
-}
-
-// extractPrayerIDsFromReviewFile extracts target prayer IDs from a review file
-func extractPrayerIDsFromReviewFile(filename string) []string {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil
+	ambiguousFiles, _ := filepath.Glob("review_ambiguous_*.txt")
+	lowConfFiles, _ := filepath.Glob("review_low_confidence_*.txt")
+	files := append(ambiguousFiles, lowConfFiles...)
+	if len(files) == 0 {
+		return nil, nil
 	}
 
-	content := string(data)
-	var prayerIDs []string
+	items, imported := ImportLegacyReviewFiles(nil, files)
+	log.Printf("📥 Read %d entries from %d review files, saved to %s with the next change", imported, len(files), reviewQueueFile)
+	return items, nil
+}
 
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		// Look for entries like "[1/366] Target Prayer: 00b7402e-76ec-4a03-a8c4-1c3db8615a3d"
-		if strings.Contains(line, "] Target Prayer: ") {
-			parts := strings.Split(line, "] Target Prayer: ")
-			if len(parts) == 2 {
-				prayerIDs = append(prayerIDs, parts[1])
-			}
-		}
+// reviewProposals converts matches into proposals for the review queue
+func reviewProposals(matches []CompressedMatchResult) []ReviewProposal {
+	proposals := make([]ReviewProposal, 0, len(matches))
+	for _, match := range matches {
+		proposals = append(proposals, ReviewProposal{
+			Version: match.TargetVersion,
+			ReviewCandidate: ReviewCandidate{
+				Phelps:     match.EnglishPhelps,
+				Confidence: match.Confidence,
+				MatchType:  match.MatchType,
+				Reasons:    match.MatchReasons,
+				Ambiguity:  match.AmbiguityReason,
+			},
+		})
 	}
-
-	return prayerIDs
+	return proposals
 }
 
 // BuildTargetPrayers extracts all prayers in target language, filtering out already-attempted ones
@@ -468,7 +474,7 @@ func BuildTargetPrayers(db Database, language string) []TargetPrayer {
 	attemptedPrayers := getAttemptedPrayersFromReviews(language)
 
 	var prayers []TargetPrayer
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Language == language && w.Text != "" {
 			// Skip prayers that have already been attempted (unless they're matched)
 			if attemptedPrayers[w.Version] && w.Phelps == "" {
@@ -478,7 +484,7 @@ func BuildTargetPrayers(db Database, language string) []TargetPrayer {
 			prayers = append(prayers, TargetPrayer{
 				Version: w.Version,
 				Name:    w.Name,
-				Text:    w.Text,
+				Text:    cleanWritingText(w),
 				Link:    w.Link,
 				Source:  w.Source,
 			})
@@ -487,7 +493,7 @@ func BuildTargetPrayers(db Database, language string) []TargetPrayer {
 
 	// Count total prayers for this language
 	totalForLang := 0
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Language == language {
 			totalForLang++
 		}
@@ -508,14 +514,14 @@ func BuildTargetPrayersWithHeuristics(db Database, language string) []TargetPray
 	var matchedPrayers []TargetPrayer
 
 	// Separate unmatched and matched prayers
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Language == language && w.Text != "" {
 			if w.Phelps != "" {
 				// This is a matched prayer - add to matched collection
 				matchedPrayers = append(matchedPrayers, TargetPrayer{
 					Version: w.Version,
 					Name:    w.Name,
-					Text:    w.Text,
+					Text:    cleanWritingText(w),
 					Link:    w.Link,
 					Source:  w.Source,
 				})
@@ -529,7 +535,7 @@ func BuildTargetPrayersWithHeuristics(db Database, language string) []TargetPray
 				unmatchedPrayers = append(unmatchedPrayers, TargetPrayer{
 					Version: w.Version,
 					Name:    w.Name,
-					Text:    w.Text,
+					Text:    cleanWritingText(w),
 					Link:    w.Link,
 					Source:  w.Source,
 				})
@@ -625,7 +631,7 @@ func cleanupDuplicatePhelpsIDs(db Database, language string) error {
 
 	// Get all matched prayers for this language
 	var matchedPrayers []Writing
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Language == language && w.Phelps != "" && w.Text != "" {
 			matchedPrayers = append(matchedPrayers, w)
 		}
@@ -650,24 +656,44 @@ func cleanupDuplicatePhelpsIDs(db Database, language string) error {
 			continue // No duplicates in this group
 		}
 
+		// Near-identical texts are the same prayer stored twice: they all keep the code
+		clusters := clusterNearDuplicates(group)
+		if len(clusters) == 1 {
+			log.Printf("   📎 %d near-identical texts share Phelps ID %s - merge candidates, keeping all", len(group), phelps)
+			continue
+		}
+
 		duplicatesFound += len(group) - 1
-		log.Printf("   🔍 Found %d duplicates for Phelps ID %s", len(group), phelps)
+		log.Printf("   🔍 Found %d duplicates for Phelps ID %s (%d distinct texts)", len(group), phelps, len(clusters))
 
-		// Find the best match using similarity scoring
-		bestMatch := findBestMatchInGroup(db, group, phelps)
+		// Find the best match among one representative per distinct text
+		representatives := make([]Writing, len(clusters))
+		for i, cluster := range clusters {
+			representatives[i] = cluster[0]
+		}
+		bestMatch := findBestMatchInGroup(db, representatives, phelps)
 		if bestMatch == nil {
 			log.Printf("   ⚠️  Could not determine best match for Phelps %s", phelps)
 			continue
 		}
 
-		// Clear Phelps codes from all except the best match
+		keep := make(map[string]bool)
+		for _, cluster := range clusters {
+			if cluster[0].Version == bestMatch.Version {
+				for _, prayer := range cluster {
+					keep[prayer.Version] = true
+				}
+			}
+		}
+
+		// Clear Phelps codes from all except the best match and its near-duplicates
 		for _, prayer := range group {
-			if prayer.Version != bestMatch.Version {
+			if !keep[prayer.Version] {
 				// Clear the Phelps code for inferior matches
-				err := clearPhelpsCode(prayer.Version)
+				cleared, err := clearPhelpsCode(prayer.Version, bestMatch.Version)
 				if err != nil {
 					log.Printf("   ❌ Failed to clear Phelps for %s: %v", prayer.Version, err)
-				} else {
+				} else if cleared {
 					log.Printf("   🧹 Cleared Phelps %s from %s (keeping %s)", phelps, prayer.Version, bestMatch.Version)
 					duplicatesFixed++
 				}
@@ -688,7 +714,7 @@ func cleanupDuplicatePhelpsIDs(db Database, language string) error {
 func findBestMatchInGroup(db Database, duplicates []Writing, phelps string) *Writing {
 	// Get the English reference for this Phelps ID
 	var englishRef *Writing
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Language == "en" && w.Phelps == phelps && w.Text != "" {
 			englishRef = &w
 			break
@@ -728,14 +754,14 @@ func llmResolveDuplicateMatch(englishRef Writing, duplicates []Writing) (*Writin
 	prompt.WriteString("# ENGLISH REFERENCE PRAYER\n")
 	prompt.WriteString(fmt.Sprintf("**Phelps Code**: %s\n", englishRef.Phelps))
 	prompt.WriteString(fmt.Sprintf("**Name**: %s\n", englishRef.Name))
-	prompt.WriteString(fmt.Sprintf("**Text**: %s\n\n", englishRef.Text))
+	prompt.WriteString(fmt.Sprintf("**Text**: %s\n\n", cleanWritingText(englishRef)))
 
 	prompt.WriteString(fmt.Sprintf("# DUPLICATE %s PRAYERS\n", strings.ToUpper(language)))
 	for i, dup := range duplicates {
 		prompt.WriteString(fmt.Sprintf("## Option %d\n", i+1))
 		prompt.WriteString(fmt.Sprintf("**Version**: %s\n", dup.Version))
 		prompt.WriteString(fmt.Sprintf("**Name**: %s\n", dup.Name))
-		prompt.WriteString(fmt.Sprintf("**Text**: %s\n\n", dup.Text))
+		prompt.WriteString(fmt.Sprintf("**Text**: %s\n\n", cleanWritingText(dup)))
 	}
 
 	prompt.WriteString("# INSTRUCTIONS\n")
@@ -832,6 +858,7 @@ func callLLMWithBackendFallback(prompt string, context string, stopOnRateLimit b
 
 		response, err := backend.call(prompt)
 		if err == nil {
+			lastLLMBackend = backend.name
 			if context != "" {
 				log.Printf("✅ Success with %s for %s", backend.name, context)
 			} else {
@@ -861,11 +888,11 @@ func callLLMWithFallback(prompt string) (string, error) {
 	return callLLMWithBackendFallback(prompt, "duplicate resolution", false)
 }
 
-// clearPhelpsCode removes the Phelps code from a specific prayer version
-func clearPhelpsCode(version string) error {
-	query := fmt.Sprintf("UPDATE writings SET phelps = '' WHERE version = '%s'", version)
-	_, err := execDoltQuery(query)
-	return err
+// clearPhelpsCode removes the Phelps code from a specific prayer version; reports whether
+// the protection policy allowed it
+func clearPhelpsCode(version, keep string) (bool, error) {
+	change := ProposedChange{Version: version, MatchType: "DUPLICATE", Reasons: []string{"duplicate of " + keep}, Source: "duplicate cleanup"}
+	return writePhelps(change, false)
 }
 
 // calculateMistakeCorrectionSample selects prayers for re-evaluation, prioritizing error indicators
@@ -979,7 +1006,7 @@ func findDuplicatePhelpsIDs(prayers []TargetPrayer) []TargetPrayer {
 
 	// Create a map of Version -> Phelps for matched prayers
 	versionToPhelps := make(map[string]string)
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Phelps != "" {
 			versionToPhelps[w.Version] = w.Phelps
 		}
@@ -1078,17 +1105,29 @@ func findLengthMismatches(matchedPrayers []TargetPrayer) []TargetPrayer {
 
 	// Create map of Version -> Phelps for matched prayers
 	versionToPhelps := make(map[string]string)
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Phelps != "" {
 			versionToPhelps[w.Version] = w.Phelps
 		}
 	}
 
-	// Create map of Phelps -> English text length
+	// Create map of Phelps -> English text length, plus passage lengths for long Tablets
 	phelpsToLength := make(map[string]int)
-	for _, w := range db.Writings {
+	phelpsToPassages := make(map[string][]int)
+	for _, w := range db.Writing {
 		if w.Language == "en" && w.Phelps != "" {
-			phelpsToLength[w.Phelps] = len(w.Text)
+			text := cleanWritingText(w)
+			phelpsToLength[w.Phelps] = len(text)
+			for _, passage := range SegmentPassages(text, "en") {
+				phelpsToPassages[w.Phelps] = append(phelpsToPassages[w.Phelps], len(passage))
+			}
+		}
+	}
+
+	versionToNotes := make(map[string]string)
+	for _, w := range db.Writing {
+		if w.Notes != "" {
+			versionToNotes[w.Version] = w.Notes
 		}
 	}
 
@@ -1106,6 +1145,19 @@ func findLengthMismatches(matchedPrayers []TargetPrayer) []TargetPrayer {
 
 		targetLength := len(prayer.Text)
 
+		// Excerpts of long Tablets are compared with their passage, not the whole Tablet
+		if passages := phelpsToPassages[phelps]; len(passages) > 0 && targetLength < englishLength {
+			if notePhelps, n, ok := parseExcerptNote(versionToNotes[prayer.Version]); ok && notePhelps == phelps && n <= len(passages) {
+				englishLength = passages[n-1]
+			} else {
+				// Unrecorded excerpt: any single passage is a plausible source
+				englishLength = passages[0]
+				for _, length := range passages[1:] {
+					englishLength = min(englishLength, length)
+				}
+			}
+		}
+
 		// Calculate length ratio - flag if more than 2x or less than 0.5x
 		ratio := float64(targetLength) / float64(englishLength)
 
@@ -1128,7 +1180,7 @@ func findSimilarPrayerConfusion(matchedPrayers []TargetPrayer) []TargetPrayer {
 
 	// Create map of Version -> Phelps
 	versionToPhelps := make(map[string]string)
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Phelps != "" {
 			versionToPhelps[w.Version] = w.Phelps
 		}
@@ -1163,7 +1215,7 @@ func findSimilarPrayerConfusion(matchedPrayers []TargetPrayer) []TargetPrayer {
 
 		// Get English reference name for this Phelps
 		var englishName string
-		for _, w := range db.Writings {
+		for _, w := range db.Writing {
 			if w.Language == "en" && w.Phelps == phelps {
 				englishName = strings.ToLower(w.Name)
 				break
@@ -1214,7 +1266,7 @@ func findMissingEnglishReference(matchedPrayers []TargetPrayer) []TargetPrayer {
 
 	// Create map of Version -> Phelps
 	versionToPhelps := make(map[string]string)
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Phelps != "" {
 			versionToPhelps[w.Version] = w.Phelps
 		}
@@ -1222,7 +1274,7 @@ func findMissingEnglishReference(matchedPrayers []TargetPrayer) []TargetPrayer {
 
 	// Create set of valid English Phelps codes (including TMP codes in English)
 	validPhelps := make(map[string]bool)
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Language == "en" && w.Phelps != "" {
 			validPhelps[w.Phelps] = true
 		}
@@ -1371,38 +1423,37 @@ func ProcessMatchResults(db *Database, results BatchMatchResponse, targetLang st
 	for _, match := range results.Matches {
 		fmt.Fprintf(reportFile, "\n--- Processing: %s ---\n", match.Phelps)
 		fmt.Fprintf(reportFile, "  Type: %s\n", match.MatchType)
-		fmt.Fprintf(reportFile, "  Confidence: %d%%\n", match.Confidence)
+		fmt.Fprintf(reportFile, "  Confidence: %.0f%%\n", match.Confidence)
 		fmt.Fprintf(reportFile, "  Reasoning: %s\n", match.Reasoning)
 
 		switch match.MatchType {
 		case "EXISTING":
 			// Update existing prayer with Phelps code
-			query := fmt.Sprintf("UPDATE writings SET phelps = '%s' WHERE version = '%s' AND language = '%s'",
-				strings.ReplaceAll(match.Phelps, "'", "''"),
-				strings.ReplaceAll(match.TargetVersion, "'", "''"),
-				targetLang)
-
-			if _, err := execDoltQuery(query); err != nil {
+			change := ProposedChange{Version: match.TargetVersion, Language: targetLang, NewPhelps: match.Phelps,
+				Confidence: match.Confidence, MatchType: match.MatchType, Reasons: []string{match.Reasoning}, Source: "structured"}
+			written, err := writePhelps(change, false)
+			if err != nil {
 				fmt.Fprintf(reportFile, "  ERROR: Failed to update: %v\n", err)
 				continue
 			}
+			if !written {
+				continue
+			}
 			matched++
 			fmt.Fprintf(reportFile, "  ✅ Updated %s -> %s\n", match.TargetVersion, match.Phelps)
 
 		case "NEW_TRANSLATION":
 			// Insert new translation
-			version := fmt.Sprintf("%s_llm_%s", targetLang, match.Phelps)
-			query := fmt.Sprintf(`INSERT INTO writings (phelps, language, version, name, text, source, is_verified)
-				VALUES ('%s', '%s', '%s', 'LLM Translation', '%s', 'LLM_TRANSLATION', false)`,
-				strings.ReplaceAll(match.Phelps, "'", "''"),
-				targetLang,
-				strings.ReplaceAll(version, "'", "''"),
-				strings.ReplaceAll(match.TranslatedText, "'", "''"))
-
-			if _, err := execDoltQuery(query); err != nil {
+			change := ProposedChange{Version: fmt.Sprintf("%s_llm_%s", targetLang, match.Phelps), Language: targetLang, NewPhelps: match.Phelps,
+				Text: match.TranslatedText, Confidence: match.Confidence, MatchType: match.MatchType, Reasons: []string{match.Reasoning}, Source: "structured"}
+			written, err := writePhelps(change, false)
+			if err != nil {
 				fmt.Fprintf(reportFile, "  ERROR: Failed to insert: %v\n", err)
 				continue
 			}
+			if !written {
+				continue
+			}
 			translated++
 			fmt.Fprintf(reportFile, "  ✅ Created new translation for %s\n", match.Phelps)
 
@@ -1584,9 +1635,44 @@ Please fix the JSON below and return ONLY the corrected JSON with no explanation
 // --- Main ---
 
 func main() {
+	if err := run(); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+}
+
+// run routes to the selected mode; its deferred changeset and progress snapshot run
+// before main exits, also when the mode fails
+func run() error {
+	// Subcommands working on the review queue have their own flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "consolidate":
+			if err := ConsolidateCommand(os.Args[2:]); err != nil {
+				return fmt.Errorf("Consolidate failed: %w", err)
+			}
+			return nil
+		case "export-sql":
+			if err := ExportSQLCommand(os.Args[2:]); err != nil {
+				return fmt.Errorf("SQL export failed: %w", err)
+			}
+			return nil
+		case "export":
+			if err := ExportLinksCommand(os.Args[2:]); err != nil {
+				return fmt.Errorf("Export failed: %w", err)
+			}
+			return nil
+		case "coverage":
+			if err := CoverageCommand(os.Args[2:]); err != nil {
+				return fmt.Errorf("Coverage report failed: %w", err)
+			}
+			return nil
+		}
+	}
+
 	targetLanguage := flag.String("language", "", "Target language code (e.g., es, pt, fr)")
 	reportPath := flag.String("report", "matching_report.txt", "Path for the report file")
-	dryRun := flag.Bool("dry-run", false, "Don't update database, just show what would happen")
+	dryRun := flag.Bool("dry-run", false, "Don't update database; save the proposed changes as changeset_<timestamp>.json and .sql (every mode but -serve-review)")
+	applyChangesetFlag := flag.String("apply-changeset", "", "Apply a changeset saved by -dry-run, skipping prayers whose code changed since")
 	useCLIFlag := flag.Bool("cli", false, "Use claude CLI instead of API (works with Claude Pro)")
 	useGeminiFlag := flag.Bool("gemini", false, "Use Gemini CLI as fallback when Claude hits rate limits")
 	useGptOssFlag := flag.Bool("gpt-oss", false, "Use ollama as local fallback (no rate limits)")
@@ -1596,20 +1682,38 @@ func main() {
 	useStatusCheckFlag := flag.Bool("status", false, "Check database status and processing recommendations")
 	useRetryBatchesFlag := flag.Bool("retry", false, "Retry processing saved batch files")
 	csvFileFlag := flag.String("csv", "", "Process issues from CSV file (specify filename or leave empty for writings_issues.csv)")
+	evaluateFlag := flag.Bool("evaluate", false, "Measure precision/recall against human-verified matches without writing (compressed matching only, with or without -use-tmp-fallback; not -ultra or the default LLM mode)")
+	goldFlag := flag.String("gold", "", "Gold set CSV with version,phelps columns for -evaluate (default: verified writings)")
+	calibrateFlag := flag.Bool("calibrate", false, "Fit confidence calibration from review decisions and -evaluate reports (writes calibration.json)")
+	targetPrecisionFlag := flag.Float64("target-precision", targetPrecision, "Calibrated precision (0-1) a match needs to be applied without review")
+	progressFlag := flag.Bool("progress", false, "Show matching progress over recorded runs (terminal sparklines and progress.html); -language limits it to one language")
+	normalizePhelpsFlag := flag.Bool("normalize-phelps", false, "Store NULL for blank Phelps codes so every status count agrees (respects -dry-run)")
+	applyReviewsFlag := flag.Bool("apply-reviews", false, "Apply reviewer decisions from edited review files given as arguments and from review_queue.jsonl")
+	serveFlag := flag.String("serve", "", "Serve a read-only JSON API over the database on the given address (e.g. -serve=localhost:8080)")
+	serveReviewFlag := flag.String("serve-review", "", "Start the review web UI on the given address (e.g. -serve-review=localhost:8765)")
 	resolveAmbiguousFlag := flag.Bool("resolve-ambiguous", false, "Phase 2: Resolve ambiguous matches using full-text matching")
 	skipProcessedFlag := flag.Bool("skip-processed", true, "Skip languages with existing review files (disable with -skip-processed=false)")
 	reverseFlag := flag.Bool("reverse", false, "Process languages from smallest to largest (start with rare/small languages)")
 	heuristicFlag := flag.Bool("heuristic", false, "Use heuristic pre-sorting to prioritize likely matches first")
 	initTMPCodesFlag := flag.Bool("init-tmp", false, "Initialize TMP codes for unmatched en/ar/fa prayers")
 	useTMPFallbackFlag := flag.Bool("use-tmp-fallback", false, "Enable three-tier matching: en -> ar -> fa -> new TMP")
+	cleaningReportFlag := flag.Bool("cleaning-report", false, "Report footnotes, headings and publication notes stripped before fingerprinting (optionally filtered by -language)")
+	secondOpinionFlag := flag.Bool("second-opinion", true, "Get a second LLM opinion on LIKELY (80-95%) and AMBIGUOUS matches before writing (one extra LLM call per such match; disable with -second-opinion=false)")
+	searchNotesFlag := flag.String("search-notes", "", "Search notes recorded by earlier matching sessions (optionally filtered by -language)")
+	findDuplicatesFlag := flag.Bool("find-duplicates", false, "Report near-identical prayers within a language and across same-script languages (optionally filtered by -language)")
 	flag.Parse()
 
+	// Give this run a session ID so its notes and progress snapshots can be told apart
+	// from earlier runs
+	startSession()
+
 	// Check if no arguments were provided - show interactive menu
 	if len(os.Args) == 1 {
+		initializeSession()
 		if err := ShowMainMenu(); err != nil {
-			log.Fatalf("Interactive menu failed: %v", err)
+			return fmt.Errorf("Interactive menu failed: %w", err)
 		}
-		return
+		return nil
 	}
 
 	useCLI = *useCLIFlag
@@ -1621,6 +1725,12 @@ func main() {
 	useStatusCheck = *useStatusCheckFlag
 	useRetryBatches = *useRetryBatchesFlag
 	useCsvProcessing = (*csvFileFlag != "")
+	useSecondOpinion = *secondOpinionFlag
+	targetPrecision = *targetPrecisionFlag
+	dryRunMode = *dryRun
+	if dryRunMode {
+		defer SaveChangeset()
+	}
 
 	// Set the CSV file if specified
 	if useCsvProcessing && *csvFileFlag != "" {
@@ -1638,10 +1748,99 @@ func main() {
 	if initTMPCodes {
 		log.Println("🏷️  Initializing TMP codes for unmatched en/ar/fa prayers...")
 		if err := AssignTMPCodes(); err != nil {
-			log.Fatalf("TMP code initialization failed: %v", err)
+			return fmt.Errorf("TMP code initialization failed: %w", err)
 		}
 		log.Println("✅ TMP code initialization completed successfully!")
-		return
+		if !dryRunMode {
+			RecordProgressSnapshot()
+		}
+		return nil
+	}
+
+	// Route to text cleaning report if requested (no LLM needed)
+	if *cleaningReportFlag {
+		if err := TextCleaningReportCommand(*targetLanguage); err != nil {
+			return fmt.Errorf("Text cleaning report failed: %w", err)
+		}
+		return nil
+	}
+
+	// Route to near-duplicate report if requested (no LLM needed)
+	if *findDuplicatesFlag {
+		if err := FindNearDuplicatesCommand(*targetLanguage); err != nil {
+			return fmt.Errorf("Near-duplicate detection failed: %w", err)
+		}
+		return nil
+	}
+
+	// Route to confidence calibration if requested (no LLM needed)
+	if *calibrateFlag {
+		if err := CalibrateCommand(); err != nil {
+			return fmt.Errorf("Calibration failed: %w", err)
+		}
+		return nil
+	}
+
+	// Route to the progress report if requested (no LLM needed)
+	if *progressFlag {
+		if err := ProgressCommand(*targetLanguage); err != nil {
+			return fmt.Errorf("Progress report failed: %w", err)
+		}
+		return nil
+	}
+
+	// Route to Phelps code normalization if requested (no LLM needed)
+	if *normalizePhelpsFlag {
+		if err := NormalizePhelpsCommand(*dryRun); err != nil {
+			return fmt.Errorf("Normalizing Phelps codes failed: %w", err)
+		}
+		return nil
+	}
+
+	// Route to review decisions if requested (no LLM needed)
+	if *applyReviewsFlag {
+		if err := ApplyReviewsCommand(flag.Args()); err != nil {
+			return fmt.Errorf("Applying review decisions failed: %w", err)
+		}
+		if !dryRunMode {
+			RecordProgressSnapshot()
+		}
+		return nil
+	}
+
+	// Route to applying a dry run's changeset if requested (no LLM needed)
+	if *applyChangesetFlag != "" {
+		if err := ApplyChangesetCommand(*applyChangesetFlag); err != nil {
+			return fmt.Errorf("Applying changeset failed: %w", err)
+		}
+		if !dryRunMode {
+			RecordProgressSnapshot()
+		}
+		return nil
+	}
+
+	// Route to the read-only API if requested (no LLM needed)
+	if *serveFlag != "" {
+		if err := ServeAPICommand(*serveFlag); err != nil {
+			return fmt.Errorf("API server failed: %w", err)
+		}
+		return nil
+	}
+
+	// Route to the review web UI if requested (no LLM needed)
+	if *serveReviewFlag != "" {
+		if err := ServeReviewCommand(*serveReviewFlag); err != nil {
+			return fmt.Errorf("Review UI failed: %w", err)
+		}
+		return nil
+	}
+
+	if *searchNotesFlag != "" {
+		initializeSession()
+		if err := SearchNotesCommand(*searchNotesFlag, *targetLanguage); err != nil {
+			return fmt.Errorf("Note search failed: %w", err)
+		}
+		return nil
 	}
 
 	// Skip API key check for status-only commands and CSV processing
@@ -1650,7 +1849,7 @@ func main() {
 		if !useCLI && !useGemini && !useGptOss {
 			claudeAPIKey = os.Getenv("CLAUDE_API_KEY")
 			if claudeAPIKey == "" {
-				log.Fatal("CLAUDE_API_KEY environment variable must be set (or use -cli/-gemini/-gpt-oss flag)")
+				return fmt.Errorf("CLAUDE_API_KEY environment variable must be set (or use -cli/-gemini/-gpt-oss flag)")
 			}
 		}
 	}
@@ -1666,17 +1865,39 @@ func main() {
 	// Check Gemini requirements
 	if useGemini {
 		if _, err := exec.LookPath("gemini"); err != nil {
-			log.Fatal("gemini CLI not found. Please install it first and authenticate: gemini auth login")
+			return fmt.Errorf("gemini CLI not found. Please install it first and authenticate: gemini auth login")
 		}
 	}
 
 	// Check ollama requirements
 	if useGptOss {
 		if _, err := exec.LookPath("ollama"); err != nil {
-			log.Fatal("ollama CLI not found. Please install it first: https://ollama.com/")
+			return fmt.Errorf("ollama CLI not found. Please install it first: https://ollama.com/")
 		}
 	}
 
+	// Route to the gold-standard evaluation if requested (dry run, never writes)
+	if *evaluateFlag {
+		mode := EvaluateCompressed
+		if useTMPFallback {
+			mode = EvaluateTMPFallback
+		}
+		if err := EvaluateCommand(*goldFlag, mode, *targetLanguage); err != nil {
+			return fmt.Errorf("Evaluation failed: %w", err)
+		}
+		return nil
+	}
+
+	// Every run from here on can change matches, so record where it left the database
+	if !useStatusCheck && !dryRunMode {
+		defer RecordProgressSnapshot()
+	}
+
+	// LLM matching from here on records notes for later sessions
+	if !useStatusCheck {
+		initializeSession()
+	}
+
 	// Route to smart fallback if requested
 	if useSmartFallback {
 		if *targetLanguage != "" {
@@ -1684,44 +1905,44 @@ func main() {
 		}
 		log.Printf("Starting SMART FALLBACK processing (Claude→Gemini→ollama)")
 		if err := SmartFallbackProcessing(); err != nil {
-			log.Fatalf("Smart fallback processing failed: %v", err)
+			return fmt.Errorf("Smart fallback processing failed: %w", err)
 		}
 		log.Println("Smart fallback processing completed successfully!")
-		return
+		return nil
 	}
 
 	// Route to status check if requested
 	if useStatusCheck {
 		if err := StatusCheckCommand(); err != nil {
-			log.Fatalf("Status check failed: %v", err)
+			return fmt.Errorf("Status check failed: %w", err)
 		}
-		return
+		return nil
 	}
 
 	// Route to retry batches if requested
 	if useRetryBatches {
 		if err := RetryBatchesCommand(); err != nil {
-			log.Fatalf("❌ Retry batches failed: %v", err)
+			return fmt.Errorf("Retry batches failed: %w", err)
 		}
-		return
+		return nil
 	}
 
 	if useCsvProcessing {
 		if err := processCsvIssues(); err != nil {
-			log.Fatalf("❌ CSV processing failed: %v", err)
+			return fmt.Errorf("CSV processing failed: %w", err)
 		}
-		return
+		return nil
 	}
 
 	// Route to resolve ambiguous if requested
 	if resolveAmbiguous {
 		if *targetLanguage == "" {
-			log.Fatal("Error: -language flag is required for -resolve-ambiguous (e.g., -language=fa)")
+			return fmt.Errorf("-language flag is required for -resolve-ambiguous (e.g., -language=fa)")
 		}
 		if err := ResolveAmbiguousMatches(*targetLanguage); err != nil {
-			log.Fatalf("Resolve ambiguous matches failed: %v", err)
+			return fmt.Errorf("Resolve ambiguous matches failed: %w", err)
 		}
-		return
+		return nil
 	}
 
 	// Route to ultra-compressed matching if requested (processes ALL languages)
@@ -1737,14 +1958,14 @@ func main() {
 			log.Printf("Starting ULTRA-COMPRESSED multi-language batch matching")
 		}
 		if err := UltraCompressedBulkMatchingWithSkip(skipProcessed, reverse, heuristic); err != nil {
-			log.Fatalf("Ultra-compressed matching failed: %v", err)
+			return fmt.Errorf("Ultra-compressed matching failed: %w", err)
 		}
 		log.Println("Ultra-compressed matching completed successfully!")
-		return
+		return nil
 	}
 
 	if *targetLanguage == "" {
-		log.Fatal("Error: -language flag is required (e.g., -language=es)")
+		return fmt.Errorf("-language flag is required (e.g., -language=es)")
 	}
 
 	// Route to compressed matching if requested
@@ -1752,10 +1973,10 @@ func main() {
 		if useTMPFallback {
 			log.Printf("Starting COMPRESSED matching with TMP FALLBACK for language: %s", *targetLanguage)
 			if err := CompressedLanguageMatchingWithTMPFallback(*targetLanguage); err != nil {
-				log.Fatalf("Compressed TMP fallback matching failed: %v", err)
+				return fmt.Errorf("Compressed TMP fallback matching failed: %w", err)
 			}
 			log.Println("Compressed TMP fallback matching completed successfully!")
-			return
+			return nil
 		}
 
 		if useGptOss {
@@ -1766,10 +1987,10 @@ func main() {
 			log.Printf("Starting COMPRESSED matching for language: %s", *targetLanguage)
 		}
 		if err := CompressedLanguageMatching(*targetLanguage); err != nil {
-			log.Fatalf("Compressed matching failed: %v", err)
+			return fmt.Errorf("Compressed matching failed: %w", err)
 		}
 		log.Println("Compressed matching completed successfully!")
-		return
+		return nil
 	}
 
 	log.Printf("Starting structured matching for language: %s", *targetLanguage)
@@ -1777,9 +1998,9 @@ func main() {
 	// Load database
 	db, err := GetDatabase()
 	if err != nil {
-		log.Fatalf("Failed to load database: %v", err)
+		return fmt.Errorf("Failed to load database: %w", err)
 	}
-	log.Printf("Database loaded: %d writings, %d languages", len(db.Writings), len(db.Languages))
+	log.Printf("Database loaded: %d writings, %d languages", len(db.Writing), len(db.Languages))
 
 	// Build reference collections
 	englishRefs := BuildEnglishReference(db)
@@ -1788,13 +2009,13 @@ func main() {
 	log.Printf("Target %s prayers: %d prayers", *targetLanguage, len(targetPrayers))
 
 	if len(englishRefs) == 0 {
-		log.Fatal("No English reference prayers found")
+		return fmt.Errorf("No English reference prayers found")
 	}
 
 	// Create report file
 	reportFile, err := os.Create(*reportPath)
 	if err != nil {
-		log.Fatalf("Failed to create report file: %v", err)
+		return fmt.Errorf("Failed to create report file: %w", err)
 	}
 	defer reportFile.Close()
 
@@ -1833,7 +2054,7 @@ func main() {
 		log.Printf("Calling LLM for chunk %d/%d...", chunkIdx+1, totalChunks)
 		response, err := callLLMWithBackendFallback(prompt, fmt.Sprintf("chunk %d/%d", chunkIdx+1, totalChunks), true)
 		if err != nil {
-			log.Fatalf("LLM call failed on chunk %d: %v", chunkIdx+1, err)
+			return fmt.Errorf("LLM call failed on chunk %d: %w", chunkIdx+1, err)
 		}
 
 		fmt.Fprintf(reportFile, "Claude response received (%d chars)\n", len(response))
@@ -1874,33 +2095,28 @@ func main() {
 		Summary: fmt.Sprintf("Processed %d chunks, %d total matches", totalChunks, len(allMatches)),
 	}
 
-	if !*dryRun {
-		// Process and apply results
-		if err := ProcessMatchResults(&db, combinedResults, *targetLanguage, reportFile); err != nil {
-			log.Fatalf("Failed to process results: %v", err)
-		}
+	// Process and apply results; a dry run only proposes them
+	if err := ProcessMatchResults(&db, combinedResults, *targetLanguage, reportFile); err != nil {
+		return fmt.Errorf("Failed to process results: %w", err)
+	}
 
+	if !*dryRun {
 		// Commit to Dolt
 		commitMsg := fmt.Sprintf("Structured matching for %s: %s", *targetLanguage, combinedResults.Summary)
-		cmd := execDoltCommand("add", ".")
-		if output, err := cmd.CombinedOutput(); err != nil {
-			log.Printf("WARNING: Failed to stage changes: %v: %s", err, string(output))
+		if err := doltCommit(commitMsg); err != nil {
+			log.Printf("WARNING: %v", err)
 		} else {
-			cmd = execDoltCommand("commit", "-m", commitMsg)
-			if output, err := cmd.CombinedOutput(); err != nil {
-				log.Printf("WARNING: Failed to commit: %v: %s", err, string(output))
-			} else {
-				log.Printf("✅ Committed to Dolt: %s", commitMsg)
-				fmt.Fprintf(reportFile, "\n✅ Committed to Dolt: %s\n", commitMsg)
-			}
+			log.Printf("✅ Committed to Dolt: %s", commitMsg)
+			fmt.Fprintf(reportFile, "\n✅ Committed to Dolt: %s\n", commitMsg)
 		}
 	} else {
-		fmt.Fprintf(reportFile, "\n⚠️  DRY RUN - No changes made to database\n")
+		fmt.Fprintf(reportFile, "\n⚠️  DRY RUN - No changes made to database, proposed changes saved as a changeset\n")
 		log.Println("Dry run completed - no changes made")
 	}
 
 	fmt.Fprintf(reportFile, "\nCompleted: %s\n", time.Now().Format(time.RFC3339))
 	log.Printf("Report saved to: %s", *reportPath)
+	return nil
 }
 
 // --- Smart Fallback Processing ---
@@ -2052,6 +2268,48 @@ func ProcessSavedBatches(backend Backend) error {
 	return nil
 }
 
+// --- Text Cleaning Report ---
+
+// TextCleaningReportCommand reports the footnotes, headings and publication notes
+// that are stripped from prayer texts before fingerprinting
+func TextCleaningReportCommand(language string) error {
+	db, err := GetDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load database: %w", err)
+	}
+
+	var entries []TextCleaningEntry
+	scanned := 0
+	for _, w := range db.Writing {
+		if w.Text == "" || (language != "" && w.Language != language) {
+			continue
+		}
+		scanned++
+		if _, removed := CleanPrayerText(w.Text, w.Source); len(removed) > 0 {
+			entries = append(entries, TextCleaningEntry{
+				Version:  w.Version,
+				Language: w.Language,
+				Source:   w.Source,
+				Removed:  removed,
+			})
+		}
+	}
+
+	reportFile := fmt.Sprintf("text_cleaning_report_%s.txt", time.Now().Format("20060102_150405"))
+	file, err := os.Create(reportFile)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer file.Close()
+
+	fmt.Fprintf(file, "TEXT CLEANING REPORT - %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	writeTextCleaningReport(file, entries, scanned)
+
+	log.Printf("🧹 %d of %d writings have editorial material removed before fingerprinting", len(entries), scanned)
+	log.Printf("📝 Report written to: %s", reportFile)
+	return nil
+}
+
 // --- Status Check Command ---
 
 func StatusCheckCommand() error {
@@ -2111,12 +2369,12 @@ func GetProcessingStatus() (*ProcessingStatus, error) {
 	}
 
 	// Get matched prayers
-	if result, err := execDoltQueryCSV("SELECT COUNT(*) FROM writings WHERE phelps IS NOT NULL"); err == nil && len(result) > 1 {
+	if result, err := execDoltQueryCSV("SELECT COUNT(*) FROM writings WHERE " + matchedSQL("phelps")); err == nil && len(result) > 1 {
 		fmt.Sscanf(result[1][0], "%d", &status.MatchedPrayers)
 	}
 
 	// Get unmatched prayers
-	if result, err := execDoltQueryCSV("SELECT COUNT(*) FROM writings WHERE phelps IS NULL"); err == nil && len(result) > 1 {
+	if result, err := execDoltQueryCSV("SELECT COUNT(*) FROM writings WHERE " + unmatchedSQL("phelps")); err == nil && len(result) > 1 {
 		fmt.Sscanf(result[1][0], "%d", &status.UnmatchedPrayers)
 	}
 
@@ -2126,7 +2384,7 @@ func GetProcessingStatus() (*ProcessingStatus, error) {
 	}
 
 	// Get unprocessed languages count
-	if result, err := execDoltQueryCSV("SELECT COUNT(DISTINCT language) FROM writings WHERE language != 'en' AND phelps IS NULL AND language NOT LIKE '%-translit'"); err == nil && len(result) > 1 {
+	if result, err := execDoltQueryCSV("SELECT COUNT(DISTINCT language) FROM writings WHERE language != 'en' AND " + unmatchedSQL("phelps") + " AND language NOT LIKE '%-translit'"); err == nil && len(result) > 1 {
 		fmt.Sscanf(result[1][0], "%d", &status.UnprocessedLangs)
 	}
 
@@ -2140,7 +2398,7 @@ func GetEnglishStatus() (*ProcessingStatus, error) {
 		fmt.Sscanf(result[1][0], "%d", &status.TotalPrayers)
 	}
 
-	if result, err := execDoltQueryCSV("SELECT COUNT(*) FROM writings WHERE language = 'en' AND phelps IS NOT NULL"); err == nil && len(result) > 1 {
+	if result, err := execDoltQueryCSV("SELECT COUNT(*) FROM writings WHERE language = 'en' AND " + matchedSQL("phelps")); err == nil && len(result) > 1 {
 		fmt.Sscanf(result[1][0], "%d", &status.MatchedPrayers)
 	}
 
@@ -2155,18 +2413,18 @@ func ShowTopLanguages() error {
 	log.Printf("📈 Top 20 Languages by Prayer Count:")
 	log.Printf("------------------------------------")
 
-	result, err := execDoltQueryCSV(`
+	result, err := execDoltQueryCSV(fmt.Sprintf(`
 		SELECT
 			language,
 			COUNT(*) as total_prayers,
-			COUNT(phelps) as matched_prayers,
-			ROUND(COUNT(phelps) * 100.0 / COUNT(*), 1) as match_percent
+			%[1]s as matched_prayers,
+			ROUND(%[1]s * 100.0 / COUNT(*), 1) as match_percent
 		FROM writings
 		GROUP BY language
 		HAVING COUNT(*) > 0
 		ORDER BY total_prayers DESC
 		LIMIT 20
-	`)
+	`, countMatchedSQL()))
 
 	if err != nil {
 		return err
@@ -2195,14 +2453,14 @@ func ShowUnprocessedLanguages() error {
 	log.Printf("🚨 Unprocessed Languages (need matching):")
 	log.Printf("-----------------------------------------")
 
-	result, err := execDoltQueryCSV(`
+	result, err := execDoltQueryCSV(fmt.Sprintf(`
 		SELECT language, COUNT(*) as prayer_count
 		FROM writings
-		WHERE language != 'en' AND phelps IS NULL AND language NOT LIKE '%-translit'
+		WHERE language != 'en' AND %s AND language NOT LIKE '%%-translit'
 		GROUP BY language
 		HAVING COUNT(*) > 0
 		ORDER BY prayer_count DESC
-	`)
+	`, unmatchedSQL("phelps")))
 
 	if err != nil {
 		return err
@@ -2410,8 +2668,10 @@ func ProcessFailedResponses(files []string) (int, int) {
 			continue
 		}
 
-		// If successful, delete the failed response file
-		if err := os.Remove(file); err != nil {
+		// If successful, delete the failed response file; a dry run keeps it for the real run
+		if dryRunMode {
+			log.Printf("🔍 Dry run - keeping %s", file)
+		} else if err := os.Remove(file); err != nil {
 			log.Printf("⚠️ Could not delete processed file %s: %v", file, err)
 		} else {
 			log.Printf("🗑️ Deleted processed file: %s", file)
@@ -2424,7 +2684,8 @@ func ProcessFailedResponses(files []string) (int, int) {
 	return processed, failed
 }
 
-// generateReviewFiles creates detailed output files for matches needing human review
+// generateReviewFiles adds matches needing human review to the review queue and
+// renders the new items as review files
 func generateReviewFiles(language string, matches []CompressedMatchResult) error {
 	timestamp := time.Now().Format("20060102_150405")
 
@@ -2435,34 +2696,52 @@ func generateReviewFiles(language string, matches []CompressedMatchResult) error
 	var unmatched []CompressedMatchResult
 
 	for _, match := range matches {
+		autoApply := shouldAutoApply(match.Backend, match.MatchType, match.Confidence, match.MatchReasons)
 		switch {
 		case match.MatchType == "AMBIGUOUS":
 			ambiguous = append(ambiguous, match)
-		case match.Confidence < 70:
+		case !autoApply:
 			lowConfidence = append(lowConfidence, match)
-		case match.Confidence >= 70:
+		case autoApply:
 			highConfidence = append(highConfidence, match)
 		case match.MatchType == "NEW_TRANSLATION":
 			unmatched = append(unmatched, match)
 		}
 	}
 
+	// A dry run only reports what it would queue; review and summary files would mark
+	// the language as processed
+	if dryRunMode {
+		log.Printf("🔍 Dry run - %d ambiguous and %d low confidence matches would be queued for review", len(ambiguous), len(lowConfidence))
+		return nil
+	}
+
+	items, err := openReviewQueue()
+	if err != nil {
+		return fmt.Errorf("failed to load review queue: %w", err)
+	}
+	items, ambiguousItems := EnqueueReviewItems(items, language, ReviewAmbiguous, reviewProposals(ambiguous))
+	items, lowConfidenceItems := EnqueueReviewItems(items, language, ReviewLowConfidence, reviewProposals(lowConfidence))
+	if err := SaveReviewQueue(reviewQueueFile, items); err != nil {
+		return fmt.Errorf("failed to save review queue: %w", err)
+	}
+
 	// Generate ambiguous matches file
-	if len(ambiguous) > 0 {
+	if len(ambiguousItems) > 0 {
 		filename := fmt.Sprintf("review_ambiguous_%s_%s.txt", language, timestamp)
-		if err := writeReviewFile(filename, "AMBIGUOUS MATCHES", language, ambiguous); err != nil {
+		if err := writeReviewFile(filename, "AMBIGUOUS MATCHES", language, ambiguousItems); err != nil {
 			return err
 		}
-		log.Printf("📝 Created ambiguous matches file: %s (%d items)", filename, len(ambiguous))
+		log.Printf("📝 Created ambiguous matches file: %s (%d items)", filename, len(ambiguousItems))
 	}
 
 	// Generate low confidence matches file
-	if len(lowConfidence) > 0 {
+	if len(lowConfidenceItems) > 0 {
 		filename := fmt.Sprintf("review_low_confidence_%s_%s.txt", language, timestamp)
-		if err := writeReviewFile(filename, "LOW CONFIDENCE MATCHES", language, lowConfidence); err != nil {
+		if err := writeReviewFile(filename, "LOW CONFIDENCE MATCHES", language, lowConfidenceItems); err != nil {
 			return err
 		}
-		log.Printf("📝 Created low confidence file: %s (%d items)", filename, len(lowConfidence))
+		log.Printf("📝 Created low confidence file: %s (%d items)", filename, len(lowConfidenceItems))
 	}
 
 	// Generate summary file
@@ -2475,43 +2754,15 @@ func generateReviewFiles(language string, matches []CompressedMatchResult) error
 	return nil
 }
 
-// writeReviewFile creates a detailed review file for human inspection
-func writeReviewFile(filename, title, language string, matches []CompressedMatchResult) error {
+// writeReviewFile renders review queue items into a file for human inspection
+func writeReviewFile(filename, title, language string, items []ReviewItem) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	fmt.Fprintf(file, "%s - %s\n", title, strings.ToUpper(language))
-	fmt.Fprintf(file, "Generated: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	fmt.Fprintf(file, "Total items: %d\n\n", len(matches))
-	fmt.Fprintf(file, "INSTRUCTIONS:\n")
-	fmt.Fprintf(file, "- Review each match below\n")
-	fmt.Fprintf(file, "- Confidence ranges: 0.0 (no match) to 1.0 (perfect match)\n")
-	fmt.Fprintf(file, "- For ambiguous matches, check the suggested reasons\n")
-	fmt.Fprintf(file, "- Verify Phelps codes against English reference\n\n")
-	fmt.Fprintf(file, "═══════════════════════════════════════════════════════════════\n\n")
-
-	for i, match := range matches {
-		fmt.Fprintf(file, "[%d/%d] Target Prayer: %s\n", i+1, len(matches), match.TargetVersion)
-		fmt.Fprintf(file, "Suggested Phelps: %s\n", match.EnglishPhelps)
-		fmt.Fprintf(file, "Match Type: %s\n", match.MatchType)
-		fmt.Fprintf(file, "Confidence: %d%%\n", match.Confidence)
-
-		if len(match.MatchReasons) > 0 {
-			fmt.Fprintf(file, "Reasons: %s\n", strings.Join(match.MatchReasons, ", "))
-		}
-
-		if match.AmbiguityReason != "" {
-			fmt.Fprintf(file, "Ambiguity: %s\n", match.AmbiguityReason)
-		}
-
-		fmt.Fprintf(file, "Action needed: [ ] APPROVE [ ] REJECT [ ] MODIFY\n")
-		fmt.Fprintf(file, "Notes: ______________________________________\n")
-		fmt.Fprintf(file, "───────────────────────────────────────────────────────────────\n\n")
-	}
-
+	WriteReviewReport(file, title, language, items)
 	return nil
 }
 
@@ -2644,7 +2895,7 @@ func validateMatchLanguages(expectedLang string, matches []CompressedMatchResult
 
 	// Build map of version -> actual language
 	versionToLang := make(map[string]string)
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		versionToLang[w.Version] = w.Language
 	}
 
@@ -2694,15 +2945,14 @@ func applyCompressedMatches(language string, results CompressedBatchResponse) er
 	applied := 0
 	for _, match := range validatedMatches {
 		if match.EnglishPhelps != "" && match.TargetVersion != "" &&
-			match.MatchType != "AMBIGUOUS" && match.Confidence >= 70 {
-			// Update database with the match
-			query := fmt.Sprintf(`UPDATE writings SET phelps = '%s' WHERE version = '%s' AND language = '%s' AND phelps IS NULL`,
-				match.EnglishPhelps, match.TargetVersion, language)
-
-			cmd := execDoltCommand("sql", "-q", query)
-			if output, err := cmd.CombinedOutput(); err != nil {
-				log.Printf("⚠️ Failed to update %s: %v: %s", match.TargetVersion, err, string(output))
-			} else {
+			match.MatchType != "AMBIGUOUS" && shouldAutoApply(match.Backend, match.MatchType, match.Confidence, match.MatchReasons) {
+			// Update database with the match; passage codes (<Phelps>§n) store the Tablet code plus an excerpt note
+			phelps, passage := splitPassageCode(match.EnglishPhelps)
+			change := ProposedChange{Version: match.TargetVersion, Language: language, NewPhelps: phelps, Passage: passage,
+				Confidence: match.Confidence, MatchType: match.MatchType, Reasons: match.MatchReasons, Source: "retry"}
+			if written, err := writePhelps(change, true); err != nil {
+				log.Printf("⚠️ Failed to update %s: %v", match.TargetVersion, err)
+			} else if written {
 				applied++
 			}
 		}
@@ -2721,12 +2971,11 @@ func applyUltraMatches(results UltraBatchResponse) error {
 		if match.EnglishPhelps != "" && match.TargetVersion != "" {
 			// Extract language from the match context or determine it another way
 			// For now, we'll need to look up the language based on the version
-			query := fmt.Sprintf(`UPDATE writings SET phelps = '%s' WHERE version = '%s' AND phelps IS NULL`,
-				match.EnglishPhelps, match.TargetVersion)
-
-			cmd := execDoltCommand("sql", "-q", query)
-			if output, err := cmd.CombinedOutput(); err != nil {
-				log.Printf("⚠️ Failed to update %s: %v: %s", match.TargetVersion, err, string(output))
+			phelps, passage := splitPassageCode(match.EnglishPhelps)
+			change := ProposedChange{Version: match.TargetVersion, NewPhelps: phelps, Passage: passage,
+				Confidence: match.Confidence, MatchType: match.MatchType, Reasons: match.MatchReasons, Source: "retry"}
+			if _, err := writePhelps(change, true); err != nil {
+				log.Printf("⚠️ Failed to update %s: %v", match.TargetVersion, err)
 			}
 		}
 	}
@@ -2739,12 +2988,10 @@ func applyRegularMatches(language string, results BatchMatchResponse) error {
 
 	for _, match := range results.Matches {
 		if match.Phelps != "" && match.TargetVersion != "" {
-			query := fmt.Sprintf(`UPDATE writings SET phelps = '%s' WHERE version = '%s' AND language = '%s' AND phelps IS NULL`,
-				match.Phelps, match.TargetVersion, language)
-
-			cmd := execDoltCommand("sql", "-q", query)
-			if output, err := cmd.CombinedOutput(); err != nil {
-				log.Printf("⚠️ Failed to update %s: %v: %s", match.TargetVersion, err, string(output))
+			change := ProposedChange{Version: match.TargetVersion, Language: language, NewPhelps: match.Phelps,
+				Confidence: match.Confidence, MatchType: match.MatchType, Reasons: []string{match.Reasoning}, Source: "retry"}
+			if _, err := writePhelps(change, true); err != nil {
+				log.Printf("⚠️ Failed to update %s: %v", match.TargetVersion, err)
 			}
 		}
 	}
@@ -2916,24 +3163,6 @@ func min(a, b int) int {
 	return b
 }
 
-// ResolveAmbiguousMatches implements Phase 2 full-text matching for ambiguous results
-func ResolveAmbiguousMatches(language string) error {
-	log.Printf("Starting Phase 2 ambiguous resolution for language: %s", language)
-
-	// Find review files for this language
-	reviewFiles, err := filepath.Glob(fmt.Sprintf("review_*_%s_*.txt", language))
-	if err != nil {
-		return fmt.Errorf("failed to find review files: %v", err)
-	}
-
-	if len(reviewFiles) == 0 {
-		return fmt.Errorf("no review files found for language %s. Run compressed matching first to generate review files", language)
-	}
-
-	log.Printf("Found %d review files for %s. Phase 2 resolution is a placeholder - implement full-text matching here", len(reviewFiles), language)
-	return nil
-}
-
 // --- CSV Issue Processing Functions ---
 
 type IssueRecord struct {
@@ -3056,7 +3285,7 @@ func fixMissingPhelpsFromCSV(issues []IssueRecord) error {
 	// Group versions by language
 	langToVersions := make(map[string][]string)
 	for _, issue := range issues {
-		for _, writing := range db.Writings {
+		for _, writing := range db.Writing {
 			if writing.Version == issue.Version {
 				langToVersions[writing.Language] = append(langToVersions[writing.Language], issue.Version)
 				break
@@ -3122,14 +3351,14 @@ func fixDuplicatesFromCSV(issues []IssueRecord) error {
 func processLanguageVersions(db Database, language string, versions []string) error {
 	// Create target prayers from the specific versions we need to process
 	var targetPrayers []TargetPrayer
-	for _, writing := range db.Writings {
+	for _, writing := range db.Writing {
 		if writing.Language == language {
 			for _, version := range versions {
 				if writing.Version == version {
 					targetPrayers = append(targetPrayers, TargetPrayer{
 						Version: writing.Version,
 						Name:    writing.Name,
-						Text:    writing.Text,
+						Text:    cleanWritingText(writing),
 						Link:    writing.Link,
 						Source:  writing.Source,
 					})
@@ -3195,13 +3424,11 @@ func applyCsvMatches(results BatchMatchResponse, language string) error {
 
 	for _, match := range results.Matches {
 		if match.Phelps != "" && match.TargetVersion != "" {
-			query := fmt.Sprintf(`UPDATE writings SET phelps = '%s' WHERE version = '%s' AND language = '%s' AND phelps IS NULL`,
-				match.Phelps, match.TargetVersion, language)
-
-			cmd := execDoltCommand("sql", "-q", query)
-			if output, err := cmd.CombinedOutput(); err != nil {
-				fmt.Printf("⚠️ Failed to update %s: %v: %s\n", match.TargetVersion, err, string(output))
-			} else {
+			change := ProposedChange{Version: match.TargetVersion, Language: language, NewPhelps: match.Phelps,
+				Confidence: match.Confidence, MatchType: match.MatchType, Reasons: []string{match.Reasoning}, Source: "csv"}
+			if written, err := writePhelps(change, true); err != nil {
+				fmt.Printf("⚠️ Failed to update %s: %v\n", match.TargetVersion, err)
+			} else if written {
 				fmt.Printf("✅ Updated %s -> %s\n", match.TargetVersion, match.Phelps)
 			}
 		}
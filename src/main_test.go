@@ -1,3 +1,9 @@
+//go:build legacy
+
+// These tests target the original per-prayer LLM API (parseLLMResponse, OllamaMessage,
+// single-value GetDatabase) that the compressed matchers replaced; run with -tags legacy
+// against a checkout of that API.
+
 package main
 
 import (
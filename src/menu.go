@@ -299,17 +299,17 @@ func DetailedLanguageReport() error {
 	fmt.Println("═══════════════════════════")
 
 	// Get all language statistics
-	result, err := execDoltQueryCSV(`
+	result, err := execDoltQueryCSV(fmt.Sprintf(`
 		SELECT
 			language,
 			COUNT(*) as total_prayers,
-			COUNT(phelps) as matched_prayers,
-			COUNT(*) - COUNT(phelps) as unmatched_prayers,
-			ROUND(COUNT(phelps) * 100.0 / COUNT(*), 1) as completion_percent
+			%[1]s as matched_prayers,
+			%[2]s as unmatched_prayers,
+			ROUND(%[1]s * 100.0 / COUNT(*), 1) as completion_percent
 		FROM writings
 		GROUP BY language
 		ORDER BY COUNT(*) DESC
-	`)
+	`, countMatchedSQL(), countUnmatchedSQL()))
 
 	if err != nil {
 		return fmt.Errorf("failed to get language statistics: %v", err)
@@ -598,13 +598,13 @@ func GetTopUnprocessedLanguages(limit int) ([]LanguageStats, error) {
 		SELECT
 			language,
 			COUNT(*) as total_prayers,
-			COUNT(*) - COUNT(phelps) as unmatched_prayers
+			COUNT(*) as unmatched_prayers
 		FROM writings
-		WHERE language != 'en' AND phelps IS NULL
+		WHERE language != 'en' AND %s
 		GROUP BY language
 		ORDER BY unmatched_prayers DESC
 		LIMIT %d
-	`, limit))
+	`, unmatchedSQL("phelps"), limit))
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MinHash signatures use one-permutation hashing: every shingle hash lands in one
+// of minHashBins bins and each bin keeps its minimum. The LSH index splits the
+// signature into lshBands bands of lshRows rows; texts sharing any band are candidates.
+const (
+	minHashBins = 64
+	lshBands    = 16
+	lshRows     = minHashBins / lshBands
+
+	// nearDuplicateThreshold is the estimated Jaccard similarity above which two
+	// texts are considered the same prayer with cosmetic differences
+	nearDuplicateThreshold = 0.8
+)
+
+// foldedLetters returns the lowercased letters of a text with script variants and
+// Latin diacritics folded, shared by nearTextHash and MinHash shingling
+func foldedLetters(text, language string) string {
+	if isArabicScript(language) {
+		text = normalizeArabicScript(text)
+	}
+	if isChinese(language) {
+		text = normalizeChineseVariants(text)
+	}
+	text = strings.ToLower(text)
+
+	var clean strings.Builder
+	for _, r := range text {
+		if folded, ok := latinDiacriticFolds[r]; ok {
+			if folded == 0 {
+				continue
+			}
+			r = folded
+		}
+		if unicode.IsLetter(r) {
+			clean.WriteRune(r)
+		}
+	}
+	return clean.String()
+}
+
+// shingleSize picks character shingle length: dense scripts need shorter shingles
+func shingleSize(language string) int {
+	if isChinese(language) || isJapanese(language) {
+		return 3
+	}
+	return 5
+}
+
+// minHashSignature computes a one-permutation MinHash signature over character
+// shingles of the folded (already cleaned) text. Empty bins borrow from the next
+// filled bin so signatures of short texts stay comparable.
+func minHashSignature(text, language string) []uint64 {
+	runes := []rune(foldedLetters(text, language))
+	k := shingleSize(language)
+	if len(runes) < k {
+		if len(runes) == 0 {
+			return nil
+		}
+		k = len(runes)
+	}
+
+	const empty = ^uint64(0)
+	signature := make([]uint64, minHashBins)
+	for i := range signature {
+		signature[i] = empty
+	}
+
+	for i := 0; i+k <= len(runes); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(string(runes[i : i+k])))
+		value := mix64(h.Sum64())
+		bin := value % minHashBins
+		if v := value / minHashBins; v < signature[bin] {
+			signature[bin] = v
+		}
+	}
+
+	// Densify empty bins by rotation
+	for i := range signature {
+		if signature[i] != empty {
+			continue
+		}
+		for offset := 1; offset < minHashBins; offset++ {
+			if donor := signature[(i+offset)%minHashBins]; donor != empty {
+				signature[i] = mix64(donor + uint64(offset))
+				break
+			}
+		}
+	}
+
+	return signature
+}
+
+// mix64 is the splitmix64 finalizer, used to spread shingle hashes evenly over bins
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// estimateSimilarity estimates Jaccard similarity from two MinHash signatures
+func estimateSimilarity(a, b []uint64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a))
+}
+
+// NearDuplicate is an indexed writing whose text is near-identical to a query
+type NearDuplicate struct {
+	Version    string
+	Language   string
+	Phelps     string
+	Similarity float64
+}
+
+// NearDuplicatePair is a pair of near-identical writings found in the index
+type NearDuplicatePair struct {
+	A, B       NearDuplicate
+	Similarity float64
+}
+
+// NearDuplicateIndex is an LSH index over MinHash signatures
+type NearDuplicateIndex struct {
+	items   []NearDuplicate
+	sigs    [][]uint64
+	buckets []map[uint64][]int
+}
+
+// NewNearDuplicateIndex creates an empty LSH index
+func NewNearDuplicateIndex() *NearDuplicateIndex {
+	buckets := make([]map[uint64][]int, lshBands)
+	for i := range buckets {
+		buckets[i] = make(map[uint64][]int)
+	}
+	return &NearDuplicateIndex{buckets: buckets}
+}
+
+// bandKey hashes one band of a signature
+func bandKey(signature []uint64, band int) uint64 {
+	key := uint64(band) + 1
+	for _, v := range signature[band*lshRows : (band+1)*lshRows] {
+		key = mix64(key ^ v)
+	}
+	return key
+}
+
+// Add indexes a writing's signature
+func (idx *NearDuplicateIndex) Add(version, language, phelps string, signature []uint64) {
+	if len(signature) != minHashBins {
+		return
+	}
+	id := len(idx.items)
+	idx.items = append(idx.items, NearDuplicate{Version: version, Language: language, Phelps: phelps})
+	idx.sigs = append(idx.sigs, signature)
+	for band := 0; band < lshBands; band++ {
+		key := bandKey(signature, band)
+		idx.buckets[band][key] = append(idx.buckets[band][key], id)
+	}
+}
+
+// candidates returns ids sharing at least one band with the signature
+func (idx *NearDuplicateIndex) candidates(signature []uint64) map[int]bool {
+	found := make(map[int]bool)
+	if len(signature) != minHashBins {
+		return found
+	}
+	for band := 0; band < lshBands; band++ {
+		for _, id := range idx.buckets[band][bandKey(signature, band)] {
+			found[id] = true
+		}
+	}
+	return found
+}
+
+// Query returns indexed writings at or above the similarity threshold, most similar first
+func (idx *NearDuplicateIndex) Query(signature []uint64, threshold float64) []NearDuplicate {
+	var result []NearDuplicate
+	for id := range idx.candidates(signature) {
+		if sim := estimateSimilarity(signature, idx.sigs[id]); sim >= threshold {
+			item := idx.items[id]
+			item.Similarity = sim
+			result = append(result, item)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Similarity != result[j].Similarity {
+			return result[i].Similarity > result[j].Similarity
+		}
+		return result[i].Version < result[j].Version
+	})
+	return result
+}
+
+// Pairs returns all near-identical pairs in the index at or above the threshold
+func (idx *NearDuplicateIndex) Pairs(threshold float64) []NearDuplicatePair {
+	var pairs []NearDuplicatePair
+	for i := range idx.items {
+		for j := range idx.candidates(idx.sigs[i]) {
+			if j <= i {
+				continue
+			}
+			if sim := estimateSimilarity(idx.sigs[i], idx.sigs[j]); sim >= threshold {
+				pairs = append(pairs, NearDuplicatePair{A: idx.items[i], B: idx.items[j], Similarity: sim})
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Similarity != pairs[j].Similarity {
+			return pairs[i].Similarity > pairs[j].Similarity
+		}
+		return pairs[i].A.Version+pairs[i].B.Version < pairs[j].A.Version+pairs[j].B.Version
+	})
+	return pairs
+}
+
+// clusterNearDuplicates groups writings whose texts are near-identical to each other
+func clusterNearDuplicates(writings []Writing) [][]Writing {
+	parent := make([]int, len(writings))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	sigs := make([][]uint64, len(writings))
+	for i, w := range writings {
+		sigs[i] = minHashSignature(cleanWritingText(w), w.Language)
+	}
+	for i := range writings {
+		for j := i + 1; j < len(writings); j++ {
+			if estimateSimilarity(sigs[i], sigs[j]) >= nearDuplicateThreshold {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	groups := make(map[int][]Writing)
+	var order []int
+	for i, w := range writings {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], w)
+	}
+
+	clusters := make([][]Writing, 0, len(order))
+	for _, root := range order {
+		clusters = append(clusters, groups[root])
+	}
+	return clusters
+}
+
+// FindNearDuplicatesCommand reports near-identical prayers within a language
+// (duplicates to merge) and across languages sharing a script
+func FindNearDuplicatesCommand(language string) error {
+	db, err := GetDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load database: %w", err)
+	}
+
+	index := NewNearDuplicateIndex()
+	for _, w := range db.Writing {
+		if w.Text == "" {
+			continue
+		}
+		index.Add(w.Version, w.Language, w.Phelps, minHashSignature(cleanWritingText(w), w.Language))
+	}
+
+	var withinLanguage, crossLanguage []NearDuplicatePair
+	for _, pair := range index.Pairs(nearDuplicateThreshold) {
+		if language != "" && pair.A.Language != language && pair.B.Language != language {
+			continue
+		}
+		if pair.A.Language == pair.B.Language {
+			withinLanguage = append(withinLanguage, pair)
+		} else {
+			crossLanguage = append(crossLanguage, pair)
+		}
+	}
+
+	reportFile := fmt.Sprintf("near_duplicates_%s.txt", time.Now().Format("20060102_150405"))
+	file, err := os.Create(reportFile)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer file.Close()
+
+	writePairs := func(title string, pairs []NearDuplicatePair) {
+		fmt.Fprintf(file, "%s (%d pairs)\n", title, len(pairs))
+		fmt.Fprintf(file, "%s\n\n", strings.Repeat("=", 80))
+		for _, p := range pairs {
+			fmt.Fprintf(file, "%.0f%%  %s/%s [%s]  <->  %s/%s [%s]\n",
+				p.Similarity*100,
+				p.A.Language, p.A.Version, displayPhelps(p.A.Phelps),
+				p.B.Language, p.B.Version, displayPhelps(p.B.Phelps))
+		}
+		fmt.Fprintf(file, "\n")
+	}
+
+	fmt.Fprintf(file, "NEAR-DUPLICATE REPORT - %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(file, "Threshold: %.0f%% estimated Jaccard similarity (MinHash, %d bins, %d LSH bands)\n\n",
+		nearDuplicateThreshold*100, minHashBins, lshBands)
+	writePairs("WITHIN-LANGUAGE DUPLICATES (candidates to merge)", withinLanguage)
+	writePairs("CROSS-LANGUAGE SAME-SCRIPT DUPLICATES", crossLanguage)
+
+	log.Printf("🔍 Found %d within-language and %d cross-language near-duplicate pairs",
+		len(withinLanguage), len(crossLanguage))
+	log.Printf("📝 Report written to: %s", reportFile)
+	return nil
+}
+
+// displayPhelps shows an empty Phelps code as "unmatched" in reports
+func displayPhelps(phelps string) string {
+	if phelps == "" {
+		return "unmatched"
+	}
+	return phelps
+}
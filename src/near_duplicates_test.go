@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+)
+
+// Test MinHash near-duplicate detection
+func TestMinHashSimilarity(t *testing.T) {
+	base := "O God, my God! I have turned in repentance unto Thee, and verily Thou art the Pardoner, the Compassionate. " +
+		"O God, my God! I have returned to Thee, and verily Thou art the Ever-Forgiving, the Gracious. " +
+		"O God, my God! I have clung to the cord of Thy bounty, and with Thee is the storehouse of all that is in heaven and earth."
+
+	tests := []struct {
+		name    string
+		other   string
+		similar bool
+	}{
+		{
+			name:    "Footnote appended",
+			other:   base + "\n\n*43. This selection is from a Tablet revealed in honour of a believer.",
+			similar: true,
+		},
+		{
+			name:    "Punctuation and invocation line differ",
+			other:   "He is God!\n" + base,
+			similar: true,
+		},
+		{
+			name:    "Different prayer",
+			other:   "Is there any Remover of difficulties save God? Say: Praised be God! He is God! All are His servants, and all abide by His bidding!",
+			similar: false,
+		},
+	}
+
+	baseSig := CreatePrayerFingerprint("", "v1", "en", "", base, "").MinHash
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := estimateSimilarity(baseSig, CreatePrayerFingerprint("", "v2", "en", "", tt.other, "").MinHash)
+			if (sim >= nearDuplicateThreshold) != tt.similar {
+				t.Errorf("similarity = %.2f, expected similar=%v", sim, tt.similar)
+			}
+		})
+	}
+}
+
+func TestNearDuplicateIndex(t *testing.T) {
+	text := "主啊！我的上帝！我的庇护者！求祢以祢的恩典护佑我，使我远离一切邪恶，在祢的圣道上坚定不移，使我的心充满祢的爱。"
+
+	index := NewNearDuplicateIndex()
+	index.Add("zh-1", "zh-Hans", "AB00001FIR", minHashSignature(text, "zh-Hans"))
+	index.Add("zh-2", "zh-Hant", "", CreatePrayerFingerprint("", "zh-2", "zh-Hant", "", "主啊！我的上帝！我的庇護者！求祢以祢的恩典護佑我，使我遠離一切邪惡，在祢的聖道上堅定不移，使我的心充滿祢的愛。（註一）", "").MinHash)
+	index.Add("zh-3", "zh-Hans", "BH00002SEC", minHashSignature("赞美归于祢，我的上帝！祢的圣名是我的医治，忆念祢是我的良药，亲近祢是我的希望。", "zh-Hans"))
+
+	pairs := index.Pairs(nearDuplicateThreshold)
+	if len(pairs) != 1 {
+		t.Fatalf("Expected 1 near-duplicate pair, got %d: %+v", len(pairs), pairs)
+	}
+	if pairs[0].A.Version != "zh-1" || pairs[0].B.Version != "zh-2" {
+		t.Errorf("Expected zh-1/zh-2 pair, got %s/%s", pairs[0].A.Version, pairs[0].B.Version)
+	}
+
+	found := index.Query(minHashSignature(text+"（一）", "zh-Hans"), nearDuplicateThreshold)
+	if len(found) != 2 || found[0].Similarity < found[1].Similarity {
+		t.Errorf("Expected zh-1 and zh-2 sorted by similarity, got %+v", found)
+	}
+}
+
+func TestPrematchNearDuplicateInvocation(t *testing.T) {
+	text := "Blessed is the spot, and the house, and the place, and the city, and the heart, and the mountain, " +
+		"and the refuge, and the cave, and the valley, and the land, and the sea, and the island, and the meadow " +
+		"where mention of God hath been made, and His praise glorified."
+
+	db := Database{
+		Writing: []Writing{
+			{Phelps: "BH00002SEC", Version: "en-1", Language: "en", Text: text},
+		},
+	}
+
+	matches, remaining := PrematchByTextHash(BuildHashIndex(db), []PrayerFingerprint{
+		CreatePrayerFingerprint("", "us-1", "en-US", "", "He is God!\n\n"+text, ""),
+	})
+
+	if len(remaining) != 0 || len(matches) != 1 {
+		t.Fatalf("Expected copy with invocation to be matched locally, got %d matches and %d remaining", len(matches), len(remaining))
+	}
+	if matches[0].EnglishPhelps != "BH00002SEC" || matches[0].MatchType != "LIKELY" {
+		t.Errorf("Expected LIKELY BH00002SEC, got %s %s", matches[0].MatchType, matches[0].EnglishPhelps)
+	}
+}
+
+func TestClusterNearDuplicates(t *testing.T) {
+	text := "O Thou kind Lord! These are Thy servants who have gathered in this meeting, turning unto Thy kingdom and seeking Thy bestowals and blessings."
+	writings := []Writing{
+		{Version: "v1", Language: "en", Text: text},
+		{Version: "v2", Language: "en", Text: text + " *12"},
+		{Version: "v3", Language: "en", Text: "Create in me a pure heart, O my God, and renew a tranquil conscience within me, O my Hope!"},
+	}
+
+	clusters := clusterNearDuplicates(writings)
+	if len(clusters) != 2 || len(clusters[0]) != 2 || len(clusters[1]) != 1 {
+		t.Errorf("Expected clusters of sizes [2 1], got %d clusters", len(clusters))
+	}
+}
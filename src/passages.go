@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Long Tablets are split into passages so excerpts in other languages can be matched
+// to the part they translate. Passage fingerprints carry the code "<Phelps>§<n>".
+const (
+	longReferenceWords = 600 // references longer than this are segmented
+	passageTargetWords = 200 // approximate passage length
+	passageSeparator   = "§"
+)
+
+var (
+	paragraphBreakRegex   = regexp.MustCompile(`\n[ \t]*\n`)
+	sentenceEndRegex      = regexp.MustCompile(`[.!?;。！？؟]+["'”’»)]*\s+`)
+	excerptNoteRegex      = regexp.MustCompile(`excerpt of (\S+) §(\d+)`)
+	wholeExcerptNoteRegex = regexp.MustCompile(`^excerpt of \S+ §\d+$`) // one "; "-separated part of the notes
+)
+
+// passageWordCount approximates word count; Chinese and Japanese have no spaces,
+// so their character count is halved like getChineseWordCount does roughly
+func passageWordCount(text, language string) int {
+	if isChinese(language) || isJapanese(language) {
+		return len([]rune(strings.TrimSpace(text))) / 2
+	}
+	return len(strings.Fields(text))
+}
+
+// splitSentences splits an overlong paragraph at sentence ends, keeping the punctuation
+func splitSentences(paragraph string) []string {
+	var sentences []string
+	last := 0
+	for _, loc := range sentenceEndRegex.FindAllStringIndex(paragraph, -1) {
+		sentences = append(sentences, strings.TrimSpace(paragraph[last:loc[1]]))
+		last = loc[1]
+	}
+	if rest := strings.TrimSpace(paragraph[last:]); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+// SegmentPassages splits a long reference text into passages of roughly
+// passageTargetWords words along paragraph (then sentence) boundaries.
+// Texts shorter than longReferenceWords return nil.
+func SegmentPassages(text, language string) []string {
+	if passageWordCount(text, language) <= longReferenceWords {
+		return nil
+	}
+
+	// Paragraphs, with overlong ones broken into sentences
+	var units []string
+	for _, paragraph := range paragraphBreakRegex.Split(text, -1) {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		if passageWordCount(paragraph, language) > 2*passageTargetWords {
+			units = append(units, splitSentences(paragraph)...)
+		} else {
+			units = append(units, paragraph)
+		}
+	}
+
+	var passages []string
+	var current []string
+	currentWords := 0
+	for _, unit := range units {
+		current = append(current, unit)
+		currentWords += passageWordCount(unit, language)
+		if currentWords >= passageTargetWords {
+			passages = append(passages, strings.Join(current, "\n\n"))
+			current, currentWords = nil, 0
+		}
+	}
+	if len(current) > 0 {
+		rest := strings.Join(current, "\n\n")
+		// A short tail belongs to the previous passage
+		if len(passages) > 0 && currentWords < passageTargetWords/2 {
+			passages[len(passages)-1] += "\n\n" + rest
+		} else {
+			passages = append(passages, rest)
+		}
+	}
+
+	if len(passages) < 2 {
+		return nil
+	}
+	return passages
+}
+
+// passageCode builds the code used for a passage fingerprint
+func passageCode(phelps string, passage int) string {
+	return fmt.Sprintf("%s%s%d", phelps, passageSeparator, passage)
+}
+
+// splitPassageCode separates a "<Phelps>§<n>" code into the Phelps code and passage
+// number; codes without a passage return passage 0
+func splitPassageCode(code string) (string, int) {
+	idx := strings.LastIndex(code, passageSeparator)
+	if idx < 0 {
+		return code, 0
+	}
+	passage, err := strconv.Atoi(strings.TrimSpace(code[idx+len(passageSeparator):]))
+	if err != nil || passage < 1 {
+		return code, 0
+	}
+	return strings.TrimSpace(code[:idx]), passage
+}
+
+// excerptNote is the note recorded on writings matched to a passage of a Tablet
+func excerptNote(phelps string, passage int) string {
+	return fmt.Sprintf("excerpt of %s §%d", phelps, passage)
+}
+
+// parseExcerptNote extracts the Phelps code and passage from a writing's notes
+func parseExcerptNote(notes string) (string, int, bool) {
+	m := excerptNoteRegex.FindStringSubmatch(notes)
+	if m == nil {
+		return "", 0, false
+	}
+	passage, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return m[1], passage, true
+}
+
+// CreateReferenceFingerprints fingerprints a reference prayer and, for long Tablets,
+// each of its passages so excerpts can be matched to the passage they translate
+func CreateReferenceFingerprints(ref EnglishReference, language string) []PrayerFingerprint {
+	fingerprints := []PrayerFingerprint{CreatePrayerFingerprint(ref.Phelps, "", language, ref.Name, ref.Text, ref.Source)}
+
+	for i, passage := range SegmentPassages(ref.Text, language) {
+		name := fmt.Sprintf("%s §%d", ref.Name, i+1)
+		fingerprints = append(fingerprints,
+			CreatePrayerFingerprint(passageCode(ref.Phelps, i+1), "", language, strings.TrimSpace(name), passage, ref.Source))
+	}
+
+	return fingerprints
+}
+
+// passageLanguages maps every Phelps code to the language whose text numbers its
+// passages: English when the Tablet has an English text, then Arabic, then Persian.
+// The verifier and the review tools resolve § codes against the same text.
+func passageLanguages(db Database) map[string]string {
+	languages := make(map[string]string)
+	for _, w := range db.Writing {
+		if w.Phelps != "" && w.Text != "" && referenceLanguageRank[w.Language] > referenceLanguageRank[languages[w.Phelps]] {
+			languages[w.Phelps] = w.Language
+		}
+	}
+	return languages
+}
+
+// referenceFingerprints fingerprints a reference in the given language, with passage
+// fingerprints only when that language numbers the Tablet's passages
+func referenceFingerprints(ref EnglishReference, language string, passageLangs map[string]string) []PrayerFingerprint {
+	if passageLangs[ref.Phelps] != language {
+		return []PrayerFingerprint{CreatePrayerFingerprint(ref.Phelps, "", language, ref.Name, ref.Text, ref.Source)}
+	}
+	return CreateReferenceFingerprints(ref, language)
+}
+
+var tabletPassages map[string]int // Phelps -> passages of the Tablet, loaded on the first passage write
+
+// countTabletPassages counts the passages of every Tablet in the language that
+// numbers them
+func countTabletPassages(db Database) map[string]int {
+	languages := passageLanguages(db)
+	counts := make(map[string]int)
+	for _, w := range db.Writing {
+		if w.Phelps == "" || w.Language != languages[w.Phelps] {
+			continue
+		}
+		if n := len(SegmentPassages(cleanWritingText(w), w.Language)); n > counts[w.Phelps] {
+			counts[w.Phelps] = n
+		}
+	}
+	return counts
+}
+
+// validatePassage checks that a Tablet has passage n before it is written or noted
+func validatePassage(phelps string, passage int) error {
+	if passage == 0 {
+		return nil
+	}
+	if tabletPassages == nil {
+		db, err := GetDatabase()
+		if err != nil {
+			return fmt.Errorf("failed to read Tablet passages: %w", err)
+		}
+		tabletPassages = countTabletPassages(db)
+	}
+	if count := tabletPassages[phelps]; passage > count {
+		return fmt.Errorf("%s has %d passages, not %s", phelps, count, passageCode(phelps, passage))
+	}
+	return nil
+}
+
+// withExcerptNote replaces any excerpt note in notes with the one for the passage;
+// passage 0 (a full-prayer code) only removes it. Other notes are kept in order.
+func withExcerptNote(notes, phelps string, passage int) string {
+	var kept []string
+	for _, part := range strings.Split(notes, "; ") {
+		if part == "" || wholeExcerptNoteRegex.MatchString(strings.TrimSpace(part)) {
+			continue
+		}
+		kept = append(kept, part)
+	}
+	if passage > 0 {
+		kept = append(kept, excerptNote(phelps, passage))
+	}
+	return strings.Join(kept, "; ")
+}
+
+// recordExcerptNote updates a writing's notes to the excerpt note of its new code
+func recordExcerptNote(version, phelps string, passage int) error {
+	escapedVersion := strings.ReplaceAll(version, "'", "''")
+	records, err := execDoltQueryCSV(fmt.Sprintf("SELECT COALESCE(notes, '') FROM writings WHERE version = '%s'", escapedVersion))
+	if err != nil {
+		return err
+	}
+	if len(records) < 2 || len(records[1]) == 0 {
+		return fmt.Errorf("%s not found in database", version)
+	}
+	notes := records[1][0]
+
+	updated := withExcerptNote(notes, phelps, passage)
+	if updated == notes {
+		return nil
+	}
+	query := fmt.Sprintf("UPDATE writings SET notes = '%s' WHERE version = '%s'",
+		strings.ReplaceAll(updated, "'", "''"), escapedVersion)
+	if _, err := execDoltQuery(query); err != nil {
+		return err
+	}
+	if passage > 0 {
+		log.Printf("   📑 %s recorded as %s", version, excerptNote(phelps, passage))
+	}
+	return nil
+}
+
+// notePassageMatch brings the excerpt note in line with a code that was just applied:
+// passage codes replace it, full-prayer codes drop it
+func notePassageMatch(version, phelps string, passage int) {
+	if err := recordExcerptNote(version, phelps, passage); err != nil {
+		log.Printf("⚠️ Failed to record excerpt note for %s: %v", version, err)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// longTablet builds a Tablet of the given number of paragraphs with 60 words each
+func longTablet(paragraphs int) string {
+	var parts []string
+	for i := 0; i < paragraphs; i++ {
+		sentence := fmt.Sprintf("Paragraph %d praise be unto Thee O Lord my God for Thy bounties are manifest.", i+1)
+		words := strings.Fields(strings.Repeat(sentence+" ", 5))
+		parts = append(parts, strings.Join(words[:60], " "))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Test that passage writes are limited to the passages a Tablet has
+func TestValidatePassage(t *testing.T) {
+	tabletPassages = countTabletPassages(Database{Writing: []Writing{
+		{Phelps: "AB00001FIR", Language: "en", Text: longTablet(14)},
+		{Phelps: "AB00001FIR", Language: "ar", Text: longTablet(3)},
+		{Phelps: "BH00002SEC", Language: "en", Text: longTablet(3)},
+	}})
+	defer func() { tabletPassages = nil }()
+
+	tests := []struct {
+		phelps  string
+		passage int
+		valid   bool
+	}{
+		{"AB00001FIR", 0, true},
+		{"AB00001FIR", 4, true},
+		{"AB00001FIR", 5, false},
+		{"BH00002SEC", 1, false},
+		{"XX00009UNK", 1, false},
+	}
+	for _, tt := range tests {
+		if err := validatePassage(tt.phelps, tt.passage); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid=%v, got %v", passageCode(tt.phelps, tt.passage), tt.valid, err)
+		}
+	}
+
+	dryRunMode, phelpsRows = true, map[string]phelpsRow{"es-1": {Language: "es"}}
+	defer func() { dryRunMode, proposedChanges, phelpsRows = false, nil, nil }()
+	if _, err := writePhelps(ProposedChange{Version: "es-1", NewPhelps: "AB00001FIR", Passage: 7}, true); err == nil || len(proposedChanges) != 0 {
+		t.Errorf("Expected a write of a missing passage to be refused, got %v", err)
+	}
+}
+
+// Test passage segmentation of long Tablets
+func TestSegmentPassages(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		passages int
+	}{
+		{"Short prayer is not segmented", longTablet(3), 0},
+		{"Long Tablet is split at paragraphs", longTablet(14), 4},
+		{"Short tail joins the last passage", longTablet(13), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passages := SegmentPassages(tt.text, "en")
+			if len(passages) != tt.passages {
+				t.Fatalf("Expected %d passages, got %d", tt.passages, len(passages))
+			}
+			if tt.passages > 0 && strings.Join(passages, "\n\n") != tt.text {
+				t.Error("Expected passages to cover the whole text in order")
+			}
+		})
+	}
+}
+
+func TestSplitPassageCode(t *testing.T) {
+	tests := []struct {
+		code    string
+		phelps  string
+		passage int
+	}{
+		{"AB00001FIR§2", "AB00001FIR", 2},
+		{"BH00123", "BH00123", 0},
+		{"TMP00042§11", "TMP00042", 11},
+		{"AB00001FIR§x", "AB00001FIR§x", 0},
+	}
+
+	for _, tt := range tests {
+		phelps, passage := splitPassageCode(tt.code)
+		if phelps != tt.phelps || passage != tt.passage {
+			t.Errorf("splitPassageCode(%q) = %q, %d; want %q, %d", tt.code, phelps, passage, tt.phelps, tt.passage)
+		}
+	}
+
+	phelps, passage, ok := parseExcerptNote("verified; " + excerptNote("AB00001FIR", 3))
+	if !ok || phelps != "AB00001FIR" || passage != 3 {
+		t.Errorf("parseExcerptNote() = %q, %d, %v", phelps, passage, ok)
+	}
+}
+
+func TestCreateReferenceFingerprints(t *testing.T) {
+	ref := EnglishReference{Phelps: "AB00001FIR", Name: "Tablet", Text: longTablet(14)}
+	fingerprints := CreateReferenceFingerprints(ref, "en")
+
+	if len(fingerprints) != 5 {
+		t.Fatalf("Expected whole Tablet plus 4 passages, got %d fingerprints", len(fingerprints))
+	}
+	if fingerprints[0].Phelps != "AB00001FIR" || fingerprints[2].Phelps != "AB00001FIR§2" {
+		t.Errorf("Unexpected codes %q and %q", fingerprints[0].Phelps, fingerprints[2].Phelps)
+	}
+	if fingerprints[1].WordCount >= fingerprints[0].WordCount {
+		t.Error("Expected passage fingerprints to be shorter than the whole Tablet")
+	}
+
+	short := CreateReferenceFingerprints(EnglishReference{Phelps: "BH00002SEC", Text: "Is there any Remover of difficulties save God?"}, "en")
+	if len(short) != 1 {
+		t.Errorf("Expected a single fingerprint for a short prayer, got %d", len(short))
+	}
+}
+
+// Test that § codes are numbered by the text they are verified against
+func TestPassageNumberingFollowsReference(t *testing.T) {
+	db := Database{Writing: []Writing{
+		{Phelps: "AB00001FIR", Language: "en", Version: "en-1", Text: longTablet(14)},
+		{Phelps: "AB00001FIR", Language: "ar", Version: "ar-1", Text: longTablet(21)},
+		{Phelps: "BH00002SEC", Language: "ar", Version: "ar-2", Text: longTablet(21)},
+	}}
+	passageLangs := passageLanguages(db)
+
+	arabic := referenceFingerprints(EnglishReference{Phelps: "AB00001FIR", Text: db.Writing[1].Text}, "ar", passageLangs)
+	if len(arabic) != 1 {
+		t.Errorf("Expected no Arabic passages for a Tablet with an English text, got %d fingerprints", len(arabic))
+	}
+	arabicOnly := referenceFingerprints(EnglishReference{Phelps: "BH00002SEC", Text: db.Writing[2].Text}, "ar", passageLangs)
+	if len(arabicOnly) < 2 || arabicOnly[1].Phelps != "BH00002SEC§1" {
+		t.Errorf("Expected passages for an Arabic-only Tablet, got %d fingerprints", len(arabicOnly))
+	}
+
+	counts := countTabletPassages(db)
+	if english := len(SegmentPassages(db.Writing[0].Text, "en")); counts["AB00001FIR"] != english {
+		t.Errorf("Expected the English passage count %d, got %d", english, counts["AB00001FIR"])
+	}
+
+	texts := buildAlignmentTexts(db)
+	if texts.referenceLangs["AB00001FIR"] != "en" || texts.referenceLangs["BH00002SEC"] != "ar" {
+		t.Errorf("Unexpected reference languages %v", texts.referenceLangs)
+	}
+	if text, ok := evaluationReferenceText(db, "AB00001FIR§2"); !ok || text != SegmentPassages(cleanWritingText(db.Writing[0]), "en")[1] {
+		t.Errorf("Expected §2 to resolve against the English text, got %q", text)
+	}
+}
+
+// Test that a new code replaces the excerpt note instead of adding another
+func TestWithExcerptNote(t *testing.T) {
+	tests := []struct {
+		name     string
+		notes    string
+		phelps   string
+		passage  int
+		expected string
+	}{
+		{"First passage note", "", "AB00001FIR", 2, "excerpt of AB00001FIR §2"},
+		{"Re-match replaces the stale note", "checked by hand; excerpt of AB00001FIR §2", "AB00001FIR", 5, "checked by hand; excerpt of AB00001FIR §5"},
+		{"Other Tablet replaces the note", "excerpt of AB00001FIR §2; checked by hand", "BH00002SEC", 1, "checked by hand; excerpt of BH00002SEC §1"},
+		{"§12 does not count as §1", "excerpt of AB00001FIR §12", "AB00001FIR", 1, "excerpt of AB00001FIR §1"},
+		{"Full-prayer code drops the note", "checked by hand; excerpt of AB00001FIR §2", "AB00001FIR", 0, "checked by hand"},
+		{"Unrelated notes are untouched", "see excerpt of the compilation", "AB00001FIR", 0, "see excerpt of the compilation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withExcerptNote(tt.notes, tt.phelps, tt.passage); got != tt.expected {
+				t.Errorf("withExcerptNote(%q) = %q, want %q", tt.notes, got, tt.expected)
+			}
+			if phelps, passage, ok := parseExcerptNote(withExcerptNote(tt.notes, tt.phelps, tt.passage)); tt.passage > 0 && (!ok || phelps != tt.phelps || passage != tt.passage) {
+				t.Errorf("Expected the notes to parse as %s, got %s §%d", passageCode(tt.phelps, tt.passage), phelps, passage)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// Protection policy for Phelps codes: automated matching may fill unmatched prayers and
+// replace TMP codes, nothing more. Verified rows (is_verified = true) are never changed,
+// prayers whose code a reviewer approved or set are left to reviewers, and a proposal
+// that would replace a real code becomes a CORRECTION review item instead of a write.
+
+const (
+	reviewChangeSource = "review" // source of changes that carry a reviewer's decision
+	notVerifiedSQL     = "(is_verified IS NULL OR is_verified = false)"
+)
+
+// humanReviewedVersions returns the prayers whose code a reviewer approved or set
+func humanReviewedVersions(items []ReviewItem) map[string]bool {
+	versions := make(map[string]bool)
+	for _, item := range items {
+		if item.Status == ReviewApproved || item.Status == ReviewModified {
+			versions[item.Version] = true
+		}
+	}
+	return versions
+}
+
+// markReviewedRows flags the prayers of human review decisions
+func markReviewedRows(rows map[string]phelpsRow) {
+	items, err := LoadReviewQueue(reviewQueueFile)
+	if err != nil {
+		log.Printf("⚠️ Could not read review decisions from %s: %v", reviewQueueFile, err)
+	}
+	for version := range humanReviewedVersions(items) {
+		if row, ok := rows[version]; ok {
+			row.Reviewed = true
+			rows[version] = row
+		}
+	}
+}
+
+// protectionReason says why a change may not be written, or "" when it may; review
+// reports that the change should be put to a reviewer instead
+func protectionReason(row phelpsRow, change ProposedChange) (string, bool) {
+	switch {
+	case row.IsVerified:
+		return "is verified", false
+	case change.Source == reviewChangeSource:
+		return "", false
+	case row.Reviewed:
+		return fmt.Sprintf("has %s from a reviewer", displayPhelps(row.Phelps)), false
+	case row.Phelps != "" && !isTMPCode(row.Phelps):
+		if change.NewPhelps == "" {
+			return fmt.Sprintf("has real code %s, clear it through review", row.Phelps), false
+		}
+		return fmt.Sprintf("has real code %s, proposal queued for review", row.Phelps), true
+	}
+	return "", false
+}
+
+// queueCorrection puts a proposal that would replace a real code in the review queue; a
+// dry run only reports it
+func queueCorrection(change ProposedChange) {
+	if dryRunMode {
+		return
+	}
+
+	code := change.NewPhelps
+	if change.Passage > 0 {
+		code = passageCode(code, change.Passage)
+	}
+	candidate := ReviewCandidate{
+		Phelps:     code,
+		Confidence: change.Confidence,
+		MatchType:  change.MatchType,
+		Reasons:    append(append([]string(nil), change.Reasons...), "would replace "+change.OldPhelps),
+	}
+
+	items, err := openReviewQueue()
+	if err != nil {
+		log.Printf("⚠️ Failed to load review queue for %s: %v", change.Version, err)
+		return
+	}
+	items, touched := EnqueueReviewItems(items, change.Language, ReviewCorrection, []ReviewProposal{{Version: change.Version, ReviewCandidate: candidate}})
+	for i := range items {
+		for _, item := range touched {
+			if items[i].ID == item.ID {
+				items[i].Category = ReviewCorrection
+			}
+		}
+	}
+	if err := SaveReviewQueue(reviewQueueFile, items); err != nil {
+		log.Printf("⚠️ Failed to save review queue: %v", err)
+	}
+}
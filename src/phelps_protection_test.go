@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+// Test which changes the protection policy lets through and which go to review
+func TestProtectionReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     phelpsRow
+		change  ProposedChange
+		blocked bool
+		review  bool
+	}{
+		{"unmatched prayer", phelpsRow{}, ProposedChange{NewPhelps: "BH00001ONE"}, false, false},
+		{"TMP code", phelpsRow{Phelps: "TMP00003"}, ProposedChange{NewPhelps: "BH00001ONE"}, false, false},
+		{"real code", phelpsRow{Phelps: "AB00002TWO"}, ProposedChange{NewPhelps: "BH00001ONE"}, true, true},
+		{"clearing a real code", phelpsRow{Phelps: "AB00002TWO"}, ProposedChange{}, true, false},
+		{"verified", phelpsRow{IsVerified: true}, ProposedChange{NewPhelps: "BH00001ONE"}, true, false},
+		{"verified, even for a reviewer", phelpsRow{Phelps: "AB00002TWO", IsVerified: true}, ProposedChange{NewPhelps: "BH00001ONE", Source: reviewChangeSource}, true, false},
+		{"reviewed TMP code", phelpsRow{Phelps: "TMP00003", Reviewed: true}, ProposedChange{NewPhelps: "BH00001ONE"}, true, false},
+		{"reviewer replaces real code", phelpsRow{Phelps: "AB00002TWO", Reviewed: true}, ProposedChange{NewPhelps: "BH00001ONE", Source: reviewChangeSource}, false, false},
+	}
+	for _, tt := range tests {
+		reason, review := protectionReason(tt.row, tt.change)
+		if (reason != "") != tt.blocked || review != tt.review {
+			t.Errorf("%s: expected blocked %v, review %v, got %q, %v", tt.name, tt.blocked, tt.review, reason, review)
+		}
+	}
+
+	items := []ReviewItem{
+		{Version: "es-1", Status: ReviewApproved},
+		{Version: "es-2", Status: ReviewModified},
+		{Version: "es-3", Status: ReviewRejected},
+		{Version: "es-4", Status: ReviewResolved},
+		{Version: "es-5", Status: ReviewOpen},
+	}
+	reviewed := humanReviewedVersions(items)
+	if len(reviewed) != 2 || !reviewed["es-1"] || !reviewed["es-2"] {
+		t.Errorf("Expected approved and modified prayers only, got %v", reviewed)
+	}
+}
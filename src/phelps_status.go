@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// A writing is unmatched when its Phelps code is NULL or blank. Both spellings exist in
+// the database because clearing a code used to write '', so status, batching and TMP
+// assignment build their SQL from these helpers instead of spelling the test out, and
+// GetDatabase trims codes so `w.Phelps == ""` means the same on the Go side.
+// -normalize-phelps rewrites blank codes to NULL, the canonical "no code" value.
+
+// unmatchedSQL is the condition for an unmatched writing on the given phelps column
+func unmatchedSQL(column string) string {
+	return fmt.Sprintf("(%s IS NULL OR TRIM(%s) = '')", column, column)
+}
+
+// matchedSQL is the condition for a writing with a Phelps code on the given column
+func matchedSQL(column string) string {
+	return fmt.Sprintf("(%s IS NOT NULL AND TRIM(%s) != '')", column, column)
+}
+
+// countUnmatchedSQL counts unmatched writings in a grouped query
+func countUnmatchedSQL() string {
+	return fmt.Sprintf("SUM(CASE WHEN %s THEN 1 ELSE 0 END)", unmatchedSQL("phelps"))
+}
+
+// countMatchedSQL counts matched writings in a grouped query
+func countMatchedSQL() string {
+	return fmt.Sprintf("SUM(CASE WHEN %s THEN 1 ELSE 0 END)", matchedSQL("phelps"))
+}
+
+// normalizePhelps is how a loaded code is stored in Writing.Phelps
+func normalizePhelps(code string) string {
+	return strings.TrimSpace(code)
+}
+
+// Blank and padded codes as NormalizePhelpsCommand finds and fixes them
+const (
+	blankPhelpsSQL  = "phelps IS NOT NULL AND TRIM(phelps) = ''"
+	paddedPhelpsSQL = "TRIM(phelps) != '' AND phelps != TRIM(phelps)"
+)
+
+// countWritings counts the writings matching a condition
+func countWritings(condition string) (int, error) {
+	records, err := execDoltQueryCSV("SELECT COUNT(*) FROM writings WHERE " + condition)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	if len(records) > 1 {
+		fmt.Sscanf(records[1][0], "%d", &count)
+	}
+	return count, nil
+}
+
+// NormalizePhelpsCommand stores NULL for blank Phelps codes and trims padded ones
+func NormalizePhelpsCommand(dryRun bool) error {
+	blank, err := countWritings(blankPhelpsSQL)
+	if err != nil {
+		return fmt.Errorf("failed to count blank codes: %w", err)
+	}
+	padded, err := countWritings(paddedPhelpsSQL)
+	if err != nil {
+		return fmt.Errorf("failed to count padded codes: %w", err)
+	}
+
+	log.Printf("🧹 %d blank Phelps codes to set to NULL, %d padded codes to trim", blank, padded)
+	if dryRun {
+		log.Printf("🔍 Dry run - no changes made")
+		return nil
+	}
+	if blank == 0 && padded == 0 {
+		log.Printf("✅ Phelps codes are already normalized")
+		return nil
+	}
+
+	if _, err := execDoltQuery("UPDATE writings SET phelps = NULL WHERE " + blankPhelpsSQL); err != nil {
+		return fmt.Errorf("failed to null blank codes: %w", err)
+	}
+	if _, err := execDoltQuery("UPDATE writings SET phelps = TRIM(phelps) WHERE " + paddedPhelpsSQL); err != nil {
+		return fmt.Errorf("failed to trim codes: %w", err)
+	}
+	log.Printf("✅ Phelps codes normalized")
+	return nil
+}
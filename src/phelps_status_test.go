@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+// Test that matched and unmatched conditions treat NULL and blank codes alike
+func TestPhelpsConditions(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{"unmatched", unmatchedSQL("phelps"), "(phelps IS NULL OR TRIM(phelps) = '')"},
+		{"matched alias", matchedSQL("base.phelps"), "(base.phelps IS NOT NULL AND TRIM(base.phelps) != '')"},
+		{"count unmatched", countUnmatchedSQL(), "SUM(CASE WHEN (phelps IS NULL OR TRIM(phelps) = '') THEN 1 ELSE 0 END)"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, tt.got)
+		}
+	}
+
+	// Loaded codes are trimmed so `w.Phelps == ""` agrees with unmatchedSQL
+	for code, expected := range map[string]string{"": "", "   ": "", " BH00001 ": "BH00001", "TMP00002": "TMP00002"} {
+		if got := normalizePhelps(code); got != expected {
+			t.Errorf("normalizePhelps(%q) = %q, expected %q", code, got, expected)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Progress history: every run that can change matches appends a snapshot of per-language
+// matched, unmatched and TMP counts plus the open review queue to the progress_history
+// table. -progress renders the trends, so we can see which runs actually moved the needle.
+
+const (
+	progressOverall  = "all" // language of the snapshot row that sums all languages
+	progressHTMLFile = "progress.html"
+)
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// ProgressPoint is one language in one snapshot
+type ProgressPoint struct {
+	Time       time.Time
+	SessionID  string
+	RunArgs    string
+	Language   string
+	Total      int
+	Matched    int
+	Unmatched  int
+	TMP        int
+	ReviewOpen int
+}
+
+// ProgressRun is the overall change one run made to the matched count
+type ProgressRun struct {
+	Time      time.Time
+	RunArgs   string
+	Matched   int
+	Delta     int
+	TMPDelta  int
+	OpenDelta int
+}
+
+// createProgressTable creates the progress_history table if needed
+func createProgressTable() error {
+	_, err := execDoltQuery(`CREATE TABLE IF NOT EXISTS progress_history (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		snapshot_time DATETIME NOT NULL,
+		session_id VARCHAR(100) NOT NULL,
+		run_args VARCHAR(255),
+		language VARCHAR(20) NOT NULL,
+		total INT NOT NULL,
+		matched INT NOT NULL,
+		unmatched INT NOT NULL,
+		tmp INT NOT NULL,
+		review_open INT NOT NULL,
+		INDEX idx_language (language),
+		INDEX idx_time (snapshot_time)
+	)`)
+	return err
+}
+
+// buildProgressSnapshot turns per-language counts (language, total, matched, unmatched,
+// tmp rows with a header) and the review queue into snapshot points plus an overall point
+func buildProgressSnapshot(records [][]string, items []ReviewItem, now time.Time, runArgs string) []ProgressPoint {
+	open := make(map[string]int)
+	for _, item := range items {
+		if item.IsOpen() {
+			open[item.Language]++
+			open[progressOverall]++
+		}
+	}
+
+	overall := ProgressPoint{Time: now, SessionID: currentSessionID, RunArgs: runArgs, Language: progressOverall, ReviewOpen: open[progressOverall]}
+	var points []ProgressPoint
+	for i, rec := range records {
+		if i == 0 || len(rec) < 5 || rec[0] == "" {
+			continue
+		}
+		point := ProgressPoint{Time: now, SessionID: currentSessionID, RunArgs: runArgs, Language: rec[0], ReviewOpen: open[rec[0]]}
+		point.Total, _ = strconv.Atoi(rec[1])
+		point.Matched, _ = strconv.Atoi(rec[2])
+		point.Unmatched, _ = strconv.Atoi(rec[3])
+		point.TMP, _ = strconv.Atoi(rec[4])
+		points = append(points, point)
+
+		overall.Total += point.Total
+		overall.Matched += point.Matched
+		overall.Unmatched += point.Unmatched
+		overall.TMP += point.TMP
+	}
+	return append(points, overall)
+}
+
+// RecordProgressSnapshot appends the current counts to progress_history; failures are
+// only logged so a snapshot never fails a run
+func RecordProgressSnapshot() {
+	if err := createProgressTable(); err != nil {
+		log.Printf("⚠️ Progress history unavailable: %v", err)
+		return
+	}
+	records, err := execDoltQueryCSV(fmt.Sprintf(`
+		SELECT language, COUNT(*), %s, %s, SUM(CASE WHEN phelps LIKE '%s%%' THEN 1 ELSE 0 END)
+		FROM writings
+		GROUP BY language
+	`, countMatchedSQL(), countUnmatchedSQL(), TMP_CODE_PREFIX))
+	if err != nil {
+		log.Printf("⚠️ Failed to count progress: %v", err)
+		return
+	}
+	items, err := LoadReviewQueue(reviewQueueFile)
+	if err != nil {
+		log.Printf("⚠️ Failed to load review queue for progress: %v", err)
+	}
+
+	runArgs := strings.Join(os.Args[1:], " ")
+	if len(runArgs) > 255 {
+		runArgs = runArgs[:255]
+	}
+	points := buildProgressSnapshot(records, items, time.Now(), runArgs)
+
+	var values []string
+	for _, p := range points {
+		values = append(values, fmt.Sprintf("('%s', '%s', '%s', '%s', %d, %d, %d, %d, %d)",
+			p.Time.Format(noteTimeFormat),
+			strings.ReplaceAll(p.SessionID, "'", "''"),
+			strings.ReplaceAll(p.RunArgs, "'", "''"),
+			strings.ReplaceAll(p.Language, "'", "''"),
+			p.Total, p.Matched, p.Unmatched, p.TMP, p.ReviewOpen))
+	}
+	query := "INSERT INTO progress_history (snapshot_time, session_id, run_args, language, total, matched, unmatched, tmp, review_open) VALUES " + strings.Join(values, ", ")
+	if _, err := execDoltQuery(query); err != nil {
+		log.Printf("⚠️ Failed to record progress snapshot: %v", err)
+		return
+	}
+	overall := points[len(points)-1]
+	log.Printf("📈 Progress snapshot: %d/%d matched, %d TMP, %d open reviews", overall.Matched, overall.Total, overall.TMP, overall.ReviewOpen)
+}
+
+// LoadProgressHistory returns all snapshots in time order, optionally for one language
+// (the overall series is always included)
+func LoadProgressHistory(language string) ([]ProgressPoint, error) {
+	query := "SELECT snapshot_time, session_id, run_args, language, total, matched, unmatched, tmp, review_open FROM progress_history"
+	if language != "" {
+		query += fmt.Sprintf(" WHERE language IN ('%s', '%s')", strings.ReplaceAll(language, "'", "''"), progressOverall)
+	}
+	records, err := execDoltQueryCSV(query + " ORDER BY snapshot_time, id")
+	if err != nil {
+		return nil, err
+	}
+
+	var points []ProgressPoint
+	for i, rec := range records {
+		if i == 0 || len(rec) < 9 {
+			continue
+		}
+		point := ProgressPoint{SessionID: rec[1], RunArgs: rec[2], Language: rec[3]}
+		point.Time, _ = time.ParseInLocation(noteTimeFormat, rec[0], time.Local)
+		point.Total, _ = strconv.Atoi(rec[4])
+		point.Matched, _ = strconv.Atoi(rec[5])
+		point.Unmatched, _ = strconv.Atoi(rec[6])
+		point.TMP, _ = strconv.Atoi(rec[7])
+		point.ReviewOpen, _ = strconv.Atoi(rec[8])
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// progressSeries groups points by language, keeping time order
+func progressSeries(points []ProgressPoint) map[string][]ProgressPoint {
+	series := make(map[string][]ProgressPoint)
+	for _, p := range points {
+		series[p.Language] = append(series[p.Language], p)
+	}
+	return series
+}
+
+// progressRuns lists the change of every overall snapshot against the one before it
+func progressRuns(overall []ProgressPoint) []ProgressRun {
+	var runs []ProgressRun
+	for i := 1; i < len(overall); i++ {
+		prev, cur := overall[i-1], overall[i]
+		runs = append(runs, ProgressRun{
+			Time:      cur.Time,
+			RunArgs:   cur.RunArgs,
+			Matched:   cur.Matched,
+			Delta:     cur.Matched - prev.Matched,
+			TMPDelta:  cur.TMP - prev.TMP,
+			OpenDelta: cur.ReviewOpen - prev.ReviewOpen,
+		})
+	}
+	return runs
+}
+
+// matchedValues is the matched count of each point
+func matchedValues(points []ProgressPoint) []int {
+	values := make([]int, len(points))
+	for i, p := range points {
+		values[i] = p.Matched
+	}
+	return values
+}
+
+// sparkline renders values as block characters scaled between their minimum and maximum
+func sparkline(values []int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if hi > lo {
+			level = (v - lo) * (len(sparkBlocks) - 1) / (hi - lo)
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// svgPolyline scales values into the points of a width×height SVG polyline
+func svgPolyline(values []int, width, height int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	var coords []string
+	for i, v := range values {
+		x := 0
+		if len(values) > 1 {
+			x = i * width / (len(values) - 1)
+		}
+		y := height / 2
+		if hi > lo {
+			y = height - (v-lo)*height/(hi-lo)
+		}
+		coords = append(coords, fmt.Sprintf("%d,%d", x, y))
+	}
+	return strings.Join(coords, " ")
+}
+
+// progressChart is one language in the HTML report
+type progressChart struct {
+	Language string
+	First    ProgressPoint
+	Last     ProgressPoint
+	Gain     int
+	Matched  string
+	Spark    string
+}
+
+// progressCharts orders the languages by matched gain over the history, overall first
+func progressCharts(series map[string][]ProgressPoint) []progressChart {
+	var charts []progressChart
+	for language, points := range series {
+		first, last := points[0], points[len(points)-1]
+		charts = append(charts, progressChart{
+			Language: language,
+			First:    first,
+			Last:     last,
+			Gain:     last.Matched - first.Matched,
+			Matched:  svgPolyline(matchedValues(points), 300, 60),
+			Spark:    sparkline(matchedValues(points)),
+		})
+	}
+	sort.Slice(charts, func(i, j int) bool {
+		if (charts[i].Language == progressOverall) != (charts[j].Language == progressOverall) {
+			return charts[i].Language == progressOverall
+		}
+		if charts[i].Gain != charts[j].Gain {
+			return charts[i].Gain > charts[j].Gain
+		}
+		return charts[i].Language < charts[j].Language
+	})
+	return charts
+}
+
+var progressTemplate = template.Must(template.New("progress").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Matching progress</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+td, th { border: 1px solid #ccc; padding: 3px 8px; text-align: right; font-size: 14px; }
+td.text { text-align: left; font-family: monospace; }
+svg { background: #f8f8f8; }
+polyline { fill: none; stroke: #26a; stroke-width: 2; }
+.up { color: #282; } .down { color: #c22; }
+</style>
+</head>
+<body>
+<h1>Matching progress</h1>
+<p>{{len .Runs}} runs recorded since the first snapshot. Charts show matched prayers over time.</p>
+
+<h2>Languages</h2>
+<table>
+<tr><th>Language</th><th>Matched</th><th>Gain</th><th>Unmatched</th><th>TMP</th><th>Open reviews</th><th>Trend</th></tr>
+{{range .Charts}}<tr><td class="text">{{.Language}}</td><td>{{.Last.Matched}}/{{.Last.Total}}</td><td class="{{if gt .Gain 0}}up{{else if lt .Gain 0}}down{{end}}">{{printf "%+d" .Gain}}</td><td>{{.Last.Unmatched}}</td><td>{{.Last.TMP}}</td><td>{{.Last.ReviewOpen}}</td>
+<td><svg width="300" height="60" viewBox="-2 -2 304 64"><polyline points="{{.Matched}}"/></svg></td></tr>
+{{end}}</table>
+
+<h2>Runs</h2>
+<table>
+<tr><th>Time</th><th>Matched</th><th>Change</th><th>TMP</th><th>Open reviews</th><th>Command</th></tr>
+{{range .Runs}}<tr><td class="text">{{.Time.Format "2006-01-02 15:04"}}</td><td>{{.Matched}}</td><td class="{{if gt .Delta 0}}up{{else if lt .Delta 0}}down{{end}}">{{printf "%+d" .Delta}}</td><td>{{printf "%+d" .TMPDelta}}</td><td>{{printf "%+d" .OpenDelta}}</td><td class="text">{{.RunArgs}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// writeProgressHTML renders the history as an HTML report
+func writeProgressHTML(w io.Writer, charts []progressChart, runs []ProgressRun) error {
+	return progressTemplate.Execute(w, map[string]interface{}{"Charts": charts, "Runs": runs})
+}
+
+// ProgressCommand shows the trends of the recorded snapshots and writes them as HTML
+func ProgressCommand(language string) error {
+	points, err := LoadProgressHistory(language)
+	if err != nil {
+		return fmt.Errorf("failed to load progress history: %w", err)
+	}
+	if len(points) == 0 {
+		log.Printf("📈 No progress snapshots yet - they are recorded after every matching run")
+		return nil
+	}
+
+	series := progressSeries(points)
+	charts := progressCharts(series)
+	runs := progressRuns(series[progressOverall])
+
+	log.Printf("📈 Matching progress (%d snapshots)", len(series[progressOverall]))
+	log.Printf("------------------------------------")
+	for i, c := range charts {
+		if i == 21 {
+			log.Printf("  ... %d more languages in %s", len(charts)-21, progressHTMLFile)
+			break
+		}
+		log.Printf("  %-12s %s %d → %d matched (%+d), %d unmatched, %d TMP, %d open reviews",
+			c.Language, c.Spark, c.First.Matched, c.Last.Matched, c.Gain, c.Last.Unmatched, c.Last.TMP, c.Last.ReviewOpen)
+	}
+
+	moved := append([]ProgressRun(nil), runs...)
+	sort.SliceStable(moved, func(i, j int) bool { return moved[i].Delta > moved[j].Delta })
+	if len(moved) > 0 {
+		log.Printf("🚀 Runs that moved the needle:")
+		for i, run := range moved {
+			if i == 10 || run.Delta <= 0 {
+				break
+			}
+			log.Printf("  %s %+5d matched  %s", run.Time.Format("2006-01-02 15:04"), run.Delta, run.RunArgs)
+		}
+	}
+
+	if err := writeReviewExport(progressHTMLFile, func(w io.Writer) error { return writeProgressHTML(w, charts, runs) }); err != nil {
+		return fmt.Errorf("failed to write %s: %w", progressHTMLFile, err)
+	}
+	log.Printf("✅ Progress report written to %s", progressHTMLFile)
+	return nil
+}
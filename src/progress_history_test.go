@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test building progress snapshots and rendering their trends
+func TestProgressHistory(t *testing.T) {
+	records := [][]string{
+		{"language", "COUNT(*)", "matched", "unmatched", "tmp"},
+		{"en", "10", "10", "0", "2"},
+		{"es", "8", "5", "3", "0"},
+	}
+	items := []ReviewItem{
+		{ID: "000001", Language: "es", Status: ReviewOpen},
+		{ID: "000002", Language: "es", Status: ReviewApproved},
+	}
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	first := buildProgressSnapshot(records, items, start, "-language=es")
+	if len(first) != 3 {
+		t.Fatalf("Expected 2 languages and an overall point, got %+v", first)
+	}
+	overall := first[2]
+	if overall.Language != progressOverall || overall.Total != 18 || overall.Matched != 15 || overall.Unmatched != 3 || overall.TMP != 2 || overall.ReviewOpen != 1 {
+		t.Errorf("Unexpected overall point %+v", overall)
+	}
+	if first[1].Language != "es" || first[1].ReviewOpen != 1 || first[0].ReviewOpen != 0 {
+		t.Errorf("Unexpected language points %+v", first[:2])
+	}
+
+	records[2] = []string{"es", "8", "8", "0", "0"}
+	second := buildProgressSnapshot(records, nil, start.Add(time.Hour), "-ultra -cli")
+	series := progressSeries(append(first, second...))
+	runs := progressRuns(series[progressOverall])
+	if len(runs) != 1 || runs[0].Delta != 3 || runs[0].OpenDelta != -1 || runs[0].RunArgs != "-ultra -cli" {
+		t.Errorf("Unexpected runs %+v", runs)
+	}
+
+	charts := progressCharts(series)
+	if charts[0].Language != progressOverall || charts[1].Language != "es" || charts[1].Gain != 3 {
+		t.Errorf("Expected overall first and es ahead of en, got %+v", charts)
+	}
+	var html bytes.Buffer
+	if err := writeProgressHTML(&html, charts, runs); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), `<polyline points="0,60 300,0"/>`) || !strings.Contains(html.String(), "-ultra -cli") {
+		t.Errorf("Unexpected HTML:\n%s", html.String())
+	}
+}
+
+// Test sparkline scaling
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values   []int
+		expected string
+	}{
+		{nil, ""},
+		{[]int{5}, "▁"},
+		{[]int{3, 3, 3}, "▁▁▁"},
+		{[]int{0, 7}, "▁█"},
+		{[]int{10, 20, 30, 40, 50, 60, 70, 80}, "▁▂▃▄▅▆▇█"},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.values); got != tt.expected {
+			t.Errorf("sparkline(%v) = %q, expected %q", tt.values, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Reviewer decisions come back either as ticked boxes in an edited review file or as
+// statuses set directly in review_queue.jsonl. Both are recorded in the queue first and
+// then written to the database by applyReviewDecisions.
+
+// ReviewDecision is what a reviewer decided for one review item
+type ReviewDecision struct {
+	ItemID   string
+	Version  string
+	Action   string // ReviewApproved, ReviewRejected or ReviewModified
+	Code     string // code to write, or the rejected code for ReviewRejected
+	Note     string
+	Reviewer string
+}
+
+var (
+	reviewCheckboxRegex = regexp.MustCompile(`\[\s*[xX✓✔]\s*\]\s*(APPROVE|REJECT|MODIFY)\b[ \t]*([A-Za-z0-9§]*)`)
+	phelpsCodeRegex     = regexp.MustCompile(`^[A-Z]{2,3}\d{5}[A-Z]*(?:§\d+)?$`)
+)
+
+// reviewFileEntry collects the fields of one entry of an edited review file
+type reviewFileEntry struct {
+	ItemID    string
+	Version   string
+	Suggested string
+	Actions   []string
+	Modify    string
+	Note      string
+}
+
+// reviewFieldValue strips the fill-in underscores from a review file field
+func reviewFieldValue(value string) string {
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(value), "_"))
+}
+
+// parseReviewDecisions reads the ticked boxes, MODIFY codes and notes of an edited review file
+func parseReviewDecisions(filename, reviewer string) ([]ReviewDecision, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []reviewFileEntry
+	var current *reviewFileEntry
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, _ := strings.Cut(line, ":")
+
+		if strings.Contains(key, "Target Prayer") {
+			if current != nil {
+				entries = append(entries, *current)
+			}
+			current = &reviewFileEntry{Version: strings.TrimSpace(value)}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch strings.TrimSpace(key) {
+		case "Item":
+			current.ItemID = strings.TrimSpace(value)
+		case "Suggested Phelps":
+			current.Suggested = strings.TrimSpace(value)
+		case "Action needed":
+			for _, m := range reviewCheckboxRegex.FindAllStringSubmatch(value, -1) {
+				current.Actions = append(current.Actions, m[1])
+				if m[1] == "MODIFY" && m[2] != "" {
+					current.Modify = m[2]
+				}
+			}
+		case "Modify to":
+			if code := reviewFieldValue(value); code != "" {
+				current.Modify = code
+			}
+		case "Notes":
+			current.Note = reviewFieldValue(value)
+		}
+	}
+	if current != nil {
+		entries = append(entries, *current)
+	}
+
+	var decisions []ReviewDecision
+	for _, entry := range entries {
+		if len(entry.Actions) == 0 {
+			continue
+		}
+		if len(entry.Actions) > 1 {
+			log.Printf("⚠️ %s: more than one box ticked for %s, skipping", filepath.Base(filename), entry.Version)
+			continue
+		}
+
+		decision := ReviewDecision{ItemID: entry.ItemID, Version: entry.Version, Note: entry.Note, Reviewer: reviewer}
+		switch entry.Actions[0] {
+		case "APPROVE":
+			decision.Action, decision.Code = ReviewApproved, entry.Suggested
+		case "REJECT":
+			decision.Action, decision.Code = ReviewRejected, entry.Suggested
+		case "MODIFY":
+			if entry.Modify == "" {
+				log.Printf("⚠️ %s: MODIFY without a code for %s, skipping", filepath.Base(filename), entry.Version)
+				continue
+			}
+			decision.Action, decision.Code = ReviewModified, strings.ToUpper(entry.Modify)
+		}
+		decisions = append(decisions, decision)
+	}
+
+	return decisions, nil
+}
+
+// findReviewItem locates the item of a decision by ID, falling back to the open item of the version
+func findReviewItem(items []ReviewItem, decision ReviewDecision) int {
+	if decision.ItemID != "" {
+		for i, item := range items {
+			if item.ID == decision.ItemID {
+				return i
+			}
+		}
+	}
+	for i, item := range items {
+		if item.Version == decision.Version && item.IsOpen() {
+			return i
+		}
+	}
+	return -1
+}
+
+// addRejectedCode records a rejected code once
+func addRejectedCode(item *ReviewItem, code string) {
+	if code == "" || code == "NONE" {
+		return
+	}
+	for _, existing := range item.Rejected {
+		if existing == code {
+			return
+		}
+	}
+	item.Rejected = append(item.Rejected, code)
+}
+
+// recordReviewDecisions stores decisions in their queue items; a MODIFY also rules out
+// the suggestion it replaces. Returns the number of decisions recorded
+func recordReviewDecisions(items []ReviewItem, decisions []ReviewDecision) int {
+	recorded := 0
+	for _, decision := range decisions {
+		idx := findReviewItem(items, decision)
+		if idx < 0 {
+			log.Printf("⚠️ No review item for %s (item %s), skipping", decision.Version, decision.ItemID)
+			continue
+		}
+
+		item := &items[idx]
+		item.Status = decision.Action
+		item.Reviewer = decision.Reviewer
+		item.Applied = false
+		item.Updated = time.Now()
+		if decision.Note != "" {
+			item.Note = decision.Note
+		}
+
+		switch decision.Action {
+		case ReviewRejected:
+			item.Decision = "NONE"
+			addRejectedCode(item, decision.Code)
+		case ReviewModified:
+			item.Decision = decision.Code
+			if len(item.Candidates) > 0 && item.Candidates[0].Phelps != decision.Code {
+				addRejectedCode(item, item.Candidates[0].Phelps)
+			}
+		default:
+			item.Decision = decision.Code
+		}
+		recorded++
+	}
+	return recorded
+}
+
+// validateReviewCode checks the format of a reviewer's code and that its work exists
+func validateReviewCode(code string, known map[string]bool) error {
+	if !phelpsCodeRegex.MatchString(code) {
+		return fmt.Errorf("%q is not a valid Phelps code", code)
+	}
+	if phelps, _ := splitPassageCode(code); !known[phelps] {
+		return fmt.Errorf("%s does not exist in the database", code)
+	}
+	return nil
+}
+
+// applyReviewDecisions writes approved and modified codes of the queue to the database and
+// closes rejections; items that fail validation stay pending. Returns applied, rejected, invalid
+func applyReviewDecisions(db Database, items []ReviewItem) (int, int, int) {
+	known := make(map[string]bool)
+	writings := make(map[string]Writing)
+	for _, w := range db.Writing {
+		if w.Phelps != "" {
+			known[w.Phelps] = true
+		}
+		writings[w.Version] = w
+	}
+
+	applied, rejected, invalid := 0, 0, 0
+	for i := range items {
+		item := &items[i]
+		if item.Applied {
+			continue
+		}
+
+		switch item.Status {
+		case ReviewRejected:
+			// Decisions edited directly into the queue may only carry the status
+			if len(item.Rejected) == 0 && len(item.Candidates) > 0 {
+				addRejectedCode(item, item.Candidates[0].Phelps)
+			}
+			if item.Decision == "" {
+				item.Decision = "NONE"
+			}
+			item.Applied = true
+			rejected++
+			log.Printf("   🚫 %s: rejected %s", item.Version, strings.Join(item.Rejected, ", "))
+
+		case ReviewApproved, ReviewModified:
+			if item.Decision == "" && len(item.Candidates) > 0 {
+				item.Decision = item.Candidates[0].Phelps
+			}
+			if err := validateReviewCode(item.Decision, known); err != nil {
+				log.Printf("   ❌ %s (item %s): %v", item.Version, item.ID, err)
+				invalid++
+				continue
+			}
+			target, ok := writings[item.Version]
+			if !ok {
+				log.Printf("   ❌ %s (item %s): prayer not found in database", item.Version, item.ID)
+				invalid++
+				continue
+			}
+			// Only a correction item may replace the real code a prayer has; TMP codes
+			// stay open to any decision
+			phelps, passage := splitPassageCode(item.Decision)
+			if target.Phelps != "" && !isTMPCode(target.Phelps) && target.Phelps != phelps && item.Category != ReviewCorrection {
+				log.Printf("   ⚠️ %s already has %s, not overwriting with %s", item.Version, target.Phelps, item.Decision)
+				invalid++
+				continue
+			}
+			if target.Phelps != phelps {
+				change := ProposedChange{Version: item.Version, Language: target.Language, NewPhelps: phelps, Passage: passage, Confidence: 100,
+					MatchType: "REVIEWED", Reasons: []string{fmt.Sprintf("%s by %s", item.Status, item.Reviewer)}, Source: reviewChangeSource}
+				written, err := writePhelps(change, false)
+				if err != nil {
+					log.Printf("   ❌ Failed to apply %s -> %s: %v", item.Version, item.Decision, err)
+					invalid++
+					continue
+				}
+				if !written {
+					invalid++
+					continue
+				}
+			}
+			item.Applied = true
+			applied++
+			log.Printf("   ✅ %s -> %s (%s by %s)", item.Version, item.Decision, item.Status, item.Reviewer)
+		}
+	}
+
+	return applied, rejected, invalid
+}
+
+// currentReviewer names the person recording decisions
+func currentReviewer() string {
+	if reviewer := os.Getenv("USER"); reviewer != "" {
+		return reviewer
+	}
+	return "reviewer"
+}
+
+// ApplyReviewsCommand records the decisions of edited review files in the review queue and
+// applies all pending decisions of the queue to the database
+func ApplyReviewsCommand(files []string) error {
+	items, err := openReviewQueue()
+	if err != nil {
+		return fmt.Errorf("failed to load review queue: %w", err)
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("review queue %s is empty", reviewQueueFile)
+	}
+
+	reviewer := currentReviewer()
+	for _, file := range files {
+		decisions, err := parseReviewDecisions(file, reviewer)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		recorded := recordReviewDecisions(items, decisions)
+		log.Printf("📝 %s: %d decisions recorded", filepath.Base(file), recorded)
+	}
+
+	// Keep the recorded decisions even if the database is unavailable; a dry run leaves
+	// the queue as it was
+	if !dryRunMode {
+		if err := SaveReviewQueue(reviewQueueFile, items); err != nil {
+			return fmt.Errorf("failed to save review queue: %w", err)
+		}
+	}
+
+	db, err := GetDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load database: %w", err)
+	}
+
+	log.Printf("🔍 Applying review decisions...")
+	applied, rejected, invalid := applyReviewDecisions(db, items)
+	// A dry run keeps the decisions pending until its changeset is applied
+	if !dryRunMode {
+		if err := SaveReviewQueue(reviewQueueFile, items); err != nil {
+			return fmt.Errorf("failed to save review queue: %w", err)
+		}
+	}
+
+	log.Printf("Review decisions applied:")
+	log.Printf("  - Approved codes written: %d", applied)
+	log.Printf("  - Rejections stored: %d", rejected)
+	log.Printf("  - Invalid or conflicting: %d", invalid)
+	return nil
+}
+
+// loadRejectedPairs reads the pairs reviewers rejected; without a queue nothing is rejected
+func loadRejectedPairs() map[string]bool {
+	items, err := LoadReviewQueue(reviewQueueFile)
+	if err != nil {
+		log.Printf("⚠️ Could not read rejected pairs from %s: %v", reviewQueueFile, err)
+	}
+	return RejectedPairs(items)
+}
+
+// DropRejectedMatches removes proposals a reviewer already rejected, adjusting the counters
+func DropRejectedMatches(results *CompressedBatchResponse, rejected map[string]bool) {
+	kept := results.Matches[:0]
+	for _, match := range results.Matches {
+		if !rejected[match.TargetVersion+"|"+match.EnglishPhelps] {
+			kept = append(kept, match)
+			continue
+		}
+		switch match.MatchType {
+		case "EXACT":
+			results.ExactMatches--
+		case "LIKELY":
+			results.LikelyMatches--
+		case "AMBIGUOUS":
+			results.AmbiguousCount--
+		}
+		log.Printf("   🚫 %s -> %s was rejected in review, dropped", match.EnglishPhelps, match.TargetVersion)
+	}
+	results.Matches = kept
+}
+
+// DropRejectedMultiLanguageMatches applies the same filter to ultra batch matches
+func DropRejectedMultiLanguageMatches(matches []MultiLanguageMatchResult, rejected map[string]bool) []MultiLanguageMatchResult {
+	kept := matches[:0]
+	for _, match := range matches {
+		if rejected[match.TargetVersion+"|"+match.EnglishPhelps] {
+			log.Printf("   🚫 %s -> %s (%s) was rejected in review, dropped", match.EnglishPhelps, match.TargetVersion, match.TargetLanguage)
+			continue
+		}
+		kept = append(kept, match)
+	}
+	return kept
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test reading decisions back from an edited review file
+func TestParseReviewDecisions(t *testing.T) {
+	items, _ := EnqueueReviewItems(nil, "es", ReviewAmbiguous, []ReviewProposal{
+		{Version: "es-1", ReviewCandidate: ReviewCandidate{Phelps: "BH00001ONE", Confidence: 70}},
+		{Version: "es-2", ReviewCandidate: ReviewCandidate{Phelps: "AB00002TWO", Confidence: 60}},
+		{Version: "es-3", ReviewCandidate: ReviewCandidate{Phelps: "BH00003THR", Confidence: 55}},
+		{Version: "es-4", ReviewCandidate: ReviewCandidate{Phelps: "BH00004FOU", Confidence: 50}},
+		{Version: "es-5", ReviewCandidate: ReviewCandidate{Phelps: "BH00005FIV", Confidence: 45}},
+	})
+	var report strings.Builder
+	WriteReviewReport(&report, "AMBIGUOUS MATCHES", "es", items)
+
+	// Tick boxes the way a reviewer would in an editor
+	entries := strings.Split(report.String(), "Target Prayer:")
+	edit := func(i int, old, new string) {
+		entries[i] = strings.Replace(entries[i], old, new, 1)
+	}
+	edit(1, "[ ] APPROVE", "[x] APPROVE")
+	edit(1, "Notes: ______", "Notes: same closing blessing")
+	edit(2, "[ ] REJECT", "[X] REJECT")
+	edit(3, "[ ] MODIFY", "[x] MODIFY")
+	edit(3, "Modify to: ______", "Modify to: ab00009nin")
+	edit(4, "[ ] APPROVE [ ] REJECT", "[x] APPROVE [x] REJECT")
+	filename := filepath.Join(t.TempDir(), "review_ambiguous_es.txt")
+	if err := os.WriteFile(filename, []byte(strings.Join(entries, "Target Prayer:")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	decisions, err := parseReviewDecisions(filename, "tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ReviewDecision{
+		{ItemID: "000001", Version: "es-1", Action: ReviewApproved, Code: "BH00001ONE", Note: "same closing blessing", Reviewer: "tester"},
+		{ItemID: "000002", Version: "es-2", Action: ReviewRejected, Code: "AB00002TWO", Reviewer: "tester"},
+		{ItemID: "000003", Version: "es-3", Action: ReviewModified, Code: "AB00009NIN", Reviewer: "tester"},
+	}
+	if len(decisions) != len(want) {
+		t.Fatalf("Expected %d decisions, got %+v", len(want), decisions)
+	}
+	for i := range want {
+		if decisions[i] != want[i] {
+			t.Errorf("Decision %d: got %+v, want %+v", i, decisions[i], want[i])
+		}
+	}
+
+	if recorded := recordReviewDecisions(items, decisions); recorded != 3 {
+		t.Fatalf("Expected 3 decisions recorded, got %d", recorded)
+	}
+	rejected := RejectedPairs(items)
+	if !rejected["es-2|AB00002TWO"] || !rejected["es-3|BH00003THR"] || rejected["es-1|BH00001ONE"] {
+		t.Errorf("Unexpected rejected pairs %v", rejected)
+	}
+	if items[0].Note != "same closing blessing" || items[2].Decision != "AB00009NIN" || !items[3].IsOpen() {
+		t.Errorf("Unexpected items after recording: %+v", items)
+	}
+
+	// A rejected pair is not queued again
+	items, changed := EnqueueReviewItems(items, "es", ReviewAmbiguous, []ReviewProposal{
+		{Version: "es-2", ReviewCandidate: ReviewCandidate{Phelps: "AB00002TWO", Confidence: 75}},
+	})
+	if len(changed) != 0 || len(items) != 5 {
+		t.Errorf("Expected the rejected pair to be left out, got %d changed", len(changed))
+	}
+}
+
+func TestApplyReviewDecisions(t *testing.T) {
+	db := Database{Writing: []Writing{
+		{Version: "en-1", Language: "en", Phelps: "BH00001ONE"},
+		{Version: "es-1", Language: "es", Phelps: "BH00001ONE"},
+		{Version: "es-2", Language: "es"},
+		{Version: "es-3", Language: "es", Phelps: "AB00002TWO"},
+		{Version: "es-4", Language: "es", Phelps: "TMP00007"},
+	}}
+	dryRunMode = true
+	phelpsRows = map[string]phelpsRow{"es-4": {Language: "es", Phelps: "TMP00007"}}
+	defer func() { dryRunMode, proposedChanges, phelpsRows = false, nil, nil }()
+	items := []ReviewItem{
+		{ID: "1", Version: "es-1", Status: ReviewApproved, Decision: "BH00001ONE"},
+		{ID: "2", Version: "es-2", Status: ReviewRejected, Candidates: []ReviewCandidate{{Phelps: "BH00001ONE"}}},
+		{ID: "3", Version: "es-2", Status: ReviewModified, Decision: "not-a-code"},
+		{ID: "4", Version: "es-2", Status: ReviewModified, Decision: "BH99999ZZZ"},
+		{ID: "5", Version: "es-3", Status: ReviewApproved, Decision: "BH00001ONE"},
+		{ID: "6", Version: "es-2", Status: ReviewOpen, Candidates: []ReviewCandidate{{Phelps: "BH00001ONE"}}},
+		{ID: "7", Version: "es-4", Status: ReviewApproved, Decision: "BH00001ONE"},
+	}
+
+	applied, rejected, invalid := applyReviewDecisions(db, items)
+	if applied != 2 || rejected != 1 || invalid != 3 {
+		t.Errorf("Got %d applied, %d rejected, %d invalid; want 2, 1, 3", applied, rejected, invalid)
+	}
+	if !items[0].Applied || !items[1].Applied || items[2].Applied || items[4].Applied || items[5].Applied || !items[6].Applied {
+		t.Errorf("Unexpected applied flags: %+v", items)
+	}
+	if len(items[1].Rejected) != 1 || items[1].Rejected[0] != "BH00001ONE" {
+		t.Errorf("Expected the suggestion stored as rejected, got %v", items[1].Rejected)
+	}
+}
+
+func TestDropRejectedMatches(t *testing.T) {
+	results := CompressedBatchResponse{
+		Matches: []CompressedMatchResult{
+			{EnglishPhelps: "BH00001ONE", TargetVersion: "es-1", MatchType: "EXACT"},
+			{EnglishPhelps: "BH00002TWO", TargetVersion: "es-1", MatchType: "LIKELY"},
+			{EnglishPhelps: "BH00003THR", TargetVersion: "es-2", MatchType: "AMBIGUOUS"},
+		},
+		ExactMatches: 1, LikelyMatches: 1, AmbiguousCount: 1,
+	}
+	rejected := map[string]bool{"es-1|BH00001ONE": true, "es-2|BH00003THR": true}
+
+	DropRejectedMatches(&results, rejected)
+	if len(results.Matches) != 1 || results.Matches[0].EnglishPhelps != "BH00002TWO" {
+		t.Errorf("Unexpected matches %+v", results.Matches)
+	}
+	if results.ExactMatches != 0 || results.LikelyMatches != 1 || results.AmbiguousCount != 0 {
+		t.Errorf("Counters not adjusted: %+v", results)
+	}
+
+	multi := DropRejectedMultiLanguageMatches([]MultiLanguageMatchResult{
+		{EnglishPhelps: "BH00001ONE", TargetVersion: "es-1"},
+		{EnglishPhelps: "BH00001ONE", TargetVersion: "fr-1"},
+	}, rejected)
+	if len(multi) != 1 || multi[0].TargetVersion != "fr-1" {
+		t.Errorf("Unexpected multi-language matches %+v", multi)
+	}
+}
@@ -0,0 +1,556 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The consolidate and export-sql subcommands turn the review queue into a report for
+// picking codes by hand and into SQL updates. Both take their own flags:
+//
+//	prayer-matcher consolidate -language=es,fr -min-confidence=50 -format=json
+//	prayer-matcher export-sql -input=review_queue.jsonl -format=csv -output=-
+
+// reviewExportOptions are the flags shared by both subcommands
+type reviewExportOptions struct {
+	Input         string
+	Languages     map[string]bool // empty means all languages
+	MinConfidence float64
+	Format        string
+	Output        string // "-" writes to stdout
+}
+
+// parseReviewExportFlags adds the shared flags to a subcommand's flag set and parses them;
+// the first format is the default
+func parseReviewExportFlags(fs *flag.FlagSet, args []string, defaultOutput string, minConfidence float64, formats []string) (reviewExportOptions, error) {
+	input := fs.String("input", reviewQueueFile, "Review queue to read")
+	languages := fs.String("language", "", "Only include these languages (comma-separated, e.g. es,fr)")
+	confidence := fs.Float64("min-confidence", minConfidence, "Leave out suggested candidates below this confidence (0-100)")
+	format := fs.String("format", formats[0], "Output format: "+strings.Join(formats, ", "))
+	output := fs.String("output", "", "Output file, - for stdout (default "+defaultOutput+")")
+	if err := fs.Parse(args); err != nil {
+		return reviewExportOptions{}, err
+	}
+
+	opts := reviewExportOptions{
+		Input:         *input,
+		Languages:     make(map[string]bool),
+		MinConfidence: *confidence,
+		Format:        strings.ToLower(*format),
+		Output:        *output,
+	}
+	for _, language := range strings.Split(*languages, ",") {
+		if language = strings.TrimSpace(language); language != "" {
+			opts.Languages[language] = true
+		}
+	}
+
+	valid := false
+	for _, f := range formats {
+		valid = valid || opts.Format == f
+	}
+	if !valid {
+		return opts, fmt.Errorf("unknown format %q (use %s)", *format, strings.Join(formats, ", "))
+	}
+	if opts.Output == "" {
+		opts.Output = strings.ReplaceAll(defaultOutput, "FORMAT", opts.Format)
+	}
+	return opts, nil
+}
+
+// includesLanguage applies the language filter
+func (opts reviewExportOptions) includesLanguage(language string) bool {
+	return len(opts.Languages) == 0 || opts.Languages[language]
+}
+
+// loadReviewExportInput reads the queue and the database; without a database the output
+// lacks prayer texts but is still written
+func loadReviewExportInput(opts reviewExportOptions) ([]ReviewItem, Database, error) {
+	items, err := LoadReviewQueue(opts.Input)
+	if err != nil {
+		return nil, Database{}, fmt.Errorf("failed to read %s: %w", opts.Input, err)
+	}
+	if len(items) == 0 {
+		return nil, Database{}, fmt.Errorf("review queue %s is empty", opts.Input)
+	}
+
+	db, err := GetDatabase()
+	if err != nil {
+		log.Printf("⚠️ Database unavailable, writing without prayer texts: %v", err)
+	}
+	return items, db, nil
+}
+
+// writeReviewExport writes to the output file or stdout
+func writeReviewExport(output string, write func(io.Writer) error) error {
+	if output == "-" {
+		return write(os.Stdout)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	if err := write(w); err != nil {
+		file.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// reviewTextIndex gives the cleaned texts the exports show and align against
+type reviewTextIndex struct {
+	alignmentTexts
+	english    map[string]Writing // phelps -> English reference
+	langPhelps map[string]bool    // language:phelps combinations already in the database
+}
+
+func buildReviewTextIndex(db Database) reviewTextIndex {
+	index := reviewTextIndex{
+		alignmentTexts: buildAlignmentTexts(db),
+		english:        make(map[string]Writing),
+		langPhelps:     make(map[string]bool),
+	}
+	for _, w := range db.Writing {
+		if w.Phelps != "" {
+			index.langPhelps[w.Language+":"+w.Phelps] = true
+		}
+	}
+	for _, w := range index.targets {
+		if w.Phelps != "" && w.Language == "en" {
+			index.english[w.Phelps] = w
+		}
+	}
+	return index
+}
+
+// englishReference returns the English text of a code, cut to its passage for § codes
+func (index reviewTextIndex) englishReference(code string) (Writing, bool) {
+	phelps, passage := splitPassageCode(code)
+	reference, ok := index.english[phelps]
+	if ok && passage > 0 {
+		if passages := SegmentPassages(reference.Text, "en"); passage <= len(passages) {
+			reference.Text = passages[passage-1]
+		}
+	}
+	return reference, ok
+}
+
+// Consolidation ranks candidates by aggregated evidence and shows only the best
+const (
+	defaultConsolidateTop = 5
+	maxEvidenceRuns       = 5 // proposals beyond this add no further evidence
+)
+
+// consolidatedCandidate is a candidate code with its evidence and the English reference
+// lines to compare
+type consolidatedCandidate struct {
+	ReviewCandidate
+	Alignment float64 `json:"alignment"` // -1 when no reference text is available
+	Evidence  float64 `json:"evidence"`
+	Name      string  `json:"name,omitempty"`
+	FirstLine string  `json:"first_line,omitempty"`
+	LastLine  string  `json:"last_line,omitempty"`
+}
+
+// consolidatedPrayer is an open review item with its full text, its best candidates and
+// the codes of the collapsed tail
+type consolidatedPrayer struct {
+	ItemID     string                  `json:"item_id"`
+	Version    string                  `json:"version"`
+	Language   string                  `json:"language"`
+	Name       string                  `json:"name,omitempty"`
+	Text       string                  `json:"text,omitempty"`
+	Candidates []consolidatedCandidate `json:"candidates"`
+	Tail       []string                `json:"tail,omitempty"`
+}
+
+// candidateEvidence combines how often a code was proposed, its max and mean confidence
+// and its alignment with the full target text into a 0-1 score
+func candidateEvidence(candidate ReviewCandidate, alignment float64) float64 {
+	runs, mean := candidate.runs()
+	proposals := float64(min(runs, maxEvidenceRuns)) / maxEvidenceRuns
+	confidence := 0.6*candidate.Confidence/100 + 0.4*mean/100
+	if alignment < 0 {
+		return 0.75*confidence + 0.25*proposals
+	}
+	return 0.45*alignment + 0.4*confidence + 0.15*proposals
+}
+
+// rankConsolidatedCandidates orders candidates by evidence and splits off the tail beyond top
+func rankConsolidatedCandidates(candidates []consolidatedCandidate, top int) ([]consolidatedCandidate, []string) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Evidence != candidates[j].Evidence {
+			return candidates[i].Evidence > candidates[j].Evidence
+		}
+		return candidates[i].Phelps < candidates[j].Phelps
+	})
+	if top <= 0 || len(candidates) <= top {
+		return candidates, nil
+	}
+
+	var tail []string
+	for _, candidate := range candidates[top:] {
+		tail = append(tail, candidate.Phelps)
+	}
+	return candidates[:top], tail
+}
+
+// consolidateReviewItems collects the open items with the candidates worth a look: codes
+// no other version of the language uses yet, not rejected, and at least MinConfidence.
+// Only the top candidates by evidence are kept in full
+func consolidateReviewItems(db Database, items []ReviewItem, opts reviewExportOptions, top int) []consolidatedPrayer {
+	index := buildReviewTextIndex(db)
+
+	var prayers []consolidatedPrayer
+	for _, item := range items {
+		if !item.IsOpen() || !opts.includesLanguage(item.Language) {
+			continue
+		}
+		rejected := make(map[string]bool)
+		for _, code := range item.Rejected {
+			rejected[code] = true
+		}
+
+		prayer := consolidatedPrayer{ItemID: item.ID, Version: item.Version, Language: item.Language}
+		if target, ok := index.targets[item.Version]; ok {
+			prayer.Name, prayer.Text = target.Name, target.Text
+		}
+		var candidates []consolidatedCandidate
+		for _, candidate := range item.Candidates {
+			phelps, _ := splitPassageCode(candidate.Phelps)
+			if candidate.Confidence < opts.MinConfidence || rejected[candidate.Phelps] ||
+				!phelpsCodeRegex.MatchString(candidate.Phelps) || index.langPhelps[item.Language+":"+phelps] {
+				continue
+			}
+			view := consolidatedCandidate{ReviewCandidate: candidate, Alignment: -1}
+			if alignment, ok := index.verify(candidate.Phelps, item.Version); ok {
+				view.Alignment = alignment.Score
+			}
+			view.Evidence = candidateEvidence(candidate, view.Alignment)
+			candidates = append(candidates, view)
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+
+		prayer.Candidates, prayer.Tail = rankConsolidatedCandidates(candidates, top)
+		for i := range prayer.Candidates {
+			if reference, ok := index.englishReference(prayer.Candidates[i].Phelps); ok {
+				prayer.Candidates[i].Name = reference.Name
+				prayer.Candidates[i].FirstLine, prayer.Candidates[i].LastLine = firstAndLastLines(reference.Text)
+			}
+		}
+		prayers = append(prayers, prayer)
+	}
+
+	sort.Slice(prayers, func(i, j int) bool {
+		if prayers[i].Language != prayers[j].Language {
+			return prayers[i].Language < prayers[j].Language
+		}
+		return prayers[i].Version < prayers[j].Version
+	})
+	return prayers
+}
+
+// writeConsolidatedText renders the consolidated report for picking codes by hand
+func writeConsolidatedText(w io.Writer, prayers []consolidatedPrayer) error {
+	fmt.Fprintf(w, "CONSOLIDATED REVIEW - PRAYERS NEEDING PHELPS CODE ASSIGNMENT\n")
+	fmt.Fprintf(w, "Generated: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "Total prayers: %d\n\n", len(prayers))
+
+	fmt.Fprintf(w, "INSTRUCTIONS:\n")
+	fmt.Fprintf(w, "- Each prayer shows the FULL text in the target language\n")
+	fmt.Fprintf(w, "- Below each prayer is a list of candidate Phelps codes\n")
+	fmt.Fprintf(w, "- For each candidate, you see the first and last line of the English reference\n")
+	fmt.Fprintf(w, "- Record your choice for the item with -serve-review or in %s\n\n", reviewQueueFile)
+
+	currentLang := ""
+	for i, prayer := range prayers {
+		if prayer.Language != currentLang {
+			if currentLang != "" {
+				fmt.Fprintf(w, "\n")
+			}
+			currentLang = prayer.Language
+			fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n")
+			fmt.Fprintf(w, "LANGUAGE: %s\n", strings.ToUpper(currentLang))
+			fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+		}
+
+		fmt.Fprintf(w, "───────────────────────────────────────────────────────────────\n")
+		fmt.Fprintf(w, "[%d] Prayer UUID: %s\n", i+1, prayer.Version)
+		fmt.Fprintf(w, "Item: %s\n", prayer.ItemID)
+		if prayer.Text != "" {
+			if prayer.Name != "" {
+				fmt.Fprintf(w, "Name: %s\n", prayer.Name)
+			}
+			fmt.Fprintf(w, "\nFULL TEXT (%s):\n", prayer.Language)
+			fmt.Fprintf(w, "%s\n\n", prayer.Text)
+		} else {
+			fmt.Fprintf(w, "\nFULL TEXT: [Not found in database]\n\n")
+		}
+
+		fmt.Fprintf(w, "CANDIDATE PHELPS CODES (%d options):\n", len(prayer.Candidates)+len(prayer.Tail))
+		for j, candidate := range prayer.Candidates {
+			runs, mean := candidate.runs()
+			fmt.Fprintf(w, "\n  [%d] %s (Confidence: %.0f%%, mean %.0f%% over %d runs, Type: %s)\n",
+				j+1, candidate.Phelps, candidate.Confidence, mean, runs, candidate.MatchType)
+			if candidate.Alignment >= 0 {
+				fmt.Fprintf(w, "      Evidence: %.2f, alignment %.2f\n", candidate.Evidence, candidate.Alignment)
+			} else {
+				fmt.Fprintf(w, "      Evidence: %.2f\n", candidate.Evidence)
+			}
+			if candidate.FirstLine == "" {
+				fmt.Fprintf(w, "      [English reference not found]\n")
+				continue
+			}
+			if candidate.Name != "" {
+				fmt.Fprintf(w, "      Name: %s\n", candidate.Name)
+			}
+			fmt.Fprintf(w, "      First: %s\n", candidate.FirstLine)
+			if candidate.LastLine != candidate.FirstLine {
+				fmt.Fprintf(w, "      Last:  %s\n", candidate.LastLine)
+			}
+		}
+
+		if len(prayer.Tail) > 0 {
+			fmt.Fprintf(w, "\n  [+%d weaker candidates: %s]\n", len(prayer.Tail), strings.Join(prayer.Tail, " "))
+		}
+
+		fmt.Fprintf(w, "\nSelected: ________________\n")
+		fmt.Fprintf(w, "Notes: ___________________________________________________\n")
+		fmt.Fprintf(w, "\n")
+	}
+
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n")
+	fmt.Fprintf(w, "END OF REPORT\n")
+	return nil
+}
+
+// ConsolidateCommand writes all open review items of the queue into one report
+func ConsolidateCommand(args []string) error {
+	defaultOutput := fmt.Sprintf("consolidated_review_%s.FORMAT", time.Now().Format("20060102_150405"))
+	fs := flag.NewFlagSet("consolidate", flag.ContinueOnError)
+	top := fs.Int("top", defaultConsolidateTop, "Candidates shown in full per prayer; the rest are listed by code (0 shows all)")
+	opts, err := parseReviewExportFlags(fs, args, defaultOutput, 0, []string{"text", "json"})
+	if err != nil {
+		return err
+	}
+	if opts.Format == "text" {
+		opts.Output = strings.TrimSuffix(opts.Output, ".text") + ".txt"
+	}
+
+	items, db, err := loadReviewExportInput(opts)
+	if err != nil {
+		return err
+	}
+	prayers := consolidateReviewItems(db, items, opts, *top)
+	log.Printf("📋 %d prayers with open candidates", len(prayers))
+
+	err = writeReviewExport(opts.Output, func(w io.Writer) error {
+		if opts.Format == "json" {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(prayers)
+		}
+		return writeConsolidatedText(w, prayers)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.Output, err)
+	}
+	if opts.Output != "-" {
+		log.Printf("✅ Consolidated report written to %s", opts.Output)
+	}
+	return nil
+}
+
+// phelpsUpdate is one code assignment exported from the review queue
+type phelpsUpdate struct {
+	ItemID       string  `json:"item_id"`
+	Version      string  `json:"version"`
+	Language     string  `json:"language"`
+	Phelps       string  `json:"phelps"`
+	Confidence   float64 `json:"confidence"`
+	MatchType    string  `json:"match_type"`
+	Reviewed     bool    `json:"reviewed"`
+	Candidates   int     `json:"candidates"`
+	Name         string  `json:"name,omitempty"`
+	FirstLine    string  `json:"first_line,omitempty"`
+	EnglishName  string  `json:"english_name,omitempty"`
+	EnglishFirst string  `json:"english_first_line,omitempty"`
+}
+
+// exportPhelpsUpdates picks the code to assign per item: the reviewer's decision for
+// approved and modified items, otherwise the best open candidate reaching MinConfidence.
+// Returns the updates and the number of items without a code to export
+func exportPhelpsUpdates(db Database, items []ReviewItem, opts reviewExportOptions) ([]phelpsUpdate, int) {
+	index := buildReviewTextIndex(db)
+
+	var updates []phelpsUpdate
+	skipped := 0
+	for _, item := range items {
+		if !opts.includesLanguage(item.Language) {
+			continue
+		}
+
+		update := phelpsUpdate{ItemID: item.ID, Version: item.Version, Language: item.Language, Candidates: len(item.Candidates)}
+		switch {
+		case item.Status == ReviewApproved || item.Status == ReviewModified:
+			if !phelpsCodeRegex.MatchString(item.Decision) {
+				skipped++
+				continue
+			}
+			update.Phelps, update.Confidence, update.MatchType, update.Reviewed = item.Decision, 100, "REVIEWED", true
+		case item.IsOpen():
+			rejected := make(map[string]bool)
+			for _, code := range item.Rejected {
+				rejected[code] = true
+			}
+			for _, candidate := range item.Candidates {
+				if candidate.Confidence >= opts.MinConfidence && !rejected[candidate.Phelps] && phelpsCodeRegex.MatchString(candidate.Phelps) {
+					update.Phelps, update.Confidence, update.MatchType = candidate.Phelps, candidate.Confidence, candidate.MatchType
+					break
+				}
+			}
+			if update.Phelps == "" {
+				skipped++
+				continue
+			}
+		default:
+			continue
+		}
+
+		if target, ok := index.targets[item.Version]; ok {
+			update.Name = target.Name
+			update.FirstLine, _ = firstAndLastLines(target.Text)
+		}
+		if reference, ok := index.englishReference(update.Phelps); ok {
+			update.EnglishName = reference.Name
+			update.EnglishFirst, _ = firstAndLastLines(reference.Text)
+		}
+		updates = append(updates, update)
+	}
+
+	sort.SliceStable(updates, func(i, j int) bool {
+		if updates[i].Language != updates[j].Language {
+			return updates[i].Language < updates[j].Language
+		}
+		return updates[i].Version < updates[j].Version
+	})
+	return updates, skipped
+}
+
+// sqlComment keeps a value on one comment line
+func sqlComment(s string, maxLen int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > maxLen {
+		s = string(runes[:maxLen-3]) + "..."
+	}
+	return s
+}
+
+// writePhelpsUpdatesSQL writes UPDATE statements that only fill prayers without a code
+func writePhelpsUpdatesSQL(w io.Writer, updates []phelpsUpdate, skipped int) error {
+	fmt.Fprintf(w, "-- Phelps Code Assignment Updates\n")
+	fmt.Fprintf(w, "-- Generated: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "-- Total prayers: %d\n\n", len(updates))
+
+	for _, update := range updates {
+		fmt.Fprintf(w, "-- Language: %s, Item: %s\n", strings.ToUpper(update.Language), update.ItemID)
+		if update.Name != "" {
+			fmt.Fprintf(w, "-- Prayer name: %s\n", sqlComment(update.Name, 80))
+		}
+		if update.FirstLine != "" {
+			fmt.Fprintf(w, "-- Starts with: %s\n", sqlComment(update.FirstLine, 80))
+		}
+		if update.EnglishName != "" {
+			fmt.Fprintf(w, "-- English: %s\n", sqlComment(update.EnglishName, 80))
+		}
+		if update.EnglishFirst != "" {
+			fmt.Fprintf(w, "-- EN starts: %s\n", sqlComment(update.EnglishFirst, 80))
+		}
+		fmt.Fprintf(w, "-- Confidence: %.0f%%, Type: %s, Candidates: %d\n", update.Confidence, update.MatchType, update.Candidates)
+
+		phelps, passage := splitPassageCode(update.Phelps)
+		if passage > 0 {
+			fmt.Fprintf(w, "-- Excerpt of %s, passage %d\n", phelps, passage)
+		}
+		fmt.Fprintf(w, "UPDATE writings SET phelps = '%s' WHERE version = '%s' AND language = '%s' AND %s;\n\n",
+			strings.ReplaceAll(phelps, "'", "''"),
+			strings.ReplaceAll(update.Version, "'", "''"),
+			strings.ReplaceAll(update.Language, "'", "''"),
+			unmatchedSQL("phelps"))
+	}
+
+	fmt.Fprintf(w, "-- Summary:\n")
+	fmt.Fprintf(w, "-- Updates generated: %d\n", len(updates))
+	fmt.Fprintf(w, "-- Skipped: %d\n", skipped)
+	return nil
+}
+
+// writePhelpsUpdatesCSV writes one row per update
+func writePhelpsUpdatesCSV(w io.Writer, updates []phelpsUpdate) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"item_id", "version", "language", "phelps", "confidence", "match_type", "reviewed", "candidates", "name", "first_line", "english_name", "english_first_line"})
+	for _, u := range updates {
+		writer.Write([]string{
+			u.ItemID, u.Version, u.Language, u.Phelps,
+			strconv.FormatFloat(u.Confidence, 'f', -1, 64), u.MatchType,
+			strconv.FormatBool(u.Reviewed), strconv.Itoa(u.Candidates),
+			u.Name, u.FirstLine, u.EnglishName, u.EnglishFirst,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportSQLCommand exports the code assignments of the review queue
+func ExportSQLCommand(args []string) error {
+	opts, err := parseReviewExportFlags(flag.NewFlagSet("export-sql", flag.ContinueOnError), args, "phelps_code_updates.FORMAT", 40, []string{"sql", "csv", "json"})
+	if err != nil {
+		return err
+	}
+
+	items, db, err := loadReviewExportInput(opts)
+	if err != nil {
+		return err
+	}
+	updates, skipped := exportPhelpsUpdates(db, items, opts)
+
+	err = writeReviewExport(opts.Output, func(w io.Writer) error {
+		switch opts.Format {
+		case "csv":
+			return writePhelpsUpdatesCSV(w, updates)
+		case "json":
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(updates)
+		}
+		return writePhelpsUpdatesSQL(w, updates, skipped)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.Output, err)
+	}
+
+	if opts.Output != "-" {
+		log.Printf("✅ %s written", opts.Output)
+	}
+	log.Printf("Updates generated: %d", len(updates))
+	log.Printf("Skipped: %d", skipped)
+	return nil
+}
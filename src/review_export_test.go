@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// reviewExportFixture is a small database and review queue covering each item status
+func reviewExportFixture() (Database, []ReviewItem) {
+	db := Database{Writing: []Writing{
+		{Version: "en-1", Language: "en", Phelps: "BH00001ONE", Name: "Guidance", Text: "O God, guide me.\n\nThou art the Mighty."},
+		{Version: "en-2", Language: "en", Phelps: "AB00002TWO", Text: "He is God! Praised be Thou."},
+		{Version: "es-0", Language: "es", Phelps: "AB00002TWO", Text: "¡Él es Dios! Alabado seas."},
+		{Version: "es-1", Language: "es", Text: "Oh Dios, guíame.\n\nTú eres el Poderoso."},
+		{Version: "fa-1", Language: "fa", Text: "ای خدا مرا هدایت فرما"},
+	}}
+	items := []ReviewItem{
+		{ID: "000001", Version: "es-1", Language: "es", Status: ReviewOpen, Candidates: []ReviewCandidate{
+			{Phelps: "BH00001ONE", Confidence: 70, MatchType: "AMBIGUOUS"},
+			{Phelps: "AB00002TWO", Confidence: 60, MatchType: "AMBIGUOUS"}, // es already has it
+			{Phelps: "BH00003THR", Confidence: 30, MatchType: "AMBIGUOUS"},
+		}},
+		{ID: "000002", Version: "fa-1", Language: "fa", Status: ReviewOpen, Rejected: []string{"BH00001ONE"}, Candidates: []ReviewCandidate{
+			{Phelps: "BH00001ONE", Confidence: 80, MatchType: "LIKELY"},
+			{Phelps: "BH00004FOU", Confidence: 45, MatchType: "AMBIGUOUS"},
+		}},
+		{ID: "000003", Version: "fr-1", Language: "fr", Status: ReviewModified, Decision: "AB00002TWO"},
+		{ID: "000004", Version: "fr-2", Language: "fr", Status: ReviewRejected, Decision: "NONE"},
+		{ID: "000005", Version: "de-1", Language: "de", Status: ReviewOpen, Candidates: []ReviewCandidate{
+			{Phelps: "BH00001ONE", Confidence: 20, MatchType: "AMBIGUOUS"},
+		}},
+	}
+	return db, items
+}
+
+// Test the consolidate and export-sql subcommands on a small queue
+func TestParseReviewExportFlags(t *testing.T) {
+	opts, err := parseReviewExportFlags(flag.NewFlagSet("export-sql", flag.ContinueOnError), []string{"-language=es, fa", "-min-confidence=55", "-format=CSV"},
+		"phelps_code_updates.FORMAT", 40, []string{"sql", "csv", "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.includesLanguage("fa") || opts.includesLanguage("fr") || opts.MinConfidence != 55 || opts.Output != "phelps_code_updates.csv" {
+		t.Errorf("Unexpected options %+v", opts)
+	}
+
+	if _, err := parseReviewExportFlags(flag.NewFlagSet("consolidate", flag.ContinueOnError), []string{"-format=xml"}, "out.FORMAT", 0, []string{"text", "json"}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestConsolidateReviewItems(t *testing.T) {
+	db, items := reviewExportFixture()
+	prayers := consolidateReviewItems(db, items, reviewExportOptions{MinConfidence: 40, Languages: map[string]bool{}}, 5)
+
+	if len(prayers) != 2 || prayers[0].Version != "es-1" || prayers[1].Version != "fa-1" {
+		t.Fatalf("Expected es-1 and fa-1, got %+v", prayers)
+	}
+	if c := prayers[0].Candidates; len(c) != 1 || c[0].Phelps != "BH00001ONE" || c[0].FirstLine != "O God, guide me." || c[0].Name != "Guidance" {
+		t.Errorf("Unexpected es-1 candidates %+v", c)
+	}
+	if c := prayers[1].Candidates; len(c) != 1 || c[0].Phelps != "BH00004FOU" {
+		t.Errorf("Expected the rejected code left out for fa-1, got %+v", c)
+	}
+
+	var report strings.Builder
+	writeConsolidatedText(&report, prayers)
+	for _, want := range []string{"LANGUAGE: FA", "Item: 000001", "First: O God, guide me.", "[English reference not found]"} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("Report is missing %q", want)
+		}
+	}
+}
+
+func TestRankConsolidatedCandidates(t *testing.T) {
+	// 90 weak one-off suggestions and two codes with real evidence
+	item := ReviewItem{}
+	for i := 0; i < 90; i++ {
+		addReviewCandidate(&item, ReviewCandidate{Phelps: fmt.Sprintf("BH%05dX", i+100), Confidence: 40 + float64(i%6)})
+	}
+	for run := 0; run < 4; run++ {
+		addReviewCandidate(&item, ReviewCandidate{Phelps: "AB00001ONE", Confidence: 55 + float64(run)})
+	}
+	addReviewCandidate(&item, ReviewCandidate{Phelps: "BH00002TWO", Confidence: 50})
+
+	var candidates []consolidatedCandidate
+	for _, c := range item.Candidates {
+		alignment := -1.0
+		if c.Phelps == "BH00002TWO" {
+			alignment = 0.9
+		}
+		candidates = append(candidates, consolidatedCandidate{ReviewCandidate: c, Alignment: alignment, Evidence: candidateEvidence(c, alignment)})
+	}
+
+	shown, tail := rankConsolidatedCandidates(candidates, 3)
+	if len(shown) != 3 || len(tail) != 89 {
+		t.Fatalf("Expected 3 shown and 89 collapsed, got %d and %d", len(shown), len(tail))
+	}
+	if shown[0].Phelps != "BH00002TWO" || shown[1].Phelps != "AB00001ONE" {
+		t.Errorf("Expected the aligned code, then the repeated one, got %s, %s", shown[0].Phelps, shown[1].Phelps)
+	}
+	if runs, mean := shown[1].runs(); runs != 4 || mean != 56.5 || shown[1].Confidence != 58 {
+		t.Errorf("Expected 4 runs at mean 56.5 and max 58, got %d at %.1f and %.0f", runs, mean, shown[1].Confidence)
+	}
+}
+
+func TestExportPhelpsUpdates(t *testing.T) {
+	db, items := reviewExportFixture()
+	updates, skipped := exportPhelpsUpdates(db, items, reviewExportOptions{MinConfidence: 40, Languages: map[string]bool{}})
+
+	var got []string
+	for _, u := range updates {
+		got = append(got, u.Version+"="+u.Phelps)
+	}
+	// Persian is exported like any other language; fr-2 was rejected, de-1 is below the threshold
+	if strings.Join(got, ",") != "es-1=BH00001ONE,fa-1=BH00004FOU,fr-1=AB00002TWO" || skipped != 1 {
+		t.Fatalf("Got %v with %d skipped", got, skipped)
+	}
+	if !updates[2].Reviewed || updates[2].MatchType != "REVIEWED" || updates[0].EnglishFirst != "O God, guide me." {
+		t.Errorf("Unexpected update details %+v", updates)
+	}
+
+	var sql strings.Builder
+	writePhelpsUpdatesSQL(&sql, updates, skipped)
+	if !strings.Contains(sql.String(), "UPDATE writings SET phelps = 'BH00004FOU' WHERE version = 'fa-1' AND language = 'fa' AND (phelps IS NULL OR TRIM(phelps) = '');") {
+		t.Errorf("Unexpected SQL:\n%s", sql.String())
+	}
+
+	var csv strings.Builder
+	if err := writePhelpsUpdatesCSV(&csv, updates); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(csv.String()), "\n"); len(lines) != 4 || !strings.HasPrefix(lines[3], "000003,fr-1,fr,AB00002TWO,100,REVIEWED,true") {
+		t.Errorf("Unexpected CSV:\n%s", csv.String())
+	}
+
+	filtered, _ := exportPhelpsUpdates(db, items, reviewExportOptions{MinConfidence: 10, Languages: map[string]bool{"de": true}})
+	if len(filtered) != 1 || filtered[0].Version != "de-1" {
+		t.Errorf("Expected only de-1 with a lower threshold, got %+v", filtered)
+	}
+}
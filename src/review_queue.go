@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Matches that need a human (or Phase 2) decision are kept as structured records in
+// review_queue.jsonl, one item per target prayer. The review_*.txt files are only a
+// rendering of that queue.
+const reviewQueueFile = "review_queue.jsonl"
+
+// Review item categories and statuses
+const (
+	ReviewAmbiguous     = "AMBIGUOUS"
+	ReviewLowConfidence = "LOW_CONFIDENCE"
+	ReviewCorrection    = "CORRECTION" // a proposal that would replace a real code
+
+	ReviewOpen     = "open"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+	ReviewModified = "modified"
+	ReviewResolved = "resolved"
+)
+
+// ReviewCandidate is one Phelps code proposed for a target prayer
+type ReviewCandidate struct {
+	Phelps         string   `json:"phelps"`
+	Confidence     float64  `json:"confidence"` // highest confidence any run gave
+	MatchType      string   `json:"match_type"`
+	Reasons        []string `json:"reasons,omitempty"`
+	Ambiguity      string   `json:"ambiguity,omitempty"`
+	Proposals      int      `json:"proposals,omitempty"`       // runs that proposed this code
+	MeanConfidence float64  `json:"mean_confidence,omitempty"` // mean over those runs
+}
+
+// runs returns how often the code was proposed; items from before counting began count once
+func (c ReviewCandidate) runs() (int, float64) {
+	if c.Proposals == 0 {
+		return 1, c.Confidence
+	}
+	return c.Proposals, c.MeanConfidence
+}
+
+// ReviewProposal is a proposed match for a target prayer entering the queue
+type ReviewProposal struct {
+	Version string
+	ReviewCandidate
+}
+
+// ReviewItem is a target prayer waiting for a decision
+type ReviewItem struct {
+	ID         string            `json:"id"`
+	Version    string            `json:"version"`
+	Language   string            `json:"language"`
+	Category   string            `json:"category"`
+	Candidates []ReviewCandidate `json:"candidates"`
+	Status     string            `json:"status"`
+	Reviewer   string            `json:"reviewer,omitempty"`
+	Decision   string            `json:"decision,omitempty"` // chosen Phelps code, or NONE
+	Note       string            `json:"note,omitempty"`
+	Rejected   []string          `json:"rejected,omitempty"` // codes a reviewer ruled out for this version
+	Applied    bool              `json:"applied,omitempty"`  // decision has been written to the database
+	Created    time.Time         `json:"created"`
+	Updated    time.Time         `json:"updated,omitempty"`
+}
+
+// IsOpen reports whether the item still needs a decision
+func (item ReviewItem) IsOpen() bool {
+	return item.Status == "" || item.Status == ReviewOpen
+}
+
+// LoadReviewQueue reads all items; a missing queue file is an empty queue
+func LoadReviewQueue(path string) ([]ReviewItem, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var items []ReviewItem
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var item ReviewItem
+		if err := json.Unmarshal([]byte(text), &item); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+// SaveReviewQueue rewrites the queue file atomically
+func SaveReviewQueue(path string, items []ReviewItem) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// RejectedPairs returns the version|code pairs reviewers rejected, which must not be proposed again
+func RejectedPairs(items []ReviewItem) map[string]bool {
+	rejected := make(map[string]bool)
+	for _, item := range items {
+		for _, code := range item.Rejected {
+			rejected[item.Version+"|"+code] = true
+		}
+	}
+	return rejected
+}
+
+// nextReviewID returns the ID following the highest numeric ID in the queue
+func nextReviewID(items []ReviewItem) int {
+	next := 1
+	for _, item := range items {
+		if n, err := strconv.Atoi(item.ID); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return next
+}
+
+// addReviewCandidate adds a candidate to an item; a code proposed again is counted and keeps
+// the details of its most confident proposal. Candidates stay sorted by confidence
+func addReviewCandidate(item *ReviewItem, candidate ReviewCandidate) {
+	for i, existing := range item.Candidates {
+		if existing.Phelps == candidate.Phelps {
+			n, mean := existing.runs()
+			if candidate.Confidence > existing.Confidence {
+				item.Candidates[i] = candidate
+			}
+			item.Candidates[i].Proposals = n + 1
+			item.Candidates[i].MeanConfidence = (mean*float64(n) + candidate.Confidence) / float64(n+1)
+			sortReviewCandidates(item)
+			return
+		}
+	}
+	candidate.Proposals, candidate.MeanConfidence = 1, candidate.Confidence
+	item.Candidates = append(item.Candidates, candidate)
+	sortReviewCandidates(item)
+}
+
+// sortReviewCandidates orders candidates by confidence
+func sortReviewCandidates(item *ReviewItem) {
+	sort.SliceStable(item.Candidates, func(i, j int) bool {
+		return item.Candidates[i].Confidence > item.Candidates[j].Confidence
+	})
+}
+
+// EnqueueReviewItems merges candidates into the open item of each version or opens
+// a new item, and returns the items that were touched; rejected pairs are left out
+func EnqueueReviewItems(items []ReviewItem, language, category string, proposals []ReviewProposal) ([]ReviewItem, []ReviewItem) {
+	now := time.Now()
+	rejected := RejectedPairs(items)
+	open := make(map[string]int)
+	for i, item := range items {
+		if item.IsOpen() && item.Language == language {
+			open[item.Version] = i
+		}
+	}
+
+	touched := make(map[int]bool)
+	var order []int
+	next := nextReviewID(items)
+	for _, proposal := range proposals {
+		if proposal.Version == "" || rejected[proposal.Version+"|"+proposal.Phelps] {
+			continue
+		}
+		idx, ok := open[proposal.Version]
+		if !ok {
+			items = append(items, ReviewItem{
+				ID:       fmt.Sprintf("%06d", next),
+				Version:  proposal.Version,
+				Language: language,
+				Category: category,
+				Status:   ReviewOpen,
+				Created:  now,
+			})
+			next++
+			idx = len(items) - 1
+			open[proposal.Version] = idx
+		}
+
+		item := &items[idx]
+		if proposal.Phelps != "" {
+			addReviewCandidate(item, proposal.ReviewCandidate)
+		}
+		if category == ReviewAmbiguous {
+			item.Category = ReviewAmbiguous
+		}
+		item.Updated = now
+		if !touched[idx] {
+			touched[idx] = true
+			order = append(order, idx)
+		}
+	}
+
+	var changed []ReviewItem
+	for _, idx := range order {
+		changed = append(changed, items[idx])
+	}
+	return items, changed
+}
+
+// ReviewedVersions returns the versions of a language that are in the queue, with any status
+func ReviewedVersions(items []ReviewItem, language string) map[string]bool {
+	versions := make(map[string]bool)
+	for _, item := range items {
+		if item.Language == language {
+			versions[item.Version] = true
+		}
+	}
+	return versions
+}
+
+// WriteReviewReport renders queue items as the human-readable review file
+func WriteReviewReport(w io.Writer, title, language string, items []ReviewItem) {
+	fmt.Fprintf(w, "%s - %s\n", title, strings.ToUpper(language))
+	fmt.Fprintf(w, "Generated: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "Total items: %d\n\n", len(items))
+	fmt.Fprintf(w, "INSTRUCTIONS:\n")
+	fmt.Fprintf(w, "- Review each match below\n")
+	fmt.Fprintf(w, "- Confidence ranges: 0%% (no match) to 100%% (perfect match)\n")
+	fmt.Fprintf(w, "- For ambiguous matches, check the suggested reasons\n")
+	fmt.Fprintf(w, "- Verify Phelps codes against English reference\n")
+	fmt.Fprintf(w, "- Tick one box with [x]; for MODIFY write the correct code after \"Modify to:\"\n")
+	fmt.Fprintf(w, "- Apply decisions with: -apply-reviews <this file>\n")
+	fmt.Fprintf(w, "- Decisions are stored in %s under the item ID\n\n", reviewQueueFile)
+	fmt.Fprintf(w, "═══════════════════════════════════════════════════════════════\n\n")
+
+	for i, item := range items {
+		fmt.Fprintf(w, "[%d/%d] Target Prayer: %s\n", i+1, len(items), item.Version)
+		fmt.Fprintf(w, "Item: %s\n", item.ID)
+		if len(item.Candidates) > 0 {
+			best := item.Candidates[0]
+			fmt.Fprintf(w, "Suggested Phelps: %s\n", best.Phelps)
+			fmt.Fprintf(w, "Match Type: %s\n", best.MatchType)
+			fmt.Fprintf(w, "Confidence: %.0f%%\n", best.Confidence)
+			if len(best.Reasons) > 0 {
+				fmt.Fprintf(w, "Reasons: %s\n", strings.Join(best.Reasons, ", "))
+			}
+			if best.Ambiguity != "" {
+				fmt.Fprintf(w, "Ambiguity: %s\n", best.Ambiguity)
+			}
+			for _, other := range item.Candidates[1:] {
+				fmt.Fprintf(w, "Also: %s (%.0f%%, %s)\n", other.Phelps, other.Confidence, other.MatchType)
+			}
+		}
+		if !item.IsOpen() {
+			fmt.Fprintf(w, "Resolved: %s -> %s", item.Status, item.Decision)
+			if item.Reviewer != "" {
+				fmt.Fprintf(w, " by %s", item.Reviewer)
+			}
+			fmt.Fprintf(w, "\n")
+		}
+
+		fmt.Fprintf(w, "Action needed: [ ] APPROVE [ ] REJECT [ ] MODIFY\n")
+		fmt.Fprintf(w, "Modify to: ______________________________________\n")
+		fmt.Fprintf(w, "Notes: ______________________________________\n")
+		fmt.Fprintf(w, "───────────────────────────────────────────────────────────────\n\n")
+	}
+}
+
+var reviewConfidenceRegex = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// legacyReviewEntry is one entry of a review file written before the queue existed
+type legacyReviewEntry struct {
+	Version    string
+	Phelps     string
+	MatchType  string
+	Confidence float64
+	Reasons    string
+	Ambiguity  string
+	Resolved   bool
+}
+
+// parseLegacyReviewFile reads the entries of an old review_ambiguous / review_low_confidence file
+func parseLegacyReviewFile(filename string) ([]legacyReviewEntry, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []legacyReviewEntry
+	var current *legacyReviewEntry
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, _ := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+
+		if strings.Contains(key, "Target Prayer") {
+			if current != nil {
+				entries = append(entries, *current)
+			}
+			current = &legacyReviewEntry{Version: value}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch strings.TrimSpace(key) {
+		case "Suggested Phelps":
+			current.Phelps = value
+		case "Match Type":
+			current.MatchType = value
+		case "Confidence":
+			// Older files printed the float with %d, e.g. "%!d(float64=72.5)%"
+			if _, after, ok := strings.Cut(value, "="); ok {
+				value = after
+			}
+			if m := reviewConfidenceRegex.FindString(value); m != "" {
+				current.Confidence, _ = strconv.ParseFloat(m, 64)
+			}
+		case "Reasons":
+			current.Reasons = value
+		case "Ambiguity":
+			current.Ambiguity = value
+		case "Resolved":
+			current.Resolved = true
+		}
+	}
+	if current != nil {
+		entries = append(entries, *current)
+	}
+
+	return entries, nil
+}
+
+// legacyReviewFileRegex extracts category and language from old review file names
+var legacyReviewFileRegex = regexp.MustCompile(`review_(ambiguous|low_confidence)_(.+?)_\d{8}_\d{6}\.txt$`)
+
+// ImportLegacyReviewFiles adds the entries of old review text files to the queue
+func ImportLegacyReviewFiles(items []ReviewItem, files []string) ([]ReviewItem, int) {
+	imported := 0
+	for _, file := range files {
+		m := legacyReviewFileRegex.FindStringSubmatch(file)
+		if m == nil {
+			continue
+		}
+		category, language := ReviewAmbiguous, m[2]
+		if m[1] == "low_confidence" {
+			category = ReviewLowConfidence
+		}
+
+		entries, err := parseLegacyReviewFile(file)
+		if err != nil {
+			continue
+		}
+		var proposals []ReviewProposal
+		for _, entry := range entries {
+			if entry.Resolved {
+				continue
+			}
+			proposal := ReviewProposal{Version: entry.Version, ReviewCandidate: ReviewCandidate{
+				Phelps:     entry.Phelps,
+				Confidence: entry.Confidence,
+				MatchType:  entry.MatchType,
+				Ambiguity:  entry.Ambiguity,
+			}}
+			if entry.Reasons != "" {
+				proposal.Reasons = strings.Split(entry.Reasons, ", ")
+			}
+			proposals = append(proposals, proposal)
+		}
+		items, _ = EnqueueReviewItems(items, language, category, proposals)
+		imported += len(proposals)
+	}
+	return items, imported
+}
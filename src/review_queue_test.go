@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test merging proposals into review queue items
+func TestEnqueueReviewItems(t *testing.T) {
+	proposal := func(version, phelps string, confidence float64) ReviewProposal {
+		return ReviewProposal{Version: version, ReviewCandidate: ReviewCandidate{Phelps: phelps, Confidence: confidence, MatchType: "AMBIGUOUS"}}
+	}
+
+	items, changed := EnqueueReviewItems(nil, "es", ReviewAmbiguous, []ReviewProposal{
+		proposal("es-1", "BH00001ONE", 60),
+		proposal("es-1", "BH00002TWO", 72),
+		proposal("es-2", "AB00003THR", 65),
+	})
+	if len(items) != 2 || len(changed) != 2 {
+		t.Fatalf("Expected 2 items, got %d (%d changed)", len(items), len(changed))
+	}
+	if items[0].ID != "000001" || items[1].ID != "000002" {
+		t.Errorf("Unexpected IDs %q and %q", items[0].ID, items[1].ID)
+	}
+	if items[0].Candidates[0].Phelps != "BH00002TWO" {
+		t.Errorf("Expected candidates sorted by confidence, got %+v", items[0].Candidates)
+	}
+
+	// A later run adds to the open item; a resolved item gets a new one
+	items[1].Status = ReviewResolved
+	items, changed = EnqueueReviewItems(items, "es", ReviewLowConfidence, []ReviewProposal{
+		proposal("es-1", "BH00001ONE", 68),
+		proposal("es-2", "AB00003THR", 50),
+	})
+	if len(items) != 3 || len(changed) != 2 {
+		t.Fatalf("Expected 3 items with 2 changed, got %d and %d", len(items), len(changed))
+	}
+	if len(items[0].Candidates) != 2 || items[0].Candidates[1].Confidence != 68 {
+		t.Errorf("Expected the higher confidence kept for a repeated code, got %+v", items[0].Candidates)
+	}
+	if items[0].Category != ReviewAmbiguous || items[2].ID != "000003" || items[2].Category != ReviewLowConfidence {
+		t.Errorf("Unexpected categories or IDs: %+v", items)
+	}
+}
+
+func TestReviewQueueRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), reviewQueueFile)
+
+	if items, err := LoadReviewQueue(path); err != nil || items != nil {
+		t.Fatalf("Expected a missing queue to be empty, got %v, %v", items, err)
+	}
+
+	items, _ := EnqueueReviewItems(nil, "fa", ReviewAmbiguous, []ReviewProposal{
+		{Version: "fa-1", ReviewCandidate: ReviewCandidate{Phelps: "BH00001ONE", Confidence: 70, Reasons: []string{"opening's phrase"}}},
+	})
+	items[0].Status, items[0].Reviewer, items[0].Decision = ReviewApproved, "reviewer", "BH00001ONE"
+	if err := SaveReviewQueue(path, items); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadReviewQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0].Decision != "BH00001ONE" || loaded[0].IsOpen() || loaded[0].Candidates[0].Reasons[0] != "opening's phrase" {
+		t.Errorf("Unexpected queue after reload: %+v", loaded)
+	}
+}
+
+func TestImportLegacyReviewFiles(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "review_ambiguous_zh-Hans_20250101_120000.txt")
+	legacy := `AMBIGUOUS MATCHES - ZH-HANS
+Total items: 2
+
+[1/2] Target Prayer: zh-1
+Suggested Phelps: BH00001ONE
+Match Type: AMBIGUOUS
+Confidence: %!d(float64=72.5)%
+Reasons: key_terms_overlap, structure_match
+Ambiguity: could also be BH00002TWO
+Action needed: [ ] APPROVE [ ] REJECT [ ] MODIFY
+───────────────────────────────────────────────────────────────
+
+[2/2] Target Prayer: zh-2
+Suggested Phelps: AB00003THR
+Confidence: 65%
+Resolved: NONE
+Action needed: [ ] APPROVE [ ] REJECT [ ] MODIFY
+`
+	if err := os.WriteFile(filename, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	items, imported := ImportLegacyReviewFiles(nil, []string{filename})
+	if imported != 1 || len(items) != 1 {
+		t.Fatalf("Expected the one open entry imported, got %d (%d items)", imported, len(items))
+	}
+	item := items[0]
+	if item.Language != "zh-Hans" || item.Version != "zh-1" || item.Category != ReviewAmbiguous {
+		t.Errorf("Unexpected item %+v", item)
+	}
+	if c := item.Candidates[0]; c.Confidence != 72.5 || len(c.Reasons) != 2 || c.Ambiguity != "could also be BH00002TWO" {
+		t.Errorf("Unexpected candidate %+v", c)
+	}
+
+	// Reading imports the files in memory without creating the queue
+	t.Chdir(dir)
+	opened, err := openReviewQueue()
+	if err != nil || len(opened) != 1 || opened[0].ID != item.ID {
+		t.Errorf("Expected the legacy entry from openReviewQueue, got %+v (%v)", opened, err)
+	}
+	if _, err := os.Stat(reviewQueueFile); !os.IsNotExist(err) {
+		t.Errorf("Expected no %s after reading, got %v", reviewQueueFile, err)
+	}
+
+	var report strings.Builder
+	WriteReviewReport(&report, "AMBIGUOUS MATCHES", "zh-Hans", items)
+	if !strings.Contains(report.String(), "Item: 000001") || !strings.Contains(report.String(), "Confidence: 72%") {
+		t.Errorf("Expected the rendering to show item ID and confidence, got:\n%s", report.String())
+	}
+}
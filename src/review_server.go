@@ -0,0 +1,538 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The review UI serves the review queue over a local HTTP server. Decisions made in the
+// browser go through recordReviewDecisions and applyReviewDecisions, like -apply-reviews.
+
+// reviewServer holds the queue and the texts shown next to it
+type reviewServer struct {
+	mu        sync.Mutex
+	addr      string // address served, checked against the Host of decisions; empty skips the check
+	queuePath string
+	reviewer  string
+	db        Database
+	texts     alignmentTexts
+	items     []ReviewItem
+}
+
+// reviewSummary is one row of the item list
+type reviewSummary struct {
+	ID         string  `json:"id"`
+	Version    string  `json:"version"`
+	Language   string  `json:"language"`
+	Category   string  `json:"category"`
+	MatchType  string  `json:"match_type"`
+	Confidence float64 `json:"confidence"`
+	Status     string  `json:"status"`
+	Decision   string  `json:"decision,omitempty"`
+	Applied    bool    `json:"applied"`
+}
+
+// reviewProgress counts decided items among those matching the filters
+type reviewProgress struct {
+	Total   int `json:"total"`
+	Decided int `json:"decided"`
+}
+
+// reviewListResponse is the item list with the available filter values
+type reviewListResponse struct {
+	Items      []reviewSummary `json:"items"`
+	Languages  []string        `json:"languages"`
+	MatchTypes []string        `json:"match_types"`
+	Progress   reviewProgress  `json:"progress"`
+}
+
+// reviewCandidateView is a candidate with its reference text and its opening and closing
+// lines, to be read against the target's
+type reviewCandidateView struct {
+	ReviewCandidate
+	Text      string  `json:"text"`
+	FirstLine string  `json:"first_line"`
+	LastLine  string  `json:"last_line"`
+	Alignment float64 `json:"alignment"` // -1 when no reference text is available
+}
+
+// reviewDetail is everything the UI shows for one item
+type reviewDetail struct {
+	Item       ReviewItem            `json:"item"`
+	Text       string                `json:"text"`
+	FirstLine  string                `json:"first_line"`
+	LastLine   string                `json:"last_line"`
+	Candidates []reviewCandidateView `json:"candidates"`
+}
+
+// reviewDecisionRequest is a decision posted by the UI; choosing a candidate other than
+// the suggestion is sent as modify with its code
+type reviewDecisionRequest struct {
+	Action string `json:"action"` // approve, reject or modify
+	Code   string `json:"code"`
+	Note   string `json:"note"`
+}
+
+// reviewDecisionResponse reports whether the decision reached the database
+type reviewDecisionResponse struct {
+	Item    ReviewItem `json:"item"`
+	Applied bool       `json:"applied"`
+	Message string     `json:"message"`
+}
+
+func newReviewServer(queuePath, reviewer string, db Database, items []ReviewItem) *reviewServer {
+	return &reviewServer{
+		queuePath: queuePath,
+		reviewer:  reviewer,
+		db:        db,
+		texts:     buildAlignmentTexts(db),
+		items:     items,
+	}
+}
+
+func (s *reviewServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /api/items", s.handleItems)
+	mux.HandleFunc("GET /api/items/{id}", s.handleItem)
+	mux.HandleFunc("POST /api/items/{id}/decision", s.handleDecision)
+	return mux
+}
+
+// firstAndLastLines returns the opening and closing sentence of a text
+func firstAndLastLines(text string) (string, string) {
+	units := sentenceUnits(text)
+	if len(units) == 0 {
+		return "", ""
+	}
+	return units[0], units[len(units)-1]
+}
+
+// reviewMatchType is the match type an item is filtered by
+func reviewMatchType(item ReviewItem) string {
+	if len(item.Candidates) > 0 && item.Candidates[0].MatchType != "" {
+		return item.Candidates[0].MatchType
+	}
+	return item.Category
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("⚠️ Failed to write response: %v", err)
+	}
+}
+
+func (s *reviewServer) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, reviewPageHTML)
+}
+
+// handleItems lists items filtered by language, match type and status (open, decided or all)
+func (s *reviewServer) handleItems(w http.ResponseWriter, r *http.Request) {
+	language := r.URL.Query().Get("language")
+	matchType := r.URL.Query().Get("type")
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "open"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	response := reviewListResponse{Items: []reviewSummary{}}
+	languages := make(map[string]bool)
+	matchTypes := make(map[string]bool)
+	for _, item := range s.items {
+		languages[item.Language] = true
+		itemType := reviewMatchType(item)
+		matchTypes[itemType] = true
+
+		if (language != "" && item.Language != language) || (matchType != "" && itemType != matchType) {
+			continue
+		}
+		response.Progress.Total++
+		if !item.IsOpen() {
+			response.Progress.Decided++
+		}
+		if (status == "open" && !item.IsOpen()) || (status == "decided" && item.IsOpen()) {
+			continue
+		}
+
+		summary := reviewSummary{
+			ID:        item.ID,
+			Version:   item.Version,
+			Language:  item.Language,
+			Category:  item.Category,
+			MatchType: itemType,
+			Status:    item.Status,
+			Decision:  item.Decision,
+			Applied:   item.Applied,
+		}
+		if len(item.Candidates) > 0 {
+			summary.Confidence = item.Candidates[0].Confidence
+		}
+		response.Items = append(response.Items, summary)
+	}
+
+	for language := range languages {
+		response.Languages = append(response.Languages, language)
+	}
+	for matchType := range matchTypes {
+		response.MatchTypes = append(response.MatchTypes, matchType)
+	}
+	sort.Strings(response.Languages)
+	sort.Strings(response.MatchTypes)
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// itemIndex finds an item by ID; the caller holds the lock
+func (s *reviewServer) itemIndex(id string) int {
+	for i, item := range s.items {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// detail builds the side-by-side view of an item; the caller holds the lock
+func (s *reviewServer) detail(item ReviewItem) reviewDetail {
+	detail := reviewDetail{Item: item, Candidates: []reviewCandidateView{}}
+	if target, ok := s.texts.targets[item.Version]; ok {
+		detail.Text = target.Text
+		detail.FirstLine, detail.LastLine = firstAndLastLines(target.Text)
+	}
+
+	for _, candidate := range item.Candidates {
+		view := reviewCandidateView{ReviewCandidate: candidate, Alignment: -1}
+		phelps, passage := splitPassageCode(candidate.Phelps)
+		if text, ok := s.texts.references[phelps]; ok {
+			if passage > 0 {
+				if passages := SegmentPassages(text, s.texts.referenceLangs[phelps]); passage <= len(passages) {
+					text = passages[passage-1]
+				}
+			}
+			view.Text = text
+			view.FirstLine, view.LastLine = firstAndLastLines(text)
+		}
+		if alignment, ok := s.texts.verify(candidate.Phelps, item.Version); ok {
+			view.Alignment = alignment.Score
+		}
+		detail.Candidates = append(detail.Candidates, view)
+	}
+	return detail
+}
+
+func (s *reviewServer) handleItem(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.itemIndex(r.PathValue("id"))
+	if idx < 0 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no such review item"})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.detail(s.items[idx]))
+}
+
+// loopbackHost reports whether a host name refers to this machine
+func loopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// servedHost reports whether a request Host names the served address, allowing the
+// loopback names of a server bound to localhost or to all interfaces
+func (s *reviewServer) servedHost(host string) bool {
+	if s.addr == "" || host == s.addr {
+		return true
+	}
+	servedName, servedPort, err := net.SplitHostPort(s.addr)
+	if err != nil {
+		return false
+	}
+	name, port, err := net.SplitHostPort(host)
+	if err != nil || port != servedPort || !loopbackHost(name) {
+		return false
+	}
+	ip := net.ParseIP(servedName)
+	return servedName == "" || loopbackHost(servedName) || (ip != nil && ip.IsUnspecified())
+}
+
+// checkSameOrigin refuses decisions that did not come from the page this server serves:
+// a cross-site form can only post text/plain or form bodies, and a page on a rebound
+// domain carries its own Host
+func (s *reviewServer) checkSameOrigin(r *http.Request) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return fmt.Errorf("content type must be application/json")
+	}
+	if !s.servedHost(r.Host) {
+		return fmt.Errorf("host %q is not the served address %s", r.Host, s.addr)
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+			return fmt.Errorf("origin %q does not match host %s", origin, r.Host)
+		}
+	}
+	return nil
+}
+
+// handleDecision records a decision, applies it and saves the queue
+func (s *reviewServer) handleDecision(w http.ResponseWriter, r *http.Request) {
+	if err := s.checkSameOrigin(r); err != nil {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return
+	}
+
+	var request reviewDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.itemIndex(r.PathValue("id"))
+	if idx < 0 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no such review item"})
+		return
+	}
+	item := &s.items[idx]
+
+	decision := ReviewDecision{ItemID: item.ID, Version: item.Version, Note: strings.TrimSpace(request.Note), Reviewer: s.reviewer}
+	suggested := ""
+	if len(item.Candidates) > 0 {
+		suggested = item.Candidates[0].Phelps
+	}
+	code := strings.ToUpper(strings.TrimSpace(request.Code))
+	switch request.Action {
+	case "approve":
+		decision.Action, decision.Code = ReviewApproved, suggested
+	case "reject":
+		decision.Action, decision.Code = ReviewRejected, suggested
+	case "modify":
+		if code == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "modify needs a code"})
+			return
+		}
+		decision.Action, decision.Code = ReviewModified, code
+		if code == suggested {
+			decision.Action = ReviewApproved
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("unknown action %q", request.Action)})
+		return
+	}
+
+	recordReviewDecisions(s.items[idx:idx+1], []ReviewDecision{decision})
+	applied, rejected, _ := applyReviewDecisions(s.db, s.items[idx:idx+1])
+	if applied > 0 {
+		s.markMatched(item.Version, item.Decision)
+	}
+	if err := SaveReviewQueue(s.queuePath, s.items); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save review queue: " + err.Error()})
+		return
+	}
+
+	response := reviewDecisionResponse{Item: *item, Applied: applied+rejected > 0}
+	switch {
+	case applied > 0:
+		response.Message = fmt.Sprintf("%s -> %s written", item.Version, item.Decision)
+	case rejected > 0:
+		response.Message = fmt.Sprintf("rejection of %s stored", strings.Join(item.Rejected, ", "))
+	default:
+		response.Message = fmt.Sprintf("%s recorded but not applied: invalid code or prayer already matched (see server log)", item.Decision)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// markMatched keeps the in-memory database in step with a written code; the caller holds the lock
+func (s *reviewServer) markMatched(version, code string) {
+	phelps, _ := splitPassageCode(code)
+	for i := range s.db.Writing {
+		if s.db.Writing[i].Version == version {
+			s.db.Writing[i].Phelps = phelps
+		}
+	}
+	if target, ok := s.texts.targets[version]; ok {
+		target.Phelps = phelps
+		s.texts.targets[version] = target
+	}
+}
+
+// ServeReviewCommand starts the review UI on addr; every decision is written as it is
+// made, so it cannot run as a dry run
+func ServeReviewCommand(addr string) error {
+	if dryRunMode {
+		return fmt.Errorf("-serve-review applies each decision as it is made and cannot be combined with -dry-run")
+	}
+	items, err := openReviewQueue()
+	if err != nil {
+		return fmt.Errorf("failed to load review queue: %w", err)
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("review queue %s is empty", reviewQueueFile)
+	}
+
+	db, err := GetDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to load database: %w", err)
+	}
+
+	server := newReviewServer(reviewQueueFile, currentReviewer(), db, items)
+	server.addr = addr
+	log.Printf("🌐 Review UI for %d items at http://%s (Ctrl+C to stop)", len(items), addr)
+	return http.ListenAndServe(addr, server.routes())
+}
+
+const reviewPageHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Prayer match review</title>
+<style>
+body { font-family: sans-serif; margin: 0; display: flex; flex-direction: column; height: 100vh; }
+header { padding: 8px 12px; background: #234; color: #fff; display: flex; gap: 12px; align-items: center; }
+header select { font-size: 14px; }
+#progress { flex: 1; display: flex; align-items: center; gap: 8px; }
+#bar { flex: 1; height: 8px; background: #456; }
+#bar div { height: 100%; background: #6c6; }
+main { flex: 1; display: flex; overflow: hidden; }
+#list { width: 260px; overflow-y: auto; border-right: 1px solid #ccc; }
+#list div { padding: 6px 10px; cursor: pointer; border-bottom: 1px solid #eee; font-size: 13px; }
+#list div.current { background: #def; }
+#list div.decided { color: #888; }
+#detail { flex: 1; overflow-y: auto; padding: 12px; }
+.columns { display: flex; gap: 12px; align-items: flex-start; }
+.column { flex: 1; border: 1px solid #ccc; padding: 8px; min-width: 0; }
+.column h3 { margin: 0 0 6px 0; font-size: 15px; }
+.lines { background: #f6f6f0; padding: 6px; margin-bottom: 6px; font-size: 14px; }
+.lines p { margin: 2px 0; }
+.meta { font-size: 12px; color: #555; margin-bottom: 6px; }
+pre { white-space: pre-wrap; font-family: inherit; font-size: 13px; }
+#actions { margin: 12px 0; display: flex; gap: 8px; align-items: center; }
+#note { flex: 1; }
+#message { font-size: 13px; color: #353; }
+kbd { border: 1px solid #999; border-radius: 3px; padding: 0 3px; font-size: 11px; }
+</style>
+</head>
+<body>
+<header>
+  <strong>Review</strong>
+  <select id="language"><option value="">All languages</option></select>
+  <select id="type"><option value="">All match types</option></select>
+  <select id="status"><option value="open">Open</option><option value="decided">Decided</option><option value="all">All</option></select>
+  <div id="progress"><span id="count"></span><div id="bar"><div></div></div></div>
+  <span><kbd>a</kbd> approve <kbd>r</kbd> reject <kbd>1</kbd>-<kbd>9</kbd> choose <kbd>m</kbd> modify <kbd>n</kbd> note <kbd>j</kbd>/<kbd>k</kbd> next/previous</span>
+</header>
+<main>
+  <div id="list"></div>
+  <div id="detail"></div>
+</main>
+<script>
+let items = [], current = -1, detail = null;
+const $ = id => document.getElementById(id);
+const esc = s => (s || "").replace(/[&<>"]/g, c => ({"&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;"}[c]));
+
+function fillSelect(select, values) {
+  const keep = select.value;
+  while (select.options.length > 1) select.remove(1);
+  values.forEach(v => select.add(new Option(v, v)));
+  select.value = keep;
+}
+
+async function loadList(keepID) {
+  const query = new URLSearchParams({language: $("language").value, type: $("type").value, status: $("status").value});
+  const data = await (await fetch("/api/items?" + query)).json();
+  items = data.items;
+  fillSelect($("language"), data.languages || []);
+  fillSelect($("type"), data.match_types || []);
+  const p = data.progress;
+  $("count").textContent = p.decided + " / " + p.total + " decided";
+  $("bar").firstElementChild.style.width = (p.total ? 100 * p.decided / p.total : 0) + "%";
+  $("list").innerHTML = items.map((it, i) =>
+    '<div data-i="' + i + '" class="' + (it.status === "open" ? "" : "decided") + '">' +
+    esc(it.version) + " (" + esc(it.language) + ")<br><small>" + esc(it.match_type) + " " + Math.round(it.confidence) + "%" +
+    (it.decision ? " → " + esc(it.decision) : "") + "</small></div>").join("");
+  let next = items.findIndex(it => it.id === keepID);
+  if (next < 0) next = Math.min(Math.max(current, 0), items.length - 1);
+  select(next);
+}
+
+async function select(i) {
+  current = i;
+  document.querySelectorAll("#list div").forEach(el => el.classList.toggle("current", +el.dataset.i === i));
+  if (i < 0 || i >= items.length) { detail = null; $("detail").innerHTML = "<p>Nothing to review.</p>"; return; }
+  detail = await (await fetch("/api/items/" + items[i].id)).json();
+  const item = detail.item;
+  let html = "<h2>" + esc(item.version) + " <small>" + esc(item.language) + " · item " + esc(item.id) + " · " + esc(item.status) +
+    (item.decision ? " → " + esc(item.decision) : "") + "</small></h2>";
+  html += '<div id="actions"><input id="note" placeholder="Note" value="' + esc(item.note) + '"><span id="message"></span></div>';
+  html += '<div class="columns"><div class="column"><h3>Target prayer</h3>' +
+    '<div class="lines"><p><b>First:</b> ' + esc(detail.first_line) + '</p><p><b>Last:</b> ' + esc(detail.last_line) + "</p></div>" +
+    "<pre>" + esc(detail.text) + "</pre></div>";
+  detail.candidates.forEach((c, n) => {
+    html += '<div class="column"><h3>' + (n + 1) + ". " + esc(c.phelps) + "</h3>" +
+      '<div class="meta">' + esc(c.match_type) + " · " + Math.round(c.confidence) + "%" +
+      (c.alignment >= 0 ? " · alignment " + c.alignment.toFixed(2) : "") +
+      (c.reasons ? "<br>" + esc(c.reasons.join(", ")) : "") + (c.ambiguity ? "<br>" + esc(c.ambiguity) : "") + "</div>" +
+      '<div class="lines"><p><b>First:</b> ' + esc(c.first_line) + '</p><p><b>Last:</b> ' + esc(c.last_line) + "</p></div>" +
+      "<details><summary>Full text</summary><pre>" + esc(c.text) + "</pre></details></div>";
+  });
+  html += "</div>";
+  $("detail").innerHTML = html;
+}
+
+async function decide(action, code) {
+  if (!detail) return;
+  const id = detail.item.id;
+  const response = await fetch("/api/items/" + id + "/decision", {
+    method: "POST", headers: {"Content-Type": "application/json"},
+    body: JSON.stringify({action: action, code: code || "", note: $("note").value})
+  });
+  const result = await response.json();
+  const message = result.error || result.message;
+  const next = items[current + 1];
+  await loadList(result.applied && next && $("status").value === "all" ? next.id : id);
+  if ($("message")) $("message").textContent = message;
+}
+
+document.addEventListener("keydown", e => {
+  if (e.target.tagName === "INPUT" || e.target.tagName === "SELECT") {
+    if (e.key === "Escape") e.target.blur();
+    return;
+  }
+  if (e.key === "a") decide("approve");
+  else if (e.key === "r") decide("reject");
+  else if (e.key === "m") { const code = prompt("Phelps code"); if (code) decide("modify", code); }
+  else if (e.key === "n") { e.preventDefault(); $("note").focus(); }
+  else if (e.key === "j") select(Math.min(current + 1, items.length - 1));
+  else if (e.key === "k") select(Math.max(current - 1, 0));
+  else if (e.key >= "1" && e.key <= "9" && detail) {
+    const c = detail.candidates[+e.key - 1];
+    if (c) decide("modify", c.phelps);
+  }
+});
+$("list").addEventListener("click", e => { const el = e.target.closest("div[data-i]"); if (el) select(+el.dataset.i); });
+["language", "type", "status"].forEach(id => $(id).addEventListener("change", () => { current = 0; loadList(); }));
+loadList();
+</script>
+</body>
+</html>
+`
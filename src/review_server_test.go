@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test the review UI API: listing with filters, item detail and decisions
+func TestReviewServer(t *testing.T) {
+	db := Database{Writing: []Writing{
+		{Version: "en-1", Language: "en", Phelps: "BH00001ONE", Text: "O God, guide me.\n\nThou art the Mighty."},
+		{Version: "en-2", Language: "en", Phelps: "AB00002TWO", Text: "He is God! Praised be Thou."},
+		{Version: "es-1", Language: "es", Phelps: "BH00001ONE", Text: "Oh Dios, guíame.\n\nTú eres el Poderoso."},
+		{Version: "es-2", Language: "es", Text: "¡Él es Dios! Alabado seas."},
+		{Version: "fr-1", Language: "fr", Text: "Il est Dieu ! Loué sois-Tu."},
+	}}
+	items := []ReviewItem{
+		{ID: "000001", Version: "es-1", Language: "es", Category: ReviewAmbiguous, Status: ReviewOpen,
+			Candidates: []ReviewCandidate{{Phelps: "BH00001ONE", Confidence: 70, MatchType: "AMBIGUOUS"}, {Phelps: "AB00002TWO", Confidence: 50, MatchType: "AMBIGUOUS"}}},
+		{ID: "000002", Version: "es-2", Language: "es", Category: ReviewLowConfidence, Status: ReviewOpen,
+			Candidates: []ReviewCandidate{{Phelps: "BH00001ONE", Confidence: 45, MatchType: "LIKELY"}}},
+		{ID: "000003", Version: "fr-1", Language: "fr", Category: ReviewAmbiguous, Status: ReviewOpen,
+			Candidates: []ReviewCandidate{{Phelps: "AB00002TWO", Confidence: 60, MatchType: "AMBIGUOUS"}}},
+	}
+	queuePath := filepath.Join(t.TempDir(), reviewQueueFile)
+	server := httptest.NewServer(newReviewServer(queuePath, "tester", db, items).routes())
+	defer server.Close()
+
+	getJSON := func(path string, value interface{}) {
+		t.Helper()
+		response, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, response.StatusCode)
+		}
+		if err := json.NewDecoder(response.Body).Decode(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	decide := func(id, body string) reviewDecisionResponse {
+		t.Helper()
+		response, err := http.Post(server.URL+"/api/items/"+id+"/decision", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		var result reviewDecisionResponse
+		if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	var list reviewListResponse
+	getJSON("/api/items?language=es&type=AMBIGUOUS", &list)
+	if len(list.Items) != 1 || list.Items[0].ID != "000001" || list.Progress.Total != 1 {
+		t.Errorf("Unexpected filtered list %+v", list)
+	}
+	if strings.Join(list.Languages, ",") != "es,fr" || strings.Join(list.MatchTypes, ",") != "AMBIGUOUS,LIKELY" {
+		t.Errorf("Unexpected filter values %v %v", list.Languages, list.MatchTypes)
+	}
+
+	var detail reviewDetail
+	getJSON("/api/items/000001", &detail)
+	if detail.FirstLine != "Oh Dios, guíame." || len(detail.Candidates) != 2 {
+		t.Fatalf("Unexpected detail %+v", detail)
+	}
+	if c := detail.Candidates[0]; c.FirstLine != "O God, guide me." || c.LastLine != "Thou art the Mighty." || c.Alignment < 0 {
+		t.Errorf("Unexpected candidate view %+v", c)
+	}
+
+	// Approving a code the prayer already has is applied without writing
+	if result := decide("000001", `{"action":"approve","note":"same opening"}`); !result.Applied || result.Item.Note != "same opening" {
+		t.Errorf("Expected approval applied, got %+v", result)
+	}
+	if result := decide("000003", `{"action":"reject"}`); !result.Applied || result.Item.Rejected[0] != "AB00002TWO" {
+		t.Errorf("Expected rejection stored, got %+v", result)
+	}
+	if result := decide("000002", `{"action":"modify","code":"not a code"}`); result.Applied || result.Item.Status != ReviewModified {
+		t.Errorf("Expected invalid code recorded but not applied, got %+v", result)
+	}
+
+	// Decisions from other sites are refused before anything is recorded
+	forged := []struct {
+		name, contentType, origin, host string
+	}{
+		{"text/plain form post", "text/plain", "", ""},
+		{"foreign origin", "application/json", "http://evil.example", ""},
+		{"rebound host", "application/json", "", "evil.example:8765"},
+	}
+	guarded := newReviewServer(queuePath, "tester", db, []ReviewItem{{ID: "000009", Version: "es-2", Language: "es", Status: ReviewOpen}})
+	guarded.addr = "localhost:8765"
+	for _, tt := range forged {
+		request := httptest.NewRequest(http.MethodPost, "http://localhost:8765/api/items/000009/decision", strings.NewReader(`{"action":"reject"}`))
+		request.Header.Set("Content-Type", tt.contentType)
+		if tt.origin != "" {
+			request.Header.Set("Origin", tt.origin)
+		}
+		if tt.host != "" {
+			request.Host = tt.host
+		}
+		recorder := httptest.NewRecorder()
+		guarded.routes().ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusForbidden || guarded.items[0].Status != ReviewOpen {
+			t.Errorf("%s: expected 403 and no decision, got %d (%s)", tt.name, recorder.Code, guarded.items[0].Status)
+		}
+	}
+	for host, expected := range map[string]bool{"localhost:8765": true, "127.0.0.1:8765": true, "[::1]:8765": true, "127.0.0.1:9999": false, "evil.example:8765": false} {
+		if got := guarded.servedHost(host); got != expected {
+			t.Errorf("servedHost(%q) = %v, expected %v", host, got, expected)
+		}
+	}
+
+	getJSON("/api/items?status=open", &list)
+	if len(list.Items) != 0 || list.Progress.Decided != 3 {
+		t.Errorf("Expected all items decided, got %+v", list)
+	}
+
+	saved, err := LoadReviewQueue(queuePath)
+	if err != nil || len(saved) != 3 || saved[0].Reviewer != "tester" || saved[1].Applied || !saved[2].Applied {
+		t.Errorf("Unexpected saved queue %+v, %v", saved, err)
+	}
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cross-session notes: lessons learned while matching (e.g. "fa short obligatory often
+// confused with medium") are kept in the session_notes table, keyed by language,
+// Phelps code and note type, and fed back into the matching prompts.
+const (
+	maxMemoryNotes = 50 // notes of the current session kept in memory
+	maxPromptNotes = 20 // notes included in a prompt per language
+	noteTimeFormat = "2006-01-02 15:04:05"
+)
+
+// Note types
+var sessionNoteTypes = []string{"SUCCESS", "FAILURE", "PATTERN", "STRATEGY", "TIP"}
+
+var noteTypeEmoji = map[string]string{
+	"SUCCESS":  "✅",
+	"FAILURE":  "❌",
+	"PATTERN":  "🔍",
+	"STRATEGY": "💡",
+	"TIP":      "📝",
+}
+
+// SessionNote is a lesson recorded by one matching session for later sessions
+type SessionNote struct {
+	Timestamp  time.Time
+	Language   string
+	NoteType   string
+	Content    string
+	PhelpsCode string
+	Confidence float64
+	SessionID  string
+}
+
+// LLMNote is a note returned by the LLM in its JSON response
+type LLMNote struct {
+	Language string `json:"language"`
+	Phelps   string `json:"phelps,omitempty"`
+	Type     string `json:"type"`
+	Content  string `json:"content"`
+}
+
+var (
+	currentSessionID string
+	sessionNotes     []SessionNote // notes added in this session, newest last
+	sessionNotesMu   sync.Mutex
+)
+
+// startSession gives this run a session ID
+func startSession() {
+	sessionNotesMu.Lock()
+	defer sessionNotesMu.Unlock()
+	if currentSessionID == "" {
+		now := time.Now()
+		currentSessionID = fmt.Sprintf("%s_%d_%d", now.Format("20060102_150405"), os.Getpid(), rand.Intn(1000))
+	}
+}
+
+// initializeSession starts the session and creates the session_notes table; only runs
+// that record or search notes call it
+func initializeSession() {
+	startSession()
+	if dryRunMode {
+		return
+	}
+
+	query := `CREATE TABLE IF NOT EXISTS session_notes (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		timestamp DATETIME NOT NULL,
+		language VARCHAR(10) NOT NULL,
+		note_type VARCHAR(20) NOT NULL,
+		content TEXT NOT NULL,
+		phelps_code VARCHAR(20),
+		confidence FLOAT,
+		session_id VARCHAR(100) NOT NULL,
+		INDEX idx_language (language),
+		INDEX idx_type (note_type),
+		INDEX idx_phelps (phelps_code),
+		INDEX idx_session (session_id)
+	)`
+	if _, err := execDoltQuery(query); err != nil {
+		log.Printf("⚠️ Session notes table unavailable, notes are kept for this session only: %v", err)
+	}
+}
+
+// normalizeNoteType maps a free-form type to one of sessionNoteTypes, defaulting to TIP
+func normalizeNoteType(noteType string) string {
+	noteType = strings.ToUpper(strings.TrimSpace(noteType))
+	for _, known := range sessionNoteTypes {
+		if noteType == known {
+			return noteType
+		}
+	}
+	return "TIP"
+}
+
+// addSessionNote records a note in memory and persists it to session_notes; a dry run
+// keeps its notes in memory only
+func addSessionNote(language, noteType, content, phelps string, confidence float64) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return
+	}
+	note := SessionNote{
+		Timestamp:  time.Now(),
+		Language:   strings.TrimSpace(language),
+		NoteType:   normalizeNoteType(noteType),
+		Content:    content,
+		PhelpsCode: strings.TrimSpace(phelps),
+		Confidence: confidence,
+		SessionID:  currentSessionID,
+	}
+
+	sessionNotesMu.Lock()
+	sessionNotes = append(sessionNotes, note)
+	if len(sessionNotes) > maxMemoryNotes {
+		sessionNotes = sessionNotes[len(sessionNotes)-maxMemoryNotes:]
+	}
+	sessionNotesMu.Unlock()
+	if dryRunMode {
+		return
+	}
+
+	query := fmt.Sprintf(`INSERT INTO session_notes (timestamp, language, note_type, content, phelps_code, confidence, session_id) VALUES ('%s', '%s', '%s', '%s', '%s', %g, '%s')`,
+		note.Timestamp.Format(noteTimeFormat),
+		strings.ReplaceAll(note.Language, "'", "''"),
+		note.NoteType,
+		strings.ReplaceAll(note.Content, "'", "''"),
+		strings.ReplaceAll(note.PhelpsCode, "'", "''"),
+		note.Confidence,
+		strings.ReplaceAll(note.SessionID, "'", "''"))
+	if _, err := execDoltQuery(query); err != nil {
+		log.Printf("⚠️ Failed to persist session note: %v", err)
+	}
+}
+
+// noteMatches reports whether a note passes the given filters; empty filters match all
+func noteMatches(note SessionNote, query, noteType, language string) bool {
+	if noteType != "" && !strings.EqualFold(note.NoteType, noteType) {
+		return false
+	}
+	if language != "" && note.Language != language {
+		return false
+	}
+	if query != "" {
+		q := strings.ToLower(query)
+		if !strings.Contains(strings.ToLower(note.Content), q) && !strings.Contains(strings.ToLower(note.PhelpsCode), q) {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryNotes returns the notes of this session that pass the filter, newest first
+func memoryNotes(keep func(SessionNote) bool) []SessionNote {
+	sessionNotesMu.Lock()
+	defer sessionNotesMu.Unlock()
+
+	var notes []SessionNote
+	for i := len(sessionNotes) - 1; i >= 0; i-- {
+		if keep(sessionNotes[i]) {
+			notes = append(notes, sessionNotes[i])
+		}
+	}
+	return notes
+}
+
+// queryStoredNotes loads notes of earlier sessions from session_notes
+func queryStoredNotes(where string, limit int) ([]SessionNote, error) {
+	conditions := []string{fmt.Sprintf("session_id <> '%s'", strings.ReplaceAll(currentSessionID, "'", "''"))}
+	if where != "" {
+		conditions = append(conditions, "("+where+")")
+	}
+	query := fmt.Sprintf(`SELECT timestamp, language, note_type, content, COALESCE(phelps_code, ''), COALESCE(confidence, 0), session_id FROM session_notes WHERE %s ORDER BY timestamp DESC LIMIT %d`,
+		strings.Join(conditions, " AND "), limit)
+
+	records, err := execDoltQueryCSV(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []SessionNote
+	for i, record := range records {
+		if i == 0 || len(record) < 7 {
+			continue // header
+		}
+		timestamp, _ := time.ParseInLocation(noteTimeFormat, record[0], time.Local)
+		confidence, _ := strconv.ParseFloat(record[5], 64)
+		notes = append(notes, SessionNote{
+			Timestamp:  timestamp,
+			Language:   record[1],
+			NoteType:   record[2],
+			Content:    record[3],
+			PhelpsCode: record[4],
+			Confidence: confidence,
+			SessionID:  record[6],
+		})
+	}
+	return notes, nil
+}
+
+// getRelevantNotes returns notes for a language plus general strategy and pattern
+// notes, from this session and earlier ones, newest first
+func getRelevantNotes(language string) []SessionNote {
+	relevant := func(note SessionNote) bool {
+		return note.Language == language || note.Language == "" || note.NoteType == "STRATEGY" || note.NoteType == "PATTERN"
+	}
+	notes := memoryNotes(relevant)
+
+	lang := strings.ReplaceAll(language, "'", "''")
+	stored, err := queryStoredNotes(fmt.Sprintf("language = '%s' OR language = '' OR note_type IN ('STRATEGY', 'PATTERN')", lang), maxPromptNotes)
+	if err != nil {
+		log.Printf("⚠️ Could not load notes from earlier sessions: %v", err)
+	}
+	notes = append(notes, stored...)
+
+	if len(notes) > maxPromptNotes {
+		notes = notes[:maxPromptNotes]
+	}
+	return notes
+}
+
+// searchSessionNotes finds notes by content or Phelps code, type and language across all sessions
+func searchSessionNotes(query, noteType, language string) []SessionNote {
+	notes := memoryNotes(func(note SessionNote) bool { return noteMatches(note, query, noteType, language) })
+
+	var conditions []string
+	if query != "" {
+		q := strings.ReplaceAll(strings.ToLower(query), "'", "''")
+		conditions = append(conditions, fmt.Sprintf("(LOWER(content) LIKE '%%%s%%' OR LOWER(phelps_code) LIKE '%%%s%%')", q, q))
+	}
+	if noteType != "" {
+		conditions = append(conditions, fmt.Sprintf("note_type = '%s'", strings.ReplaceAll(strings.ToUpper(noteType), "'", "''")))
+	}
+	if language != "" {
+		conditions = append(conditions, fmt.Sprintf("language = '%s'", strings.ReplaceAll(language, "'", "''")))
+	}
+
+	stored, err := queryStoredNotes(strings.Join(conditions, " AND "), 100)
+	if err != nil {
+		log.Printf("⚠️ Could not search notes from earlier sessions: %v", err)
+	}
+	return append(notes, stored...)
+}
+
+// removeSessionNotes deletes notes by type and language that are older than the given
+// number of days; empty filters and 0 days match everything in memory, but stored
+// notes are only deleted with at least one filter and never in a dry run. Returns the
+// number of in-memory notes removed.
+func removeSessionNotes(noteType, language string, olderThanDays int) int {
+	cutoff := time.Now().AddDate(0, 0, -olderThanDays)
+	remove := func(note SessionNote) bool {
+		return noteMatches(note, "", noteType, language) && (olderThanDays == 0 || note.Timestamp.Before(cutoff))
+	}
+
+	sessionNotesMu.Lock()
+	kept := sessionNotes[:0]
+	for _, note := range sessionNotes {
+		if !remove(note) {
+			kept = append(kept, note)
+		}
+	}
+	removed := len(sessionNotes) - len(kept)
+	sessionNotes = kept
+	sessionNotesMu.Unlock()
+
+	if dryRunMode {
+		return removed
+	}
+	var conditions []string
+	if noteType != "" {
+		conditions = append(conditions, fmt.Sprintf("note_type = '%s'", strings.ReplaceAll(strings.ToUpper(noteType), "'", "''")))
+	}
+	if language != "" {
+		conditions = append(conditions, fmt.Sprintf("language = '%s'", strings.ReplaceAll(language, "'", "''")))
+	}
+	if olderThanDays > 0 {
+		conditions = append(conditions, fmt.Sprintf("timestamp < '%s'", cutoff.Format(noteTimeFormat)))
+	}
+	if len(conditions) == 0 {
+		log.Printf("⚠️ Not removing stored session notes without a type, language or age filter")
+		return removed
+	}
+	if _, err := execDoltQuery("DELETE FROM session_notes WHERE " + strings.Join(conditions, " AND ")); err != nil {
+		log.Printf("⚠️ Failed to remove stored session notes: %v", err)
+	}
+
+	return removed
+}
+
+// noteAge formats how long ago a note was written
+func noteAge(timestamp time.Time) string {
+	age := time.Since(timestamp)
+	switch {
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+}
+
+// formatNotesForPrompt renders notes as a prompt section; no notes gives ""
+func formatNotesForPrompt(notes []SessionNote) string {
+	if len(notes) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	out.WriteString("CROSS-SESSION EXPERIENCE NOTES:\n")
+	out.WriteString("Here are insights from all previous LLM sessions (current and past):\n\n")
+
+	for _, note := range notes {
+		emoji := noteTypeEmoji[note.NoteType]
+		if emoji == "" {
+			emoji = "📝"
+		}
+		out.WriteString(fmt.Sprintf("%s %s (%s)", emoji, note.NoteType, noteAge(note.Timestamp)))
+		if note.Language != "" {
+			out.WriteString(fmt.Sprintf(" [%s]", note.Language))
+		}
+		out.WriteString(": " + note.Content)
+
+		if note.PhelpsCode != "" {
+			if note.Confidence > 0 {
+				out.WriteString(fmt.Sprintf(" [%s, confidence: %.0f%%]", note.PhelpsCode, note.Confidence*100))
+			} else {
+				out.WriteString(fmt.Sprintf(" [%s]", note.PhelpsCode))
+			}
+		}
+		if note.SessionID != "" && note.SessionID == currentSessionID {
+			out.WriteString(" [CURRENT SESSION]")
+		} else {
+			out.WriteString(fmt.Sprintf(" [SESSION: %s]", note.SessionID))
+		}
+		out.WriteString("\n")
+	}
+
+	out.WriteString("\nUse these cross-session insights to improve your analysis and learn from previous LLM experiences.\n\n")
+	return out.String()
+}
+
+// notesForLanguages collects the relevant notes of every language in a batch without duplicates
+func notesForLanguages(languages []string) []SessionNote {
+	seen := make(map[string]bool)
+	var notes []SessionNote
+	for _, language := range languages {
+		for _, note := range getRelevantNotes(language) {
+			key := note.SessionID + "|" + note.Timestamp.String() + "|" + note.Content
+			if !seen[key] {
+				seen[key] = true
+				notes = append(notes, note)
+			}
+		}
+	}
+	return notes
+}
+
+// recordLLMNotes stores the notes an LLM returned with its matches
+func recordLLMNotes(notes []LLMNote, defaultLanguage string) {
+	for _, note := range notes {
+		language := note.Language
+		if language == "" {
+			language = defaultLanguage
+		}
+		addSessionNote(language, note.Type, note.Content, note.Phelps, 0)
+	}
+	if len(notes) > 0 {
+		log.Printf("📝 Recorded %d notes from the LLM for later sessions", len(notes))
+	}
+}
+
+// SearchNotesCommand prints notes matching a query, optionally filtered by language
+func SearchNotesCommand(query, language string) error {
+	notes := searchSessionNotes(query, "", language)
+	if len(notes) == 0 {
+		fmt.Println("No matching notes found")
+		return nil
+	}
+
+	fmt.Printf("Found %d notes:\n\n", len(notes))
+	fmt.Print(formatNotesForPrompt(notes))
+	return nil
+}
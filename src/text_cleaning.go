@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TextCleaningRule removes editorial material (footnotes, headings, publication notes)
+// that is not part of the prayer itself. Rules with Sources only apply to writings
+// whose source contains one of the given names (case-insensitive). A group named keep
+// is left in place of the match.
+type TextCleaningRule struct {
+	Name    string
+	Sources []string
+	Pattern *regexp.Regexp
+}
+
+// CleaningRemoval records one fragment removed by a cleaning rule
+type CleaningRemoval struct {
+	Rule string
+	Text string
+}
+
+// textCleaningRules are applied in order; line rules run before inline markers so a
+// footnote line like "*43. This selection..." is removed whole
+var textCleaningRules = []TextCleaningRule{
+	{
+		Name:    "markdown heading",
+		Pattern: regexp.MustCompile(`(?m)^[ \t]*#{1,6}(?:[ \t][^\n]*)?$`),
+	},
+	{
+		Name:    "footnote",
+		Pattern: regexp.MustCompile(`(?m)^[ \t]*\*+[ \t]*\d+\.?[ \t]+[^\n]*$`),
+	},
+	{
+		// The note sentence to the end of its line; prayer text before it on the line stays
+		Name:    "publication note",
+		Pattern: regexp.MustCompile(`(?mi)(?P<keep>^|[.!?]["”’»)]*)[ \t]*[^.!?\n]*\b(?:was first published in|first appeared in|this selection (?:is|was) (?:taken )?from|translated (?:from the original )?by)\b[^\n]*$`),
+	},
+	{
+		Name:    "attribution line",
+		Sources: []string{"bahaiprayers"},
+		Pattern: regexp.MustCompile(`(?m)^[ \t]*[—–-]+[ \t]*(?:Bahá.u.lláh|.Abdu.l-Bahá|The Báb)[ \t]*$`),
+	},
+	{
+		Name:    "footnote marker",
+		Pattern: regexp.MustCompile(`\*+\d+|\[\d+\]|（[註注][^）]*）`),
+	},
+}
+
+var extraBlankLinesRegex = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)+`)
+
+// appliesTo reports whether a rule applies to writings from the given source
+func (rule TextCleaningRule) appliesTo(source string) bool {
+	if len(rule.Sources) == 0 {
+		return true
+	}
+	source = strings.ToLower(source)
+	for _, name := range rule.Sources {
+		if strings.Contains(source, strings.ToLower(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanPrayerText strips editorial material from a prayer text and returns the cleaned
+// text with what was removed. With an empty source only source-independent rules apply.
+func CleanPrayerText(text, source string) (string, []CleaningRemoval) {
+	var removed []CleaningRemoval
+	for _, rule := range textCleaningRules {
+		if !rule.appliesTo(source) {
+			continue
+		}
+		keep := rule.Pattern.SubexpIndex("keep")
+		for _, match := range rule.Pattern.FindAllStringSubmatch(text, -1) {
+			fragment := match[0]
+			if keep > 0 {
+				fragment = strings.TrimPrefix(fragment, match[keep])
+			}
+			if trimmed := strings.TrimSpace(fragment); trimmed != "" {
+				removed = append(removed, CleaningRemoval{Rule: rule.Name, Text: trimmed})
+			}
+		}
+		text = rule.Pattern.ReplaceAllString(text, "${keep}")
+	}
+
+	if len(removed) == 0 {
+		return text, nil
+	}
+
+	text = extraBlankLinesRegex.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text), removed
+}
+
+// TextCleaningEntry is one writing with the fragments removed from it
+type TextCleaningEntry struct {
+	Version  string
+	Language string
+	Source   string
+	Removed  []CleaningRemoval
+}
+
+// writeTextCleaningReport writes per-rule totals followed by every removed fragment
+func writeTextCleaningReport(w io.Writer, entries []TextCleaningEntry, scanned int) {
+	ruleCounts := make(map[string]int)
+	for _, entry := range entries {
+		for _, removal := range entry.Removed {
+			ruleCounts[removal.Rule]++
+		}
+	}
+	var rules []string
+	for rule := range ruleCounts {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	fmt.Fprintf(w, "Scanned: %d writings, %d cleaned\n\n", scanned, len(entries))
+	fmt.Fprintf(w, "REMOVALS BY RULE:\n")
+	for _, rule := range rules {
+		fmt.Fprintf(w, "  %-20s %d\n", rule, ruleCounts[rule])
+	}
+	fmt.Fprintf(w, "\n%s\n\n", strings.Repeat("═", 63))
+
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s/%s", entry.Language, entry.Version)
+		if entry.Source != "" {
+			fmt.Fprintf(w, " (source: %s)", entry.Source)
+		}
+		fmt.Fprintf(w, "\n")
+		for _, removal := range entry.Removed {
+			fmt.Fprintf(w, "  - [%s] %s\n", removal.Rule, removal.Text)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+)
+
+// Test editorial material removal before fingerprinting
+func TestCleanPrayerText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		source   string
+		expected string
+		rules    []string
+	}{
+		{
+			name:     "Footnote line",
+			input:    "He is the Eternal, the One.\n\nPraised be Thou, O Lord.\n\n*43.    This selection, with the exception of the invocation, was first published in Tablets of Bahá’u’lláh.",
+			expected: "He is the Eternal, the One.\n\nPraised be Thou, O Lord.",
+			rules:    []string{"footnote"},
+		},
+		{
+			name:     "Markdown heading and inline marker",
+			input:    "## For Infants\n\nO God! Educate these children.*12",
+			expected: "O God! Educate these children.",
+			rules:    []string{"markdown heading", "footnote marker"},
+		},
+		{
+			name:     "Publication note",
+			input:    "O Thou kind Lord!\nTranslated by Shoghi Effendi",
+			expected: "O Thou kind Lord!",
+			rules:    []string{"publication note"},
+		},
+		{
+			name:     "Inline publication note keeps the prayer",
+			input:    "O Thou kind Lord! Unite all. This selection was first published in Bahá’í Prayers (1954).",
+			expected: "O Thou kind Lord! Unite all.",
+			rules:    []string{"publication note"},
+		},
+		{
+			name:     "Attribution only removed for its source",
+			input:    "Glorified art Thou, O Lord my God!\n— Bahá’u’lláh",
+			source:   "bahaiprayers.net",
+			expected: "Glorified art Thou, O Lord my God!",
+			rules:    []string{"attribution line"},
+		},
+		{
+			name:     "Attribution kept for other sources",
+			input:    "Glorified art Thou, O Lord my God!\n— Bahá’u’lláh",
+			source:   "other",
+			expected: "Glorified art Thou, O Lord my God!\n— Bahá’u’lláh",
+		},
+		{
+			name:     "Chinese footnote marker",
+			input:    "主啊！求祢護佑我。（註一）",
+			expected: "主啊！求祢護佑我。",
+			rules:    []string{"footnote marker"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, removed := CleanPrayerText(tt.input, tt.source)
+			if result != tt.expected {
+				t.Errorf("CleanPrayerText() = %q, want %q", result, tt.expected)
+			}
+			if len(removed) != len(tt.rules) {
+				t.Fatalf("Expected %d removals, got %+v", len(tt.rules), removed)
+			}
+			for i, rule := range tt.rules {
+				if removed[i].Rule != rule {
+					t.Errorf("Removal %d: got rule %q, want %q", i, removed[i].Rule, rule)
+				}
+			}
+		})
+	}
+}
+
+func TestFingerprintUsesCleanedText(t *testing.T) {
+	text := "O God, guide me, protect me, make of me a shining lamp and a brilliant star."
+	withNotes := "# Guidance\n\n" + text + "\n\n*7. This selection was first published in Bahá’í Prayers."
+
+	clean := CreatePrayerFingerprint("", "v1", "en", "", text, "")
+	noted := CreatePrayerFingerprint("", "v2", "en", "", withNotes, "")
+
+	if clean.TextHash != noted.TextHash {
+		t.Error("Expected footnotes and headings not to change the text hash")
+	}
+	if clean.WordCount != noted.WordCount || clean.ClosingPhrase != noted.ClosingPhrase {
+		t.Errorf("Expected identical word count and closing phrase, got %d/%q and %d/%q",
+			clean.WordCount, clean.ClosingPhrase, noted.WordCount, noted.ClosingPhrase)
+	}
+}
@@ -83,10 +83,10 @@ func assignTMPCodesForLanguageWithStart(language string, startNum int) (int, int
 		SELECT version, name, text
 		FROM writings
 		WHERE language = '%s'
-		  AND (phelps IS NULL OR phelps = '')
+		  AND %s
 		  AND text != ''
 		ORDER BY version
-	`, language)
+	`, language, unmatchedSQL("phelps"))
 
 	records, err := execDoltQueryCSV(query)
 	if err != nil {
@@ -112,20 +112,15 @@ func assignTMPCodesForLanguageWithStart(language string, startNum int) (int, int
 		nextNum++
 
 		// Assign the TMP code
-		updateQuery := fmt.Sprintf(`
-			UPDATE writings
-			SET phelps = '%s'
-			WHERE version = '%s' AND language = '%s'
-		`,
-			tmpCode,
-			strings.ReplaceAll(version, "'", "''"),
-			language,
-		)
-
-		if _, err := execDoltQuery(updateQuery); err != nil {
+		change := ProposedChange{Version: version, Language: language, NewPhelps: tmpCode, MatchType: "NEW_TMP_CODE", Source: "init-tmp"}
+		written, err := writePhelps(change, true)
+		if err != nil {
 			log.Printf("⚠️  Failed to assign TMP code to %s: %v", version, err)
 			continue
 		}
+		if !written {
+			continue
+		}
 
 		assigned++
 	}
@@ -147,37 +142,40 @@ func BuildReferencesWithTMP(db Database) ([]EnglishReference, []EnglishReference
 	var persianRefs []EnglishReference
 
 	// Build English references (including TMP codes)
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Language == "en" && w.Phelps != "" && w.Text != "" {
 			englishRefs = append(englishRefs, EnglishReference{
 				Phelps:   w.Phelps,
 				Name:     w.Name,
-				Text:     w.Text,
+				Text:     cleanWritingText(w),
 				Category: w.Type,
+				Source:   w.Source,
 			})
 		}
 	}
 
 	// Build Arabic references (including TMP codes)
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Language == "ar" && w.Phelps != "" && w.Text != "" {
 			arabicRefs = append(arabicRefs, EnglishReference{
 				Phelps:   w.Phelps,
 				Name:     w.Name,
-				Text:     w.Text,
+				Text:     cleanWritingText(w),
 				Category: w.Type,
+				Source:   w.Source,
 			})
 		}
 	}
 
 	// Build Persian references (including TMP codes)
-	for _, w := range db.Writings {
+	for _, w := range db.Writing {
 		if w.Language == "fa" && w.Phelps != "" && w.Text != "" {
 			persianRefs = append(persianRefs, EnglishReference{
 				Phelps:   w.Phelps,
 				Name:     w.Name,
-				Text:     w.Text,
+				Text:     cleanWritingText(w),
 				Category: w.Type,
+				Source:   w.Source,
 			})
 		}
 	}
@@ -244,6 +242,8 @@ func CreateFallbackMatchingPrompt(
 	prompt.WriteString("2. **Fall back to Arabic**: If no English match, try Arabic references\n")
 	prompt.WriteString("3. **Fall back to Persian**: If no Arabic match, try Persian references\n")
 	prompt.WriteString("4. **Generate TMP code**: If no match found in any tier, set match_type: NEW_TMP_CODE\n\n")
+	prompt.WriteString("⚠️  Long Tablets are also given as numbered passages (phelps ending in §n): if a target is an excerpt of one passage, return the passage code (e.g. AB00001FIR§2)\n")
+	prompt.WriteString("⚠️  Arabic and Persian fingerprints are diacritic-folded with unified letter forms, so identical text_hash, key_terms and phonetic_terms between ar and fa references mean the same original text\n\n")
 
 	prompt.WriteString("# MATCHING CRITERIA (Same as before)\n")
 	prompt.WriteString("- **EXACT**: text_hash match (confidence: 100%)\n")
@@ -305,6 +305,10 @@ func GenerateNewTMPCode() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	// A dry run has not written the codes it proposed
+	if proposed := highestProposedTMP(); proposed >= nextNum {
+		nextNum = proposed + 1
+	}
 
 	return fmt.Sprintf("%s%05d", TMP_CODE_PREFIX, nextNum), nil
 }
@@ -317,21 +321,25 @@ func ApplyTMPMatches(language string, results CompressedBatchResponse) error {
 	newTmpCount := 0
 
 	for _, match := range results.Matches {
+		// Passage codes (<Phelps>§n) store the Tablet code plus an excerpt note
+		phelps, passage := splitPassageCode(match.EnglishPhelps)
+
 		switch match.MatchType {
 		case "EXACT":
-			if match.Confidence >= 95 {
+			if shouldAutoApply(match.Backend, match.MatchType, match.Confidence, match.MatchReasons) {
 				// Apply the match (could be real Phelps or TMP code)
-				query := fmt.Sprintf("UPDATE writings SET phelps = '%s' WHERE version = '%s' AND language = '%s'",
-					strings.ReplaceAll(match.EnglishPhelps, "'", "''"),
-					strings.ReplaceAll(match.TargetVersion, "'", "''"),
-					language)
-
-				if _, err := execDoltQuery(query); err != nil {
+				change := ProposedChange{Version: match.TargetVersion, Language: language, NewPhelps: phelps, Passage: passage,
+					Confidence: match.Confidence, MatchType: match.MatchType, Reasons: match.MatchReasons, Source: "tmp-fallback"}
+				written, err := writePhelps(change, false)
+				if err != nil {
 					log.Printf("ERROR updating %s: %v", match.TargetVersion, err)
 					continue
 				}
+				if !written {
+					continue
+				}
 
-				if isTMPCode(match.EnglishPhelps) {
+				if isTMPCode(phelps) {
 					tmpCount++
 				} else {
 					exactCount++
@@ -339,18 +347,19 @@ func ApplyTMPMatches(language string, results CompressedBatchResponse) error {
 			}
 
 		case "LIKELY":
-			if match.Confidence >= 80 {
-				query := fmt.Sprintf("UPDATE writings SET phelps = '%s' WHERE version = '%s' AND language = '%s'",
-					strings.ReplaceAll(match.EnglishPhelps, "'", "''"),
-					strings.ReplaceAll(match.TargetVersion, "'", "''"),
-					language)
-
-				if _, err := execDoltQuery(query); err != nil {
+			if shouldAutoApply(match.Backend, match.MatchType, match.Confidence, match.MatchReasons) {
+				change := ProposedChange{Version: match.TargetVersion, Language: language, NewPhelps: phelps, Passage: passage,
+					Confidence: match.Confidence, MatchType: match.MatchType, Reasons: match.MatchReasons, Source: "tmp-fallback"}
+				written, err := writePhelps(change, false)
+				if err != nil {
 					log.Printf("ERROR updating %s: %v", match.TargetVersion, err)
 					continue
 				}
+				if !written {
+					continue
+				}
 
-				if isTMPCode(match.EnglishPhelps) {
+				if isTMPCode(phelps) {
 					tmpCount++
 				} else {
 					likelyCount++
@@ -365,15 +374,16 @@ func ApplyTMPMatches(language string, results CompressedBatchResponse) error {
 				continue
 			}
 
-			query := fmt.Sprintf("UPDATE writings SET phelps = '%s' WHERE version = '%s' AND language = '%s'",
-				newTmpCode,
-				strings.ReplaceAll(match.TargetVersion, "'", "''"),
-				language)
-
-			if _, err := execDoltQuery(query); err != nil {
+			change := ProposedChange{Version: match.TargetVersion, Language: language, NewPhelps: newTmpCode,
+				Confidence: match.Confidence, MatchType: match.MatchType, Reasons: match.MatchReasons, Source: "tmp-fallback"}
+			written, err := writePhelps(change, false)
+			if err != nil {
 				log.Printf("ERROR assigning TMP code to %s: %v", match.TargetVersion, err)
 				continue
 			}
+			if !written {
+				continue
+			}
 
 			newTmpCount++
 			log.Printf("   🆕 Generated new TMP code: %s for %s", newTmpCode, match.TargetVersion)
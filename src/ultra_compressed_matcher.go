@@ -30,6 +30,7 @@ type MultiLanguageMatchResult struct {
 	Confidence      float64  `json:"confidence"`
 	MatchReasons    []string `json:"match_reasons"`
 	AmbiguityReason string   `json:"ambiguity_reason,omitempty"`
+	Backend         string   `json:"-"` // backend whose response proposed the match
 }
 
 // UltraBatchResponse handles multi-language processing results
@@ -42,6 +43,7 @@ type UltraBatchResponse struct {
 	TotalNewTranslations int                        `json:"total_new_translations"`
 	ProcessedLanguages   []string                   `json:"processed_languages"`
 	Summary              string                     `json:"summary"`
+	Notes                []LLMNote                  `json:"notes,omitempty"`
 }
 
 // LanguageSummary provides per-language statistics
@@ -72,21 +74,21 @@ type langSizeStruct struct {
 // Excludes transliteration languages which need special handling
 // NOW INCLUDES languages with matched prayers for error correction
 func GetUnprocessedLanguageStats() ([]LanguageStats, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT
 			language,
 			COUNT(*) as total_prayers,
-			SUM(CASE WHEN phelps IS NULL OR phelps = '' THEN 1 ELSE 0 END) as unmatched_prayers,
-			SUM(CASE WHEN phelps IS NOT NULL AND phelps != '' THEN 1 ELSE 0 END) as matched_prayers
+			%[1]s as unmatched_prayers,
+			%[2]s as matched_prayers
 		FROM writings
 		WHERE language != 'en'
 		  AND language NOT IN ('', 'unknown')
-		  AND language NOT LIKE '%-translit'
+		  AND language NOT LIKE '%%-translit'
 		GROUP BY language
-		HAVING SUM(CASE WHEN phelps IS NULL OR phelps = '' THEN 1 ELSE 0 END) > 0
-		   OR SUM(CASE WHEN phelps IS NOT NULL AND phelps != '' THEN 1 ELSE 0 END) > 10
-		ORDER BY SUM(CASE WHEN phelps IS NULL OR phelps = '' THEN 1 ELSE 0 END) DESC, COUNT(*) DESC
-	`
+		HAVING %[1]s > 0
+		   OR %[2]s > 10
+		ORDER BY %[1]s DESC, COUNT(*) DESC
+	`, countUnmatchedSQL(), countMatchedSQL())
 
 	output, err := execDoltQueryCSV(query)
 	if err != nil {
@@ -288,7 +290,14 @@ func CreateMultiLanguagePrompt(batch LanguageBatch) string {
 	prompt.WriteString("2. For each English reference, find matches in ANY of the target languages\n")
 	prompt.WriteString("3. Use the same matching criteria: EXACT, LIKELY, AMBIGUOUS, NEW_TRANSLATION\n")
 	prompt.WriteString("4. Include target_language field to specify which language contains the match\n")
-	prompt.WriteString("5. Focus on high-confidence matches for bulk processing efficiency\n\n")
+	prompt.WriteString("5. Focus on high-confidence matches for bulk processing efficiency\n")
+	prompt.WriteString("6. Long Tablets are also given as numbered passages (phelps ending in §n): match excerpts to the passage code (e.g. AB00001FIR§2)\n")
+	prompt.WriteString("7. Optionally add notes (type SUCCESS, FAILURE, PATTERN, STRATEGY or TIP) for lessons later sessions should know\n\n")
+
+	if notes := formatNotesForPrompt(notesForLanguages(batch.Languages)); notes != "" {
+		prompt.WriteString("# NOTES FROM EARLIER SESSIONS\n")
+		prompt.WriteString(notes)
+	}
 
 	prompt.WriteString("# OUTPUT FORMAT\n")
 	prompt.WriteString("```json\n")
@@ -320,7 +329,10 @@ func CreateMultiLanguagePrompt(batch LanguageBatch) string {
   "total_ambiguous": 12,
   "total_new_translations": 31,
   "processed_languages": ["cy", "th", "tl"],
-  "summary": "Processed 3 languages with 155 total prayers: 45 exact, 67 likely, 12 ambiguous"
+  "summary": "Processed 3 languages with 155 total prayers: 45 exact, 67 likely, 12 ambiguous",
+  "notes": [
+    {"language": "th", "phelps": "BH00002SHO", "type": "PATTERN", "content": "short obligatory prayer often confused with the medium one"}
+  ]
 }`)
 	prompt.WriteString("\n```\n\n")
 
@@ -385,8 +397,7 @@ func ProcessLanguageBatch(languages []string, heuristic bool) error {
 	// Create fingerprints for English references
 	var englishFingerprints []PrayerFingerprint
 	for _, ref := range englishRefs {
-		fp := CreatePrayerFingerprint(ref.Phelps, "", "en", ref.Name, ref.Text)
-		englishFingerprints = append(englishFingerprints, fp)
+		englishFingerprints = append(englishFingerprints, CreateReferenceFingerprints(ref, "en")...)
 	}
 
 	// Build batch structure
@@ -397,6 +408,10 @@ func ProcessLanguageBatch(languages []string, heuristic bool) error {
 		TotalPrayers:   0,
 	}
 
+	// Resolve duplicated texts locally; only the remaining prayers go to the LLM
+	hashIndex := BuildHashIndex(db)
+	var localMatches []MultiLanguageMatchResult
+
 	// Process each language in the batch
 	for _, lang := range languages {
 		var targetPrayers []TargetPrayer
@@ -408,10 +423,24 @@ func ProcessLanguageBatch(languages []string, heuristic bool) error {
 		var targetFingerprints []PrayerFingerprint
 
 		for _, prayer := range targetPrayers {
-			fp := CreatePrayerFingerprint("", prayer.Version, lang, prayer.Name, prayer.Text)
+			fp := CreatePrayerFingerprint("", prayer.Version, lang, prayer.Name, prayer.Text, prayer.Source)
 			targetFingerprints = append(targetFingerprints, fp)
 		}
 
+		langLocal, remaining := prematchLanguageTargets(hashIndex, targetFingerprints, lang)
+		for _, m := range langLocal {
+			localMatches = append(localMatches, MultiLanguageMatchResult{
+				EnglishPhelps:   m.EnglishPhelps,
+				TargetLanguage:  lang,
+				TargetVersion:   m.TargetVersion,
+				MatchType:       m.MatchType,
+				Confidence:      m.Confidence,
+				MatchReasons:    m.MatchReasons,
+				AmbiguityReason: m.AmbiguityReason,
+			})
+		}
+		targetFingerprints = remaining
+
 		batch.LanguageGroups[lang] = targetFingerprints
 		batch.TotalPrayers += len(targetFingerprints)
 
@@ -430,6 +459,16 @@ func ProcessLanguageBatch(languages []string, heuristic bool) error {
 	log.Printf("Created batch: %d languages, %d total prayers (%s)",
 		len(languages), batch.TotalPrayers, batch.BatchSize)
 
+	// Text-hash matches need no LLM, so they are applied before a call that may fail
+	if len(localMatches) > 0 {
+		log.Printf("Applying %d text-hash matches", len(localMatches))
+		applyLanguageBatchMatches(languages, localMatches)
+	}
+	if batch.TotalPrayers == 0 {
+		log.Printf("✅ All prayers in batch resolved by text hash, skipping LLM call")
+		return nil
+	}
+
 	// Create prompt
 	prompt := CreateMultiLanguagePrompt(batch)
 
@@ -467,6 +506,7 @@ func ProcessLanguageBatch(languages []string, heuristic bool) error {
 	if backendErr != nil {
 		return fmt.Errorf("all backends failed for batch %v: %w", languages, backendErr)
 	}
+	backend := lastLLMBackend
 
 	// Parse response using robust JSON extraction
 	jsonStr, err := ExtractJSONFromResponse(response)
@@ -493,13 +533,28 @@ func ProcessLanguageBatch(languages []string, heuristic bool) error {
 		return fmt.Errorf("failed to parse response (saved to %s): %w", failedResponseFile, err)
 	}
 
-	// Process results for each language
+	for i := range results.Matches {
+		results.Matches[i].Backend = backend
+	}
+
+	// Verify LLM proposals against the full texts, then process results for each language
+	recordLLMNotes(results.Notes, "")
+	results.Matches = DropRejectedMultiLanguageMatches(results.Matches, loadRejectedPairs())
+	VerifyMultiLanguageMatches(db, results.Matches)
+	EvaluateMultiLanguageMatches(db, results.Matches)
+	applyLanguageBatchMatches(languages, results.Matches)
+
+	return nil
+}
+
+// applyLanguageBatchMatches validates and applies the matches of a language batch
+func applyLanguageBatchMatches(languages []string, matches []MultiLanguageMatchResult) {
 	totalProcessed := 0
 	languageMismatches := 0
 
 	for _, lang := range languages {
 		langMatches := 0
-		for _, match := range results.Matches {
+		for _, match := range matches {
 			if match.TargetLanguage == lang {
 				// Validate that the UUID actually belongs to this language
 				actualLang, err := getLanguageForVersion(match.TargetVersion)
@@ -515,26 +570,17 @@ func ProcessLanguageBatch(languages []string, heuristic bool) error {
 					continue
 				}
 
-				// Apply the match to database
-				if match.MatchType == "EXACT" && match.Confidence >= 95 {
-					query := fmt.Sprintf("UPDATE writings SET phelps = '%s' WHERE version = '%s' AND language = '%s'",
-						strings.ReplaceAll(match.EnglishPhelps, "'", "''"),
-						strings.ReplaceAll(match.TargetVersion, "'", "''"),
-						lang)
-
-					if _, err := execDoltQuery(query); err != nil {
+				// Apply the match to database; passage codes (<Phelps>§n) store the Tablet code plus an excerpt note
+				phelps, passage := splitPassageCode(match.EnglishPhelps)
+				if (match.MatchType == "EXACT" || match.MatchType == "LIKELY") && shouldAutoApply(match.Backend, match.MatchType, match.Confidence, match.MatchReasons) {
+					change := ProposedChange{Version: match.TargetVersion, Language: lang, NewPhelps: phelps, Passage: passage,
+						Confidence: match.Confidence, MatchType: match.MatchType, Reasons: match.MatchReasons, Source: "ultra"}
+					written, err := writePhelps(change, false)
+					if err != nil {
 						log.Printf("ERROR updating %s: %v", match.TargetVersion, err)
 						continue
 					}
-					langMatches++
-				} else if match.MatchType == "LIKELY" && match.Confidence >= 80 {
-					query := fmt.Sprintf("UPDATE writings SET phelps = '%s' WHERE version = '%s' AND language = '%s'",
-						strings.ReplaceAll(match.EnglishPhelps, "'", "''"),
-						strings.ReplaceAll(match.TargetVersion, "'", "''"),
-						lang)
-
-					if _, err := execDoltQuery(query); err != nil {
-						log.Printf("ERROR updating %s: %v", match.TargetVersion, err)
+					if !written {
 						continue
 					}
 					langMatches++
@@ -552,8 +598,6 @@ func ProcessLanguageBatch(languages []string, heuristic bool) error {
 
 	log.Printf("Batch completed: %d total matches across %d languages",
 		totalProcessed, len(languages))
-
-	return nil
 }
 
 // splitAndRetryBatch splits a large batch into smaller ones and retries
@@ -972,7 +1016,7 @@ func getLanguageLikelihoodScore(stat LanguageStats) int {
 }
 
 func getLanguagePrayerCount(language string) int {
-	records, err := execDoltQueryCSV(fmt.Sprintf("SELECT COUNT(*) FROM writings WHERE language = '%s' AND (phelps = '' OR phelps IS NULL)", language))
+	records, err := execDoltQueryCSV(fmt.Sprintf("SELECT COUNT(*) FROM writings WHERE language = '%s' AND %s", language, unmatchedSQL("phelps")))
 	if err != nil || len(records) < 2 {
 		return 0
 	}
@@ -985,7 +1029,7 @@ func getLanguagePrayerCount(language string) int {
 
 // Helper functions for skip processing (moved from main.go)
 func getAllUnprocessedLanguages() ([]string, error) {
-	records, err := execDoltQueryCSV("SELECT DISTINCT language FROM writings WHERE phelps = '' OR phelps IS NULL ORDER BY language")
+	records, err := execDoltQueryCSV("SELECT DISTINCT language FROM writings WHERE " + unmatchedSQL("phelps") + " ORDER BY language")
 	if err != nil {
 		return nil, err
 	}
@@ -1072,17 +1116,17 @@ func getLanguageFailureRateFromFile(filename string) float64 {
 
 // GetTransliterationLanguages returns transliteration languages that need special processing
 func GetTransliterationLanguages() ([]LanguageStats, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT
 			language,
 			COUNT(*) as total_prayers,
-			SUM(CASE WHEN phelps IS NULL THEN 1 ELSE 0 END) as unmatched_prayers
+			%[1]s as unmatched_prayers
 		FROM writings
-		WHERE language LIKE '%-translit'
+		WHERE language LIKE '%%-translit'
 		GROUP BY language
-		HAVING SUM(CASE WHEN phelps IS NULL THEN 1 ELSE 0 END) > 0
+		HAVING %[1]s > 0
 		ORDER BY COUNT(*) DESC
-	`
+	`, countUnmatchedSQL())
 
 	output, err := execDoltQueryCSV(query)
 	if err != nil {
@@ -1142,24 +1186,39 @@ func ProcessTransliterationLanguages() error {
 		// Copy Phelps codes from base language to transliteration language
 		// This assumes that transliteration texts correspond 1:1 with base language texts
 		query := fmt.Sprintf(`
-			UPDATE writings AS translit
+			SELECT translit.version, base.phelps, base.version
+			FROM writings AS translit
 			INNER JOIN writings AS base ON (
 				base.language = '%s'
-				AND base.phelps IS NOT NULL
+				AND %s
 				AND translit.name = base.name
 			)
-			SET translit.phelps = base.phelps
-			WHERE translit.language = '%s' AND translit.phelps IS NULL
-		`, baseLanguage, lang.Language)
+			WHERE translit.language = '%s' AND %s
+			ORDER BY translit.version, base.version
+		`, baseLanguage, matchedSQL("base.phelps"), lang.Language, unmatchedSQL("translit.phelps"))
 
-		result, err := execDoltQuery(query)
+		records, err := execDoltQueryCSV(query)
 		if err != nil {
 			log.Printf("❌ Failed to update %s: %v", lang.Language, err)
 			continue
 		}
 
-		log.Printf("✅ Updated %s using %s base language", lang.Language, baseLanguage)
-		log.Printf("   Query result: %s", strings.TrimSpace(string(result)))
+		copied := 0
+		for i := 1; i < len(records); i++ {
+			rec := records[i]
+			if len(rec) < 3 {
+				continue
+			}
+			change := ProposedChange{Version: rec[0], Language: lang.Language, NewPhelps: normalizePhelps(rec[1]), Confidence: 100,
+				MatchType: "TRANSLITERATION", Reasons: []string{fmt.Sprintf("same name as %s (%s)", rec[2], baseLanguage)}, Source: "transliteration"}
+			if written, err := writePhelps(change, true); err != nil {
+				log.Printf("❌ Failed to update %s: %v", rec[0], err)
+			} else if written {
+				copied++
+			}
+		}
+
+		log.Printf("✅ Updated %s using %s base language: %d codes copied", lang.Language, baseLanguage, copied)
 	}
 
 	return nil